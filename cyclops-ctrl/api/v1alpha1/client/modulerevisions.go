@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+type ModuleRevisionInterface interface {
+	List(opts metav1.ListOptions) ([]cyclopsv1alpha1.ModuleRevision, error)
+	Get(name string) (*cyclopsv1alpha1.ModuleRevision, error)
+	Create(*cyclopsv1alpha1.ModuleRevision) (*cyclopsv1alpha1.ModuleRevision, error)
+	Delete(name string) error
+}
+
+type moduleRevisionClient struct {
+	restClient rest.Interface
+	ns         string
+}
+
+func (c *moduleRevisionClient) List(opts metav1.ListOptions) ([]cyclopsv1alpha1.ModuleRevision, error) {
+	result := cyclopsv1alpha1.ModuleRevisionList{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("modulerevisions").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(context.Background()).
+		Into(&result)
+
+	return result.Items, err
+}
+
+func (c *moduleRevisionClient) Get(name string) (*cyclopsv1alpha1.ModuleRevision, error) {
+	result := cyclopsv1alpha1.ModuleRevision{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("modulerevisions").
+		Name(name).
+		Do(context.Background()).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *moduleRevisionClient) Create(revision *cyclopsv1alpha1.ModuleRevision) (*cyclopsv1alpha1.ModuleRevision, error) {
+	result := cyclopsv1alpha1.ModuleRevision{}
+	err := c.restClient.
+		Post().
+		Namespace(c.ns).
+		Resource("modulerevisions").
+		Body(revision).
+		Do(context.Background()).
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *moduleRevisionClient) Delete(name string) error {
+	return c.restClient.
+		Delete().
+		Namespace(c.ns).
+		Resource("modulerevisions").
+		Name(name).
+		Do(context.Background()).
+		Error()
+}
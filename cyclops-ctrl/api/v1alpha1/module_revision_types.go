@@ -0,0 +1,59 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModuleRevisionModuleLabel is set on a ModuleRevision to the name of its Module
+const ModuleRevisionModuleLabel = "cyclops-ui.com/module"
+
+// ModuleRevisionSpec defines a single reconciled state of a Module
+type ModuleRevisionSpec struct {
+	ModuleName string `json:"moduleName"`
+
+	HistoryEntry `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Module",type=string,JSONPath=`.spec.moduleName`
+//+kubebuilder:printcolumn:name="Generation",type=integer,JSONPath=`.spec.generation`
+//+kubebuilder:printcolumn:name="Template version",type=string,JSONPath=`.spec.template.version`
+//+kubebuilder:printcolumn:name="Author",type=string,JSONPath=`.spec.author`,priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ModuleRevision stores a generation of a Module for history and rollbacks
+type ModuleRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ModuleRevisionSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ModuleRevisionList contains a list of ModuleRevision
+type ModuleRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModuleRevision `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModuleRevision{}, &ModuleRevisionList{})
+}
@@ -30,6 +30,46 @@ type ModuleSpec struct {
 	TargetNamespace string               `json:"targetNamespace"`
 	TemplateRef     TemplateRef          `json:"template"`
 	Values          apiextensionsv1.JSON `json:"values"`
+
+	// DependsOn lists Modules that have to be Ready before this Module is reconciled
+	// +kubebuilder:validation:Optional
+	DependsOn []ModuleReference `json:"dependsOn,omitempty"`
+
+	// ValuesFrom lists Secrets and ConfigMaps in the Module namespace that are
+	// merged into Values, in order, when the Module is rendered
+	// +kubebuilder:validation:Optional
+	ValuesFrom []ValuesReference `json:"valuesFrom,omitempty"`
+}
+
+type ModuleReference struct {
+	Name string `json:"name"`
+}
+
+const (
+	ValuesFromKindSecret    = "Secret"
+	ValuesFromKindConfigMap = "ConfigMap"
+)
+
+type ValuesReference struct {
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	// Key selects a single key of the source. Without TargetPath, the key value
+	// is parsed as YAML and merged into the values root. If Key is not set, all
+	// keys of the source are set as strings under TargetPath.
+	// +kubebuilder:validation:Optional
+	Key string `json:"key,omitempty"`
+
+	// TargetPath is a dot separated path in values the source is set at. Dots
+	// in keys are escaped with a backslash, like in helm --set, e.g.
+	// podAnnotations.prometheus\.io/scrape
+	// +kubebuilder:validation:Optional
+	TargetPath string `json:"targetPath,omitempty"`
+
+	// Optional skips the source if it, or the selected key, does not exist
+	// +kubebuilder:validation:Optional
+	Optional bool `json:"optional,omitempty"`
 }
 
 type ModuleValue struct {
@@ -48,12 +88,36 @@ const (
 	GitOpsWritePathAnnotation     = "cyclops-ui.com/write-path"
 	GitOpsWriteRevisionAnnotation = "cyclops-ui.com/write-revision"
 
+	// GitOpsWriteModeAnnotation overrides the GitOps write mode of a Module.
+	// Set to pull-request to open pull requests instead of pushing to the write
+	// revision.
+	GitOpsWriteModeAnnotation  = "cyclops-ui.com/write-mode"
+	GitOpsWriteModePush        = "push"
+	GitOpsWriteModePullRequest = "pull-request"
+
 	ModuleManagerLabel = "cyclops-ui.com/module-manager"
 
 	AddonModuleLabel     = "cyclops-ui.com/addon"
 	MCPServerModuleLabel = "cyclops-ui.com/mcp-server"
 
 	ResourceFinalizer = "cyclops-ui.com/module-resources"
+
+	// PruneAnnotation set to "false" on a Module disables deleting resources
+	// that are no longer rendered from the Module template
+	PruneAnnotation = "cyclops-ui.com/prune"
+
+	// ResourcePolicyAnnotation set to "keep" on a Module child resource excludes
+	// it from pruning
+	ResourcePolicyAnnotation = "cyclops-ui.com/resource-policy"
+	ResourcePolicyKeep       = "keep"
+
+	// ForceConflictsAnnotation set to "true" on a Module makes server-side apply
+	// of its child resources take ownership of fields managed by other controllers
+	ForceConflictsAnnotation = "cyclops-ui.com/force-conflicts"
+
+	// AuthorAnnotation holds the user that made the last change to a Module and
+	// is recorded on the Module revision
+	AuthorAnnotation = "cyclops-ui.com/author"
 )
 
 type GitOpsWriteDestination struct {
@@ -73,6 +137,60 @@ type TemplateRef struct {
 
 	// +kubebuilder:validation:Optional
 	EnforceGitOpsWrite *GitOpsWriteDestination `json:"enforceGitOpsWrite,omitempty"`
+
+	// UpdatePolicy opts the Module into automatic updates of its resolved
+	// template version
+	// +kubebuilder:validation:Optional
+	UpdatePolicy *TemplateUpdatePolicy `json:"updatePolicy,omitempty"`
+}
+
+type TemplateUpdateStrategy string
+
+const (
+	// TemplateUpdatePatch updates to the latest version with the same major and
+	// minor version
+	TemplateUpdatePatch TemplateUpdateStrategy = "patch"
+	// TemplateUpdateMinor updates to the latest version with the same major
+	// version
+	TemplateUpdateMinor TemplateUpdateStrategy = "minor"
+	// TemplateUpdateRange updates to the latest version matching a semver range
+	TemplateUpdateRange TemplateUpdateStrategy = "range"
+	// TemplateUpdateBranch updates git templates to the head of their branch
+	TemplateUpdateBranch TemplateUpdateStrategy = "branch"
+)
+
+type TemplateUpdatePolicy struct {
+	// +kubebuilder:validation:Enum=patch;minor;range;branch
+	Strategy TemplateUpdateStrategy `json:"strategy"`
+
+	// Range is the semver constraint of the range strategy. Defaults to the
+	// template version.
+	// +kubebuilder:validation:Optional
+	Range string `json:"range,omitempty"`
+
+	// MaintenanceWindow limits when updates are applied. Updates are applied
+	// as soon as they are found if not set.
+	// +kubebuilder:validation:Optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type MaintenanceDay string
+
+type MaintenanceWindow struct {
+	// Days the window opens on. Every day if empty.
+	// +kubebuilder:validation:Optional
+	Days []MaintenanceDay `json:"days,omitempty"`
+
+	// Start is the time of day the window opens, e.g. 02:00
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA time zone of the window. Defaults to UTC.
+	// +kubebuilder:validation:Optional
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
 type TemplateGitRef struct {
@@ -89,6 +207,24 @@ const (
 	Failed    ReconciliationStatusState = "failed"
 )
 
+// Module condition types
+const (
+	// ModuleReconciled is true when all resources rendered from the Module
+	// template were applied to the cluster
+	ModuleReconciled = "Reconciled"
+	// ModuleReady is true when the Module is reconciled and all of its
+	// workloads are available
+	ModuleReady = "Ready"
+	// ModuleProgressing is true while Module workloads are rolling out
+	ModuleProgressing = "Progressing"
+	// ModuleDegraded is true when the Module failed to reconcile or any of its
+	// workloads is unhealthy
+	ModuleDegraded = "Degraded"
+	// ModuleDriftDetected is true when the Module in the cluster differs from
+	// the Module committed to its git destination
+	ModuleDriftDetected = "DriftDetected"
+)
+
 type ReconciliationStatus struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Enum=unknown;succeeded;failed
@@ -106,6 +242,16 @@ type GroupVersionResource struct {
 	Resource string `json:"resource"`
 }
 
+type PrunedResource struct {
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+
+	PrunedAt metav1.Time `json:"prunedAt"`
+}
+
 // ModuleStatus defines the observed state of Module
 type ModuleStatus struct {
 	ReconciliationStatus    *ReconciliationStatus `json:"reconciliationStatus,omitempty"`
@@ -114,6 +260,14 @@ type ModuleStatus struct {
 	ManagedGVRs []GroupVersionResource `json:"managedGVRs,omitempty"`
 	// +kubebuilder:validation:Optional
 	IconURL string `json:"iconURL,omitempty"`
+	// +kubebuilder:validation:Optional
+	PrunedResources []PrunedResource `json:"prunedResources,omitempty"`
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type HistoryTemplateRef struct {
@@ -133,6 +287,15 @@ type HistoryEntry struct {
 	TargetNamespace string               `json:"targetNamespace"`
 	TemplateRef     HistoryTemplateRef   `json:"template"`
 	Values          apiextensionsv1.JSON `json:"values"`
+
+	// Author is the user that made the change to the Module
+	// +kubebuilder:validation:Optional
+	Author string `json:"author,omitempty"`
+	// +kubebuilder:validation:Optional
+	Timestamp *metav1.Time `json:"timestamp,omitempty"`
+	// ManifestDigest is the sha256 digest of the manifest rendered from the entry
+	// +kubebuilder:validation:Optional
+	ManifestDigest string `json:"manifestDigest,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -144,6 +307,7 @@ type HistoryEntry struct {
 //+kubebuilder:printcolumn:name="Template version",type=string,JSONPath=`.spec.template.version`,priority=1
 //+kubebuilder:printcolumn:name="Template resolved version",type=string,JSONPath=`.status.templateResolvedVersion`,priority=1
 //+kubebuilder:printcolumn:name="Reconciliation Status",type=string,JSONPath=`.status.reconciliationStatus.status`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 
 // Module is the Schema for the modules API
 type Module struct {
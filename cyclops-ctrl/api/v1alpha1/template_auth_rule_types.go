@@ -24,6 +24,14 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+type TemplateAuthType string
+
+const (
+	TemplateAuthTypeBasic     TemplateAuthType = "basic"
+	TemplateAuthTypeSSH       TemplateAuthType = "ssh"
+	TemplateAuthTypeGitHubApp TemplateAuthType = "githubApp"
+)
+
 // TemplateAuthRuleSpec defines the desired state of TemplateAuthRule
 type TemplateAuthRuleSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -31,8 +39,73 @@ type TemplateAuthRuleSpec struct {
 
 	Repo string `json:"repo"`
 
-	Username v1.SecretKeySelector `json:"username"`
-	Password v1.SecretKeySelector `json:"password"`
+	// Type of the credentials. Defaults to basic, which uses the username,
+	// password, token and docker config.
+	// +kubebuilder:validation:Enum=basic;ssh;githubApp
+	// +optional
+	Type TemplateAuthType `json:"type,omitempty"`
+
+	// Username and Password are used for basic authentication
+	// +optional
+	Username v1.SecretKeySelector `json:"username,omitempty"`
+	// +optional
+	Password v1.SecretKeySelector `json:"password,omitempty"`
+
+	// Token is a bearer token sent to Helm and OCI registries
+	// +optional
+	Token v1.SecretKeySelector `json:"token,omitempty"`
+
+	// DockerConfig references a docker config JSON secret. The credentials of
+	// the repository host are used. Key defaults to .dockerconfigjson
+	// +optional
+	DockerConfig v1.SecretKeySelector `json:"dockerConfig,omitempty"`
+
+	// SSH is used by the ssh type
+	// +optional
+	SSH *SSHAuth `json:"ssh,omitempty"`
+
+	// GitHubApp is used by the githubApp type
+	// +optional
+	GitHubApp *GitHubAppAuth `json:"githubApp,omitempty"`
+
+	// Verification requires templates of the repository to be signed by one of
+	// the trusted keys. Templates that fail verification are not rendered.
+	// +optional
+	Verification *TemplateVerification `json:"verification,omitempty"`
+}
+
+type SSHAuth struct {
+	PrivateKey v1.SecretKeySelector `json:"privateKey"`
+	// KnownHosts references the known_hosts entries used to verify the git
+	// server host key
+	KnownHosts v1.SecretKeySelector `json:"knownHosts"`
+
+	// User defaults to git
+	// +optional
+	User string `json:"user,omitempty"`
+}
+
+// GitHubAppAuth authenticates with installation tokens of a GitHub App. Tokens
+// are refreshed before they expire.
+type GitHubAppAuth struct {
+	AppID          int64                `json:"appID"`
+	InstallationID int64                `json:"installationID"`
+	PrivateKey     v1.SecretKeySelector `json:"privateKey"`
+
+	// APIURL defaults to https://api.github.com. Set it for GitHub Enterprise
+	// Server, e.g. https://github.example.com/api/v3
+	// +optional
+	APIURL string `json:"apiURL,omitempty"`
+}
+
+// TemplateVerification verifies signed git commits and tags, provenance files
+// of Helm charts and cosign signatures of OCI charts
+type TemplateVerification struct {
+	// PublicKeys references the trusted keys: armored PGP public keys or SSH
+	// public keys in the authorized_keys format for git, armored PGP public
+	// keys for Helm provenance files and PEM encoded cosign public keys for
+	// OCI charts
+	PublicKeys v1.SecretKeySelector `json:"publicKeys"`
 }
 
 //+kubebuilder:object:root=true
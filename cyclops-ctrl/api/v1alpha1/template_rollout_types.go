@@ -0,0 +1,145 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type TemplateRolloutPhase string
+
+const (
+	RolloutPending     TemplateRolloutPhase = "Pending"
+	RolloutProgressing TemplateRolloutPhase = "Progressing"
+	RolloutSucceeded   TemplateRolloutPhase = "Succeeded"
+	RolloutFailed      TemplateRolloutPhase = "Failed"
+)
+
+type ModuleRolloutState string
+
+const (
+	ModuleRolloutPending    ModuleRolloutState = "Pending"
+	ModuleRolloutUpgrading  ModuleRolloutState = "Upgrading"
+	ModuleRolloutSucceeded  ModuleRolloutState = "Succeeded"
+	ModuleRolloutFailed     ModuleRolloutState = "Failed"
+	ModuleRolloutRolledBack ModuleRolloutState = "RolledBack"
+
+	// ModuleRolloutSkipped Modules are written to git and have to be upgraded
+	// in their git repository
+	ModuleRolloutSkipped ModuleRolloutState = "Skipped"
+)
+
+// RolloutTemplateRef selects Modules created from a template, regardless of the
+// template version
+type RolloutTemplateRef struct {
+	URL  string `json:"repo"`
+	Path string `json:"path"`
+}
+
+// TemplateRolloutSpec defines the desired state of TemplateRollout
+type TemplateRolloutSpec struct {
+	// Selector selects Modules by labels
+	// +kubebuilder:validation:Optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// TemplateRef selects Modules by their template repository and path
+	// +kubebuilder:validation:Optional
+	TemplateRef *RolloutTemplateRef `json:"template,omitempty"`
+
+	// Version is the template version selected Modules are upgraded to
+	Version string `json:"version"`
+
+	// BatchSize is the number of Modules upgraded in a single wave
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=1
+	BatchSize int `json:"batchSize,omitempty"`
+
+	// PauseBetweenWaves is how long to wait after a wave is Ready before
+	// starting the next one
+	// +kubebuilder:validation:Optional
+	PauseBetweenWaves metav1.Duration `json:"pauseBetweenWaves,omitempty"`
+
+	// ProgressDeadline is how long Modules of a wave can take to become Ready
+	// before the wave is considered failed. Defaults to 10 minutes.
+	// +kubebuilder:validation:Optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+type ModuleRolloutStatus struct {
+	Name  string             `json:"name"`
+	Wave  int                `json:"wave"`
+	State ModuleRolloutState `json:"state"`
+
+	// PreviousGeneration is the Module generation before the upgrade, used to
+	// find the history entry to roll back to
+	// +kubebuilder:validation:Optional
+	PreviousGeneration int64 `json:"previousGeneration,omitempty"`
+	// +kubebuilder:validation:Optional
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	// +kubebuilder:validation:Optional
+	UpgradedGeneration int64 `json:"upgradedGeneration,omitempty"`
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// TemplateRolloutStatus defines the observed state of TemplateRollout
+type TemplateRolloutStatus struct {
+	// +kubebuilder:validation:Optional
+	Phase TemplateRolloutPhase `json:"phase,omitempty"`
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +kubebuilder:validation:Optional
+	CurrentWave int `json:"currentWave"`
+	// +kubebuilder:validation:Optional
+	WaveStartedAt *metav1.Time `json:"waveStartedAt,omitempty"`
+	// +kubebuilder:validation:Optional
+	WaveCompletedAt *metav1.Time `json:"waveCompletedAt,omitempty"`
+	// +kubebuilder:validation:Optional
+	Modules []ModuleRolloutStatus `json:"modules,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Wave",type=integer,JSONPath=`.status.currentWave`
+
+// TemplateRollout upgrades the template version of selected Modules in waves
+type TemplateRollout struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TemplateRolloutSpec   `json:"spec,omitempty"`
+	Status TemplateRolloutStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TemplateRolloutList contains a list of TemplateRollout
+type TemplateRolloutList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TemplateRollout `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TemplateRollout{}, &TemplateRolloutList{})
+}
@@ -21,9 +21,26 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHubAppAuth) DeepCopyInto(out *GitHubAppAuth) {
+	*out = *in
+	in.PrivateKey.DeepCopyInto(&out.PrivateKey)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubAppAuth.
+func (in *GitHubAppAuth) DeepCopy() *GitHubAppAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubAppAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitOpsWriteDestination) DeepCopyInto(out *GitOpsWriteDestination) {
 	*out = *in
@@ -59,6 +76,10 @@ func (in *HistoryEntry) DeepCopyInto(out *HistoryEntry) {
 	*out = *in
 	out.TemplateRef = in.TemplateRef
 	in.Values.DeepCopyInto(&out.Values)
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistoryEntry.
@@ -86,6 +107,27 @@ func (in *HistoryTemplateRef) DeepCopy() *HistoryTemplateRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]MaintenanceDay, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Module) DeepCopyInto(out *Module) {
 	*out = *in
@@ -152,11 +194,125 @@ func (in *ModuleList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleReference) DeepCopyInto(out *ModuleReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleReference.
+func (in *ModuleReference) DeepCopy() *ModuleReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleRevision) DeepCopyInto(out *ModuleRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleRevision.
+func (in *ModuleRevision) DeepCopy() *ModuleRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModuleRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleRevisionList) DeepCopyInto(out *ModuleRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ModuleRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleRevisionList.
+func (in *ModuleRevisionList) DeepCopy() *ModuleRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModuleRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleRevisionSpec) DeepCopyInto(out *ModuleRevisionSpec) {
+	*out = *in
+	in.HistoryEntry.DeepCopyInto(&out.HistoryEntry)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleRevisionSpec.
+func (in *ModuleRevisionSpec) DeepCopy() *ModuleRevisionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleRevisionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleRolloutStatus) DeepCopyInto(out *ModuleRolloutStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleRolloutStatus.
+func (in *ModuleRolloutStatus) DeepCopy() *ModuleRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModuleSpec) DeepCopyInto(out *ModuleSpec) {
 	*out = *in
 	in.TemplateRef.DeepCopyInto(&out.TemplateRef)
 	in.Values.DeepCopyInto(&out.Values)
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]ModuleReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValuesReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleSpec.
@@ -182,6 +338,20 @@ func (in *ModuleStatus) DeepCopyInto(out *ModuleStatus) {
 		*out = make([]GroupVersionResource, len(*in))
 		copy(*out, *in)
 	}
+	if in.PrunedResources != nil {
+		in, out := &in.PrunedResources, &out.PrunedResources
+		*out = make([]PrunedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleStatus.
@@ -209,6 +379,22 @@ func (in *ModuleValue) DeepCopy() *ModuleValue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrunedResource) DeepCopyInto(out *PrunedResource) {
+	*out = *in
+	in.PrunedAt.DeepCopyInto(&out.PrunedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrunedResource.
+func (in *PrunedResource) DeepCopy() *PrunedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(PrunedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReconciliationStatus) DeepCopyInto(out *ReconciliationStatus) {
 	*out = *in
@@ -229,6 +415,38 @@ func (in *ReconciliationStatus) DeepCopy() *ReconciliationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutTemplateRef) DeepCopyInto(out *RolloutTemplateRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutTemplateRef.
+func (in *RolloutTemplateRef) DeepCopy() *RolloutTemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHAuth) DeepCopyInto(out *SSHAuth) {
+	*out = *in
+	in.PrivateKey.DeepCopyInto(&out.PrivateKey)
+	in.KnownHosts.DeepCopyInto(&out.KnownHosts)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHAuth.
+func (in *SSHAuth) DeepCopy() *SSHAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateAuthRule) DeepCopyInto(out *TemplateAuthRule) {
 	*out = *in
@@ -292,6 +510,23 @@ func (in *TemplateAuthRuleSpec) DeepCopyInto(out *TemplateAuthRuleSpec) {
 	*out = *in
 	in.Username.DeepCopyInto(&out.Username)
 	in.Password.DeepCopyInto(&out.Password)
+	in.Token.DeepCopyInto(&out.Token)
+	in.DockerConfig.DeepCopyInto(&out.DockerConfig)
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(SSHAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitHubApp != nil {
+		in, out := &in.GitHubApp, &out.GitHubApp
+		*out = new(GitHubAppAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(TemplateVerification)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateAuthRuleSpec.
@@ -327,6 +562,11 @@ func (in *TemplateRef) DeepCopyInto(out *TemplateRef) {
 		*out = new(GitOpsWriteDestination)
 		**out = **in
 	}
+	if in.UpdatePolicy != nil {
+		in, out := &in.UpdatePolicy, &out.UpdatePolicy
+		*out = new(TemplateUpdatePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRef.
@@ -339,6 +579,124 @@ func (in *TemplateRef) DeepCopy() *TemplateRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRollout) DeepCopyInto(out *TemplateRollout) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRollout.
+func (in *TemplateRollout) DeepCopy() *TemplateRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TemplateRollout) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRolloutList) DeepCopyInto(out *TemplateRolloutList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TemplateRollout, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRolloutList.
+func (in *TemplateRolloutList) DeepCopy() *TemplateRolloutList {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRolloutList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TemplateRolloutList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRolloutSpec) DeepCopyInto(out *TemplateRolloutSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(RolloutTemplateRef)
+		**out = **in
+	}
+	out.PauseBetweenWaves = in.PauseBetweenWaves
+	if in.ProgressDeadline != nil {
+		in, out := &in.ProgressDeadline, &out.ProgressDeadline
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRolloutSpec.
+func (in *TemplateRolloutSpec) DeepCopy() *TemplateRolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRolloutStatus) DeepCopyInto(out *TemplateRolloutStatus) {
+	*out = *in
+	if in.WaveStartedAt != nil {
+		in, out := &in.WaveStartedAt, &out.WaveStartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.WaveCompletedAt != nil {
+		in, out := &in.WaveCompletedAt, &out.WaveCompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]ModuleRolloutStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRolloutStatus.
+func (in *TemplateRolloutStatus) DeepCopy() *TemplateRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateStore) DeepCopyInto(out *TemplateStore) {
 	*out = *in
@@ -396,3 +754,54 @@ func (in *TemplateStoreList) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateUpdatePolicy) DeepCopyInto(out *TemplateUpdatePolicy) {
+	*out = *in
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateUpdatePolicy.
+func (in *TemplateUpdatePolicy) DeepCopy() *TemplateUpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateUpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateVerification) DeepCopyInto(out *TemplateVerification) {
+	*out = *in
+	in.PublicKeys.DeepCopyInto(&out.PublicKeys)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateVerification.
+func (in *TemplateVerification) DeepCopy() *TemplateVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesReference) DeepCopyInto(out *ValuesReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesReference.
+func (in *ValuesReference) DeepCopy() *ValuesReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesReference)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -4,16 +4,22 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/drift"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git/pullrequests"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/cache"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/fetch"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/render"
 
 	_ "github.com/joho/godotenv/autoload"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -25,11 +31,17 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/audit"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/handler"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/history"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/integrations/helm"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/modulecontroller"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/modulewebhook"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/prometheus"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/rolloutcontroller"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/telemetry"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/updates"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
 
 	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
@@ -78,10 +90,13 @@ func main() {
 	helmWatchNamespace := getHelmWatchNamespace()
 	moduleTargetNamespace := getModuleTargetNamespace()
 
-	k8sClient, err := k8sclient.New(
-		watchNamespace,
-		helmWatchNamespace,
-		moduleTargetNamespace,
+	k8sClient, err := k8sclient.NewWithConfig(
+		k8sclient.ClientConfig{
+			ModuleNamespace:       watchNamespace,
+			HelmReleaseNamespace:  helmWatchNamespace,
+			ModuleTargetNamespace: moduleTargetNamespace,
+			FieldManager:          getFieldManager(),
+		},
 		zap.New(zap.UseFlagOptions(&opts)),
 	)
 	if err != nil {
@@ -91,24 +106,72 @@ func main() {
 
 	credsResolver := auth.NewTemplatesResolver(k8sClient)
 
-	templatesRepo := template.NewRepo(
-		credsResolver,
-		cache.NewInMemoryTemplatesCache(),
-	)
-
 	monitor, err := prometheus.NewMonitor(setupLog)
 	if err != nil {
 		setupLog.Error(err, "failed to set up prom monitor")
 	}
 
+	fetch.Configure(getTemplateFetchLimits())
+
+	templatesRepo, err := newTemplatesRepo(credsResolver, &monitor)
+	if err != nil {
+		setupLog.Error(err, "failed to set up templates cache")
+		os.Exit(1)
+	}
+
+	go func() {
+		modules, err := k8sClient.ListModules()
+		if err != nil {
+			setupLog.Error(err, "failed to list modules for template cache warm up")
+			return
+		}
+
+		template.WarmUp(templatesRepo, modules, setupLog.WithName("template-cache"))
+	}()
+
 	renderer := render.NewRenderer(k8sClient)
 
 	prometheus.StartCacheMetricsUpdater(&monitor, templatesRepo.ReturnCache(), 10*time.Second, setupLog)
 
 	helmReleaseClient := helm.NewReleaseClient(helmWatchNamespace, k8sClient)
-	gitWriteClient := git.NewWriteClient(credsResolver, getCommitMessageTemplate(), setupLog)
+	gitWriteClient := git.NewWriteClient(credsResolver, getCommitMessageTemplate(), getGitOpsWriteDestination(), getGitOpsPullRequestConfig(), setupLog)
+
+	driftDetector := drift.NewDetector(
+		k8sClient,
+		gitWriteClient,
+		&monitor,
+		getEnvDuration("GITOPS_DRIFT_CHECK_INTERVAL", drift.DefaultInterval),
+		getEnvBool("GITOPS_DRIFT_RESYNC"),
+		setupLog.WithName("drift"),
+	)
 
-	handler, err := handler.New(templatesRepo, k8sClient, helmReleaseClient, renderer, gitWriteClient, moduleTargetNamespace, telemetryClient, monitor)
+	apiAuth, err := apiauth.New(getAPIAuthConfig(), k8sClient)
+	if err != nil {
+		setupLog.Error(err, "failed to set up API authentication")
+		os.Exit(1)
+	}
+
+	auditSinks, err := audit.NewSinks(getEnvList("AUDIT_SINKS"), os.Getenv("AUDIT_FILE_PATH"), k8sClient)
+	if err != nil {
+		setupLog.Error(err, "failed to set up audit sinks")
+		os.Exit(1)
+	}
+
+	handler, err := handler.New(
+		templatesRepo,
+		k8sClient,
+		helmReleaseClient,
+		renderer,
+		gitWriteClient,
+		driftDetector,
+		moduleTargetNamespace,
+		apiAuth,
+		audit.New(audit.DefaultRetainedEntries, setupLog.WithName("audit"), auditSinks...),
+		getEnvList("CORS_ALLOWED_ORIGINS"),
+		os.Getenv("TEMPLATE_WEBHOOK_SECRET"),
+		telemetryClient,
+		monitor,
+	)
 	if err != nil {
 		panic(err)
 	}
@@ -144,14 +207,54 @@ func main() {
 		k8sClient,
 		renderer,
 		getMaxConcurrentReconciles(),
+		getModuleHistoryLimit(),
 		telemetryClient,
 		monitor,
 	)).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Module")
 		os.Exit(1)
 	}
+
+	if err = (rolloutcontroller.NewTemplateRolloutReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		k8sClient,
+		gitWriteClient,
+	)).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TemplateRollout")
+		os.Exit(1)
+	}
+
+	if getEnvBool("ENABLE_WEBHOOKS") {
+		if err = (modulewebhook.NewModuleWebhook(
+			templatesRepo,
+			k8sClient,
+			renderer,
+			moduleTargetNamespace,
+		)).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Module")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if err := mgr.Add(driftDetector); err != nil {
+		setupLog.Error(err, "unable to set up drift detection")
+		os.Exit(1)
+	}
+
+	templateUpdater := updates.NewUpdater(
+		templatesRepo,
+		k8sClient,
+		getEnvDuration("TEMPLATE_UPDATE_INTERVAL", updates.DefaultInterval),
+		getModuleHistoryLimit(),
+		setupLog.WithName("template-updates"),
+	)
+	if err := mgr.Add(templateUpdater); err != nil {
+		setupLog.Error(err, "unable to set up automatic template updates")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -180,6 +283,31 @@ func getEnvBool(key string) bool {
 	return b
 }
 
+func getEnvList(key string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if len(value) != 0 {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getAPIAuthConfig() apiauth.Config {
+	return apiauth.Config{
+		Authenticators: getEnvList("AUTH_AUTHENTICATORS"),
+		Authorization:  getEnvList("AUTH_AUTHORIZATION"),
+		OIDC: apiauth.OIDCConfig{
+			JWKSPath:      os.Getenv("AUTH_OIDC_JWKS_PATH"),
+			Issuer:        os.Getenv("AUTH_OIDC_ISSUER"),
+			Audience:      os.Getenv("AUTH_OIDC_AUDIENCE"),
+			UsernameClaim: os.Getenv("AUTH_OIDC_USERNAME_CLAIM"),
+			GroupsClaim:   os.Getenv("AUTH_OIDC_GROUPS_CLAIM"),
+		},
+	}
+}
+
 func getWatchNamespace() string {
 	value := os.Getenv("WATCH_NAMESPACE")
 	if value == "" {
@@ -214,6 +342,125 @@ func getMaxConcurrentReconciles() int {
 	return value
 }
 
+func getModuleHistoryLimit() int {
+	strValue := os.Getenv("MODULE_HISTORY_LIMIT")
+	if strValue == "" {
+		return history.DefaultLimit
+	}
+
+	value, err := strconv.Atoi(strValue)
+	if err != nil || value < 1 {
+		return history.DefaultLimit
+	}
+
+	return value
+}
+
+func newTemplatesRepo(credsResolver auth.TemplatesResolver, observer template.FetchObserver) (template.ITemplateRepo, error) {
+	ttl := cache.TTL{
+		Immutable: getEnvDuration("TEMPLATE_CACHE_IMMUTABLE_TTL", 0),
+		Mutable:   getEnvDuration("TEMPLATE_CACHE_TTL", cache.DefaultMutableTTL),
+	}
+
+	switch os.Getenv("TEMPLATE_CACHE") {
+	case "", "memory":
+		return template.NewRepo(credsResolver, cache.NewInMemoryTemplatesCache(ttl), observer), nil
+	case "disk":
+		dir := os.Getenv("TEMPLATE_CACHE_DIR")
+		if len(dir) == 0 {
+			dir = filepath.Join(os.TempDir(), "cyclops-templates")
+		}
+
+		maxSize, err := getTemplateCacheMaxSize()
+		if err != nil {
+			return nil, err
+		}
+
+		diskCache, err := cache.NewDiskTemplatesCache(dir, maxSize, ttl)
+		if err != nil {
+			return nil, err
+		}
+
+		return template.NewRepo(credsResolver, diskCache, observer), nil
+	}
+
+	return nil, fmt.Errorf("unknown TEMPLATE_CACHE %v, use memory or disk", os.Getenv("TEMPLATE_CACHE"))
+}
+
+func getTemplateCacheMaxSize() (int64, error) {
+	value := os.Getenv("TEMPLATE_CACHE_MAX_SIZE")
+	if len(value) == 0 {
+		return cache.DefaultDiskMaxSize, nil
+	}
+
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TEMPLATE_CACHE_MAX_SIZE: %w", err)
+	}
+
+	return quantity.Value(), nil
+}
+
+func getTemplateFetchLimits() fetch.Limits {
+	limits := fetch.DefaultLimits()
+
+	if value, err := strconv.ParseFloat(os.Getenv("TEMPLATE_FETCH_RATE_LIMIT"), 64); err == nil && value >= 0 {
+		limits.RequestsPerSecond = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("TEMPLATE_FETCH_BURST")); err == nil && value > 0 {
+		limits.Burst = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("TEMPLATE_FETCH_MAX_CONCURRENCY")); err == nil && value >= 0 {
+		limits.MaxConcurrent = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("TEMPLATE_FETCH_MAX_RETRIES")); err == nil && value >= 0 {
+		limits.MaxRetries = value
+	}
+
+	return limits
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+
+	return duration
+}
+
 func getCommitMessageTemplate() string {
 	return os.Getenv("COMMIT_MESSAGE_TEMPLATE")
 }
+
+func getGitOpsWriteDestination() *cyclopsv1alpha1.GitOpsWriteDestination {
+	repo := os.Getenv("GITOPS_WRITE_REPO")
+	if len(repo) == 0 {
+		return nil
+	}
+
+	return &cyclopsv1alpha1.GitOpsWriteDestination{
+		Repo:    repo,
+		Path:    os.Getenv("GITOPS_WRITE_PATH"),
+		Version: os.Getenv("GITOPS_WRITE_REVISION"),
+	}
+}
+
+func getGitOpsPullRequestConfig() git.PullRequestConfig {
+	return git.PullRequestConfig{
+		Enabled: os.Getenv("GITOPS_WRITE_MODE") == cyclopsv1alpha1.GitOpsWriteModePullRequest,
+		Config: pullrequests.Config{
+			Provider: os.Getenv("GITOPS_PR_PROVIDER"),
+			APIURL:   os.Getenv("GITOPS_PR_API_URL"),
+		},
+	}
+}
+
+func getFieldManager() string {
+	return os.Getenv("FIELD_MANAGER")
+}
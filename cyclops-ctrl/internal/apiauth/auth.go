@@ -0,0 +1,221 @@
+package apiauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+const (
+	AuthenticatorOIDC        = "oidc"
+	AuthenticatorTokenReview = "tokenreview"
+
+	AuthorizationSubjectAccessReview = "sar"
+	AuthorizationImpersonate         = "impersonate"
+
+	// SubjectAccessReviewPathPrefix is prepended to request paths checked with
+	// SubjectAccessReviews, so ClusterRoles grant access to the API with
+	// nonResourceURLs like /cyclops/modules/*
+	SubjectAccessReviewPathPrefix = "/cyclops"
+
+	userKey             = "cyclops-user"
+	kubernetesClientKey = "cyclops-kubernetes-client"
+)
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+//+kubebuilder:rbac:groups="",resources=users;groups;serviceaccounts,verbs=impersonate
+
+type User struct {
+	Name   string
+	Groups []string
+}
+
+// Authenticator maps a bearer token to a user. It returns an error if it can
+// not authenticate the token.
+type Authenticator interface {
+	AuthenticateToken(token string) (*User, error)
+}
+
+type Config struct {
+	// Authenticators enabled for the API, in order. Authentication is disabled if
+	// none are set.
+	Authenticators []string
+	// Authorization modes enabled for the API
+	Authorization []string
+
+	OIDC OIDCConfig
+}
+
+type Auth struct {
+	authenticators []Authenticator
+
+	subjectAccessReview bool
+	impersonate         bool
+
+	kubernetesClient k8sclient.IKubernetesClient
+}
+
+func New(config Config, kubernetesClient k8sclient.IKubernetesClient) (*Auth, error) {
+	a := &Auth{
+		authenticators:   make([]Authenticator, 0, len(config.Authenticators)),
+		kubernetesClient: kubernetesClient,
+	}
+
+	for _, name := range config.Authenticators {
+		switch name {
+		case AuthenticatorOIDC:
+			oidc, err := NewOIDCAuthenticator(config.OIDC)
+			if err != nil {
+				return nil, err
+			}
+			a.authenticators = append(a.authenticators, oidc)
+		case AuthenticatorTokenReview:
+			a.authenticators = append(a.authenticators, NewTokenReviewAuthenticator(kubernetesClient))
+		default:
+			return nil, fmt.Errorf("unknown authenticator %v", name)
+		}
+	}
+
+	for _, mode := range config.Authorization {
+		switch mode {
+		case AuthorizationSubjectAccessReview:
+			a.subjectAccessReview = true
+		case AuthorizationImpersonate:
+			a.impersonate = true
+		default:
+			return nil, fmt.Errorf("unknown authorization mode %v", mode)
+		}
+	}
+
+	if !a.Enabled() && (a.subjectAccessReview || a.impersonate) {
+		return nil, errors.New("authorization requires at least one authenticator")
+	}
+
+	return a, nil
+}
+
+// Enabled returns true if API requests have to be authenticated
+func (a *Auth) Enabled() bool {
+	return len(a.authenticators) != 0
+}
+
+// Middleware authenticates the request bearer token and authorizes the request
+// user. The user, and the client impersonating the user if enabled, are set on
+// the request context.
+func (a *Auth) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !a.Enabled() {
+			ctx.Next()
+			return
+		}
+
+		token := bearerToken(ctx.Request)
+		if len(token) == 0 {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewError("Unauthorized", "missing bearer token"))
+			return
+		}
+
+		user, err := a.authenticate(token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewError("Unauthorized", err.Error()))
+			return
+		}
+
+		if a.subjectAccessReview {
+			allowed, reason, err := a.kubernetesClient.SubjectAccessReview(
+				user.Name,
+				user.Groups,
+				SubjectAccessReviewPathPrefix+ctx.Request.URL.Path,
+				strings.ToLower(ctx.Request.Method),
+			)
+			if err != nil {
+				fmt.Println(err)
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.NewError("Error authorizing request", err.Error()))
+				return
+			}
+
+			if !allowed {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, dto.NewError("Forbidden", reason))
+				return
+			}
+		}
+
+		if a.impersonate {
+			client, err := a.kubernetesClient.Impersonate(user.Name, user.Groups)
+			if err != nil {
+				fmt.Println(err)
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, dto.NewError("Error creating Kubernetes client", err.Error()))
+				return
+			}
+
+			ctx.Set(kubernetesClientKey, client)
+		}
+
+		ctx.Set(userKey, user)
+		ctx.Next()
+	}
+}
+
+func (a *Auth) authenticate(token string) (*User, error) {
+	errs := make([]error, 0, len(a.authenticators))
+	for _, authenticator := range a.authenticators {
+		user, err := authenticator.AuthenticateToken(token)
+		if err == nil {
+			return user, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// UserFrom returns the authenticated user of the request, or nil if
+// authentication is disabled
+func UserFrom(ctx *gin.Context) *User {
+	user, ok := ctx.Get(userKey)
+	if !ok {
+		return nil
+	}
+
+	return user.(*User)
+}
+
+// KubernetesClient returns the client impersonating the request user, or the
+// given client if impersonation is disabled
+func KubernetesClient(ctx *gin.Context, kubernetesClient k8sclient.IKubernetesClient) k8sclient.IKubernetesClient {
+	client, ok := ctx.Get(kubernetesClientKey)
+	if !ok {
+		return kubernetesClient
+	}
+
+	return client.(k8sclient.IKubernetesClient)
+}
+
+// bearerToken returns the token from the Authorization header. Browsers can not
+// set headers on websocket requests, so the token of websocket requests can also
+// be passed in the access_token query parameter.
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) != 0 {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+
+		return strings.TrimSpace(token)
+	}
+
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return ""
+	}
+
+	return r.URL.Query().Get("access_token")
+}
@@ -0,0 +1,311 @@
+package apiauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+func TestAPIAuth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test api auth")
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signedToken(alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, _ := ecdsa.Sign(rand.Reader, k, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + b64(signature)
+}
+
+func writeJWKS(rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-key",
+				"use": "sig",
+				"n":   b64(rsaKey.N.Bytes()),
+				"e":   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec-key",
+				"crv": "P-256",
+				"x":   b64(ecKey.X.FillBytes(make([]byte, 32))),
+				"y":   b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	}
+
+	data, _ := json.Marshal(jwks)
+	path := filepath.Join(GinkgoT().TempDir(), "jwks.json")
+	Expect(os.WriteFile(path, data, 0o600)).To(Succeed())
+
+	return path
+}
+
+var _ = Describe("API auth test", func() {
+	var rsaKey *rsa.PrivateKey
+	var ecKey *ecdsa.PrivateKey
+	var jwksPath string
+	var claims map[string]interface{}
+
+	BeforeEach(func() {
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+
+		jwksPath = writeJWKS(rsaKey, ecKey)
+
+		claims = map[string]interface{}{
+			"iss":    "https://issuer.example.com",
+			"aud":    []string{"cyclops"},
+			"sub":    "1234",
+			"email":  "jane@example.com",
+			"groups": []string{"developers"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		}
+	})
+
+	Describe("OIDC authenticator", func() {
+		var authenticator *OIDCAuthenticator
+
+		BeforeEach(func() {
+			var err error
+			authenticator, err = NewOIDCAuthenticator(OIDCConfig{
+				JWKSPath:      jwksPath,
+				Issuer:        "https://issuer.example.com",
+				Audience:      "cyclops",
+				UsernameClaim: "email",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("authenticates RSA signed tokens", func() {
+			user, err := authenticator.AuthenticateToken(signedToken("RS256", "rsa-key", rsaKey, claims))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.Name).To(BeEquivalentTo("jane@example.com"))
+			Expect(user.Groups).To(BeEquivalentTo([]string{"developers"}))
+		})
+
+		It("authenticates EC signed tokens", func() {
+			user, err := authenticator.AuthenticateToken(signedToken("ES256", "ec-key", ecKey, claims))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.Name).To(BeEquivalentTo("jane@example.com"))
+		})
+
+		It("rejects tokens signed with other keys", func() {
+			otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = authenticator.AuthenticateToken(signedToken("RS256", "rsa-key", otherKey, claims))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects expired tokens", func() {
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+			_, err := authenticator.AuthenticateToken(signedToken("RS256", "rsa-key", rsaKey, claims))
+			Expect(err).To(MatchError(ContainSubstring("expired")))
+		})
+
+		It("rejects tokens for other audiences", func() {
+			claims["aud"] = "other"
+
+			_, err := authenticator.AuthenticateToken(signedToken("RS256", "rsa-key", rsaKey, claims))
+			Expect(err).To(MatchError(ContainSubstring("audience")))
+		})
+
+		It("requires an audience", func() {
+			_, err := NewOIDCAuthenticator(OIDCConfig{JWKSPath: jwksPath})
+			Expect(err).To(MatchError(ContainSubstring("audience")))
+		})
+
+		It("reads rotated keys at most once per reload interval", func() {
+			rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.Rename(writeJWKS(rotated, ecKey), jwksPath)).To(Succeed())
+
+			now := time.Now()
+			authenticator.keySet.now = func() time.Time { return now }
+
+			_, err = authenticator.AuthenticateToken(signedToken("RS256", "rsa-key", rotated, claims))
+			Expect(err).To(HaveOccurred())
+
+			now = now.Add(jwksReloadInterval)
+
+			user, err := authenticator.AuthenticateToken(signedToken("RS256", "rsa-key", rotated, claims))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.Name).To(BeEquivalentTo("jane@example.com"))
+		})
+
+		It("rejects unsigned tokens", func() {
+			header, _ := json.Marshal(map[string]string{"alg": "none"})
+			payload, _ := json.Marshal(claims)
+
+			_, err := authenticator.AuthenticateToken(b64(header) + "." + b64(payload) + ".")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Middleware", func() {
+		var k8sClient *mocks.IKubernetesClient
+		var r *gin.Engine
+		var w *httptest.ResponseRecorder
+
+		setup := func(config Config) {
+			a, err := New(config, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			r = gin.New()
+			r.GET("/modules/:name", a.Middleware(), func(ctx *gin.Context) {
+				user := UserFrom(ctx)
+				if user == nil {
+					ctx.String(http.StatusOK, "")
+					return
+				}
+				ctx.String(http.StatusOK, user.Name)
+			})
+		}
+
+		BeforeEach(func() {
+			gin.SetMode(gin.TestMode)
+			k8sClient = &mocks.IKubernetesClient{}
+			w = httptest.NewRecorder()
+		})
+
+		It("allows all requests if authentication is disabled", func() {
+			setup(Config{})
+
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo", nil)
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+		})
+
+		It("rejects requests without a token", func() {
+			setup(Config{Authenticators: []string{AuthenticatorTokenReview}})
+
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo", nil)
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusUnauthorized))
+		})
+
+		It("authenticates tokens with token review", func() {
+			k8sClient.On("TokenReview", "sa-token").Return(&authenticationv1.UserInfo{
+				Username: "system:serviceaccount:cyclops:ci",
+			}, true, nil)
+
+			setup(Config{Authenticators: []string{AuthenticatorOIDC, AuthenticatorTokenReview}, OIDC: OIDCConfig{JWKSPath: jwksPath, Audience: "cyclops"}})
+
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo", nil)
+			req.Header.Set("Authorization", "Bearer sa-token")
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+			Expect(w.Body.String()).To(BeEquivalentTo("system:serviceaccount:cyclops:ci"))
+		})
+
+		It("accepts tokens in the query only on websocket requests", func() {
+			setup(Config{
+				Authenticators: []string{AuthenticatorOIDC},
+				OIDC:           OIDCConfig{JWKSPath: jwksPath, Audience: "cyclops", UsernameClaim: "email"},
+			})
+			path := "/modules/demo?access_token=" + signedToken("RS256", "rsa-key", rsaKey, claims)
+
+			req, _ := http.NewRequest(http.MethodGet, path, nil)
+			r.ServeHTTP(w, req)
+			Expect(w.Code).To(BeEquivalentTo(http.StatusUnauthorized))
+
+			w = httptest.NewRecorder()
+			req, _ = http.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			r.ServeHTTP(w, req)
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+			Expect(w.Body.String()).To(BeEquivalentTo("jane@example.com"))
+		})
+
+		It("checks access with subject access reviews", func() {
+			k8sClient.On("SubjectAccessReview", "jane@example.com", []string{"developers"}, "/cyclops/modules/demo", "get").
+				Return(false, "no access", nil)
+
+			setup(Config{
+				Authenticators: []string{AuthenticatorOIDC},
+				Authorization:  []string{AuthorizationSubjectAccessReview},
+				OIDC:           OIDCConfig{JWKSPath: jwksPath, Audience: "cyclops", UsernameClaim: "email"},
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo", nil)
+			req.Header.Set("Authorization", "Bearer "+signedToken("RS256", "rsa-key", rsaKey, claims))
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusForbidden))
+			k8sClient.AssertCalled(GinkgoT(), "SubjectAccessReview", "jane@example.com", []string{"developers"}, "/cyclops/modules/demo", "get")
+		})
+
+		It("impersonates the request user", func() {
+			impersonated := &mocks.IKubernetesClient{}
+			k8sClient.On("Impersonate", "jane@example.com", mock.Anything).Return(impersonated, nil)
+
+			a, err := New(Config{
+				Authenticators: []string{AuthenticatorOIDC},
+				Authorization:  []string{AuthorizationImpersonate},
+				OIDC:           OIDCConfig{JWKSPath: jwksPath, Audience: "cyclops", UsernameClaim: "email"},
+			}, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			r = gin.New()
+			r.GET("/modules/:name", a.Middleware(), func(ctx *gin.Context) {
+				Expect(KubernetesClient(ctx, k8sClient)).To(BeIdenticalTo(impersonated))
+				ctx.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo", nil)
+			req.Header.Set("Authorization", "Bearer "+signedToken("ES256", "ec-key", ecKey, claims))
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+		})
+	})
+})
@@ -0,0 +1,250 @@
+package apiauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	jose "github.com/go-jose/go-jose/v4"
+	json "github.com/json-iterator/go"
+)
+
+// jwksReloadInterval is the minimum time between reads of the JSON Web Key Set
+// file, so tokens signed with unknown keys can't make every request read it
+var jwksReloadInterval = time.Minute
+
+var signingAlgorithms = []string{
+	oidc.RS256, oidc.RS384, oidc.RS512,
+	oidc.PS256, oidc.PS384, oidc.PS512,
+	oidc.ES256, oidc.ES384, oidc.ES512,
+}
+
+type OIDCConfig struct {
+	// JWKSPath is the path of the JSON Web Key Set file with the issuer
+	// signing keys
+	JWKSPath string
+	Issuer   string
+	// Audience is the client ID tokens have to be issued for
+	Audience string
+
+	// UsernameClaim defaults to sub
+	UsernameClaim string
+	// GroupsClaim defaults to groups
+	GroupsClaim string
+}
+
+// OIDCAuthenticator authenticates OIDC ID tokens signed with keys from a local
+// JSON Web Key Set
+type OIDCAuthenticator struct {
+	config   OIDCConfig
+	keySet   *fileKeySet
+	verifier *oidc.IDTokenVerifier
+}
+
+func NewOIDCAuthenticator(config OIDCConfig) (*OIDCAuthenticator, error) {
+	if len(config.JWKSPath) == 0 {
+		return nil, errors.New("oidc: JWKS path not set")
+	}
+
+	if len(config.Audience) == 0 {
+		return nil, errors.New("oidc: audience not set")
+	}
+
+	if len(config.UsernameClaim) == 0 {
+		config.UsernameClaim = "sub"
+	}
+
+	if len(config.GroupsClaim) == 0 {
+		config.GroupsClaim = "groups"
+	}
+
+	keySet, err := newFileKeySet(config.JWKSPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCAuthenticator{
+		config: config,
+		keySet: keySet,
+		verifier: oidc.NewVerifier(config.Issuer, keySet, &oidc.Config{
+			ClientID:             config.Audience,
+			SupportedSigningAlgs: signingAlgorithms,
+			SkipIssuerCheck:      len(config.Issuer) == 0,
+		}),
+	}, nil
+}
+
+func (o *OIDCAuthenticator) AuthenticateToken(token string) (*User, error) {
+	idToken, err := o.verifier.Verify(context.Background(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := make(map[string]interface{})
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("oidc: malformed token claims: %w", err)
+	}
+
+	username, ok := claims[o.config.UsernameClaim].(string)
+	if !ok || len(username) == 0 {
+		return nil, fmt.Errorf("oidc: claim %v not set", o.config.UsernameClaim)
+	}
+
+	return &User{
+		Name:   username,
+		Groups: stringsClaim(claims[o.config.GroupsClaim]),
+	}, nil
+}
+
+// fileKeySet verifies token signatures with keys from a JSON Web Key Set file.
+// The file is read again if a token is signed with an unknown key, at most once
+// per jwksReloadInterval, so rotated keys are picked up without a restart.
+type fileKeySet struct {
+	path string
+	now  func() time.Time
+
+	lock     sync.Mutex
+	keys     []jose.JSONWebKey
+	loadedAt time.Time
+}
+
+func newFileKeySet(path string) (*fileKeySet, error) {
+	s := &fileKeySet{
+		path: path,
+		now:  time.Now,
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *fileKeySet) VerifySignature(_ context.Context, token string) ([]byte, error) {
+	algorithms := make([]jose.SignatureAlgorithm, 0, len(signingAlgorithms))
+	for _, alg := range signingAlgorithms {
+		algorithms = append(algorithms, jose.SignatureAlgorithm(alg))
+	}
+
+	jws, err := jose.ParseSigned(token, algorithms)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: malformed token: %w", err)
+	}
+
+	if len(jws.Signatures) != 1 {
+		return nil, errors.New("oidc: token must have exactly one signature")
+	}
+	keyID := jws.Signatures[0].Header.KeyID
+
+	if payload, ok := verifyWithKeys(jws, s.signingKeys(keyID)); ok {
+		return payload, nil
+	}
+
+	keys, err := s.reload(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, ok := verifyWithKeys(jws, keys); ok {
+		return payload, nil
+	}
+
+	return nil, errors.New("oidc: invalid token signature")
+}
+
+func (s *fileKeySet) signingKeys(keyID string) []jose.JSONWebKey {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return matchingKeys(s.keys, keyID)
+}
+
+// reload reads the key set again and returns the keys matching the key ID. It
+// returns no keys if the key set was read less than jwksReloadInterval ago.
+func (s *fileKeySet) reload(keyID string) ([]jose.JSONWebKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.now().Sub(s.loadedAt) < jwksReloadInterval {
+		return nil, nil
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return matchingKeys(s.keys, keyID), nil
+}
+
+func (s *fileKeySet) load() error {
+	s.loadedAt = s.now()
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("oidc: reading JWKS: %w", err)
+	}
+
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return fmt.Errorf("oidc: parsing JWKS: %w", err)
+	}
+
+	keys := make([]jose.JSONWebKey, 0, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		if len(key.Use) != 0 && key.Use != "sig" {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	s.keys = keys
+	return nil
+}
+
+func matchingKeys(keys []jose.JSONWebKey, keyID string) []jose.JSONWebKey {
+	if len(keyID) == 0 {
+		return keys
+	}
+
+	matching := make([]jose.JSONWebKey, 0, 1)
+	for _, key := range keys {
+		if key.KeyID == keyID {
+			matching = append(matching, key)
+		}
+	}
+
+	return matching
+}
+
+func verifyWithKeys(jws *jose.JSONWebSignature, keys []jose.JSONWebKey) ([]byte, bool) {
+	for _, key := range keys {
+		if payload, err := jws.Verify(&key); err == nil {
+			return payload, true
+		}
+	}
+
+	return nil, false
+}
+
+func stringsClaim(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, value := range v {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
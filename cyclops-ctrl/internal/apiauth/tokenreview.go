@@ -0,0 +1,35 @@
+package apiauth
+
+import (
+	"errors"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+// TokenReviewAuthenticator authenticates Kubernetes bearer tokens, like
+// ServiceAccount tokens, against the API server
+type TokenReviewAuthenticator struct {
+	kubernetesClient k8sclient.IKubernetesClient
+}
+
+func NewTokenReviewAuthenticator(kubernetesClient k8sclient.IKubernetesClient) *TokenReviewAuthenticator {
+	return &TokenReviewAuthenticator{
+		kubernetesClient: kubernetesClient,
+	}
+}
+
+func (t *TokenReviewAuthenticator) AuthenticateToken(token string) (*User, error) {
+	userInfo, authenticated, err := t.kubernetesClient.TokenReview(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !authenticated {
+		return nil, errors.New("token review: token not authenticated")
+	}
+
+	return &User{
+		Name:   userInfo.Username,
+		Groups: userInfo.Groups,
+	}, nil
+}
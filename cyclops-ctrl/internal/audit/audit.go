@@ -0,0 +1,224 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+)
+
+const (
+	// DefaultRetainedEntries is the number of recent entries kept in memory for queries
+	DefaultRetainedEntries = 1000
+
+	entryKey = "cyclops-audit-entry"
+
+	// sinkQueueSize is the number of entries buffered for sinks. Entries are
+	// dropped if sinks fall behind.
+	sinkQueueSize = 256
+
+	// errorBodyLimit is the number of response body bytes read for the error of
+	// failed requests
+	errorBodyLimit = 4096
+)
+
+// Log records mutating API requests. Entries are written to the sinks and the
+// most recent ones are kept in memory.
+type Log struct {
+	sinks []Sink
+	queue chan dto.AuditEntry
+
+	entries     []dto.AuditEntry
+	next        int
+	entriesLock sync.RWMutex
+
+	logger logr.Logger
+}
+
+func New(retainedEntries int, logger logr.Logger, sinks ...Sink) *Log {
+	if retainedEntries < 1 {
+		retainedEntries = DefaultRetainedEntries
+	}
+
+	l := &Log{
+		sinks:   sinks,
+		queue:   make(chan dto.AuditEntry, sinkQueueSize),
+		entries: make([]dto.AuditEntry, 0, retainedEntries),
+		logger:  logger,
+	}
+
+	go l.writeSinks()
+
+	return l
+}
+
+// Middleware audits requests for which audited returns true. Handlers add the
+// affected Module, resource and changes to the entry with SetModule,
+// SetResource and SetDiff.
+func (l *Log) Middleware(audited func(ctx *gin.Context) bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !audited(ctx) {
+			ctx.Next()
+			return
+		}
+
+		start := time.Now()
+
+		entry := &dto.AuditEntry{
+			Timestamp: start,
+			ClientIP:  ctx.ClientIP(),
+			Method:    ctx.Request.Method,
+			Route:     ctx.FullPath(),
+			Path:      ctx.Request.URL.Path,
+		}
+		ctx.Set(entryKey, entry)
+
+		writer := &responseWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		if user := apiauth.UserFrom(ctx); user != nil {
+			entry.Actor = user.Name
+			entry.Groups = user.Groups
+		}
+
+		entry.Status = writer.Status()
+		entry.LatencyMs = time.Since(start).Milliseconds()
+		entry.Outcome = dto.AuditSuccess
+		if entry.Status >= http.StatusBadRequest {
+			entry.Outcome = dto.AuditFailure
+			entry.Error = errorMessage(writer.body.Bytes())
+		}
+
+		l.record(*entry)
+	}
+}
+
+// ModuleEntries returns up to limit most recent entries of the Module, newest first
+func (l *Log) ModuleEntries(module string, limit int) []dto.AuditEntry {
+	l.entriesLock.RLock()
+	defer l.entriesLock.RUnlock()
+
+	entries := make([]dto.AuditEntry, 0)
+	for i := 1; i <= len(l.entries); i++ {
+		entry := l.entries[(l.next-i+len(l.entries))%len(l.entries)]
+		if entry.Module != module {
+			continue
+		}
+
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+	}
+
+	return entries
+}
+
+func (l *Log) record(entry dto.AuditEntry) {
+	l.entriesLock.Lock()
+	if len(l.entries) < cap(l.entries) {
+		l.entries = append(l.entries, entry)
+	} else {
+		l.entries[l.next] = entry
+	}
+	l.next = (l.next + 1) % cap(l.entries)
+	l.entriesLock.Unlock()
+
+	if len(l.sinks) == 0 {
+		return
+	}
+
+	select {
+	case l.queue <- entry:
+	default:
+		l.logger.Info("audit sinks queue full, dropping entry", "route", entry.Route, "module", entry.Module)
+	}
+}
+
+func (l *Log) writeSinks() {
+	for entry := range l.queue {
+		for _, sink := range l.sinks {
+			if err := sink.Write(entry); err != nil {
+				l.logger.Error(err, "failed to write audit entry", "sink", sink.Name())
+			}
+		}
+	}
+}
+
+// SetModule sets the Module affected by the audited request
+func SetModule(ctx *gin.Context, module string) {
+	if entry := entryFrom(ctx); entry != nil {
+		entry.Module = module
+	}
+}
+
+// SetResource sets the resource affected by the audited request
+func SetResource(ctx *gin.Context, resource dto.AuditResource) {
+	if entry := entryFrom(ctx); entry != nil {
+		entry.Resource = &resource
+	}
+}
+
+// SetDiff sets the changes made by the audited request
+func SetDiff(ctx *gin.Context, diff []dto.JSONPatch) {
+	if entry := entryFrom(ctx); entry != nil {
+		entry.Diff = diff
+	}
+}
+
+func entryFrom(ctx *gin.Context) *dto.AuditEntry {
+	entry, ok := ctx.Get(entryKey)
+	if !ok {
+		return nil
+	}
+
+	return entry.(*dto.AuditEntry)
+}
+
+func errorMessage(body []byte) string {
+	var apiErr dto.Error
+	if err := json.Unmarshal(body, &apiErr); err == nil && len(apiErr.Message) != 0 {
+		if len(apiErr.Description) == 0 {
+			return apiErr.Message
+		}
+		return apiErr.Message + ": " + apiErr.Description
+	}
+
+	return string(body)
+}
+
+// responseWriter keeps the start of the response body of failed requests
+type responseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseWriter) capture(data []byte) {
+	if w.Status() < http.StatusBadRequest || w.body.Len() >= errorBodyLimit {
+		return
+	}
+
+	if remaining := errorBodyLimit - w.body.Len(); len(data) > remaining {
+		data = data[:remaining]
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+func TestAudit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test audit")
+}
+
+type channelSink struct {
+	entries chan dto.AuditEntry
+}
+
+func (s *channelSink) Name() string {
+	return "channel"
+}
+
+func (s *channelSink) Write(entry dto.AuditEntry) error {
+	s.entries <- entry
+	return nil
+}
+
+var _ = Describe("Audit log test", func() {
+	var sink *channelSink
+	var log *Log
+	var r *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+
+		sink = &channelSink{entries: make(chan dto.AuditEntry, 10)}
+		log = New(3, logr.Discard(), sink)
+
+		r = gin.New()
+		r.Use(log.Middleware(func(ctx *gin.Context) bool {
+			return ctx.Request.Method != http.MethodGet
+		}))
+		r.GET("/modules/:name", func(ctx *gin.Context) {
+			SetModule(ctx, ctx.Param("name"))
+			ctx.Status(http.StatusOK)
+		})
+		r.POST("/modules/:name/update", func(ctx *gin.Context) {
+			SetModule(ctx, ctx.Param("name"))
+			SetDiff(ctx, []dto.JSONPatch{{Op: "replace", Path: "/values/replicas", Value: 3}})
+			ctx.Status(http.StatusOK)
+		})
+		r.DELETE("/modules/:name", func(ctx *gin.Context) {
+			SetModule(ctx, ctx.Param("name"))
+			ctx.JSON(http.StatusBadRequest, dto.NewError("Error deleting module", "not found"))
+		})
+	})
+
+	serve := func(method, path string) {
+		req, _ := http.NewRequest(method, path, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	It("records mutating requests", func() {
+		serve(http.MethodGet, "/modules/demo")
+		serve(http.MethodPost, "/modules/demo/update")
+
+		var entry dto.AuditEntry
+		Eventually(sink.entries).Should(Receive(&entry))
+		Expect(entry.Method).To(BeEquivalentTo(http.MethodPost))
+		Expect(entry.Route).To(BeEquivalentTo("/modules/:name/update"))
+		Expect(entry.Module).To(BeEquivalentTo("demo"))
+		Expect(entry.Diff).To(HaveLen(1))
+		Expect(entry.Status).To(BeEquivalentTo(http.StatusOK))
+		Expect(entry.Outcome).To(BeEquivalentTo(dto.AuditSuccess))
+
+		Expect(log.ModuleEntries("demo", 0)).To(HaveLen(1))
+	})
+
+	It("records errors of failed requests", func() {
+		serve(http.MethodDelete, "/modules/demo")
+
+		entries := log.ModuleEntries("demo", 0)
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Outcome).To(BeEquivalentTo(dto.AuditFailure))
+		Expect(entries[0].Error).To(BeEquivalentTo("Error deleting module: not found"))
+	})
+
+	It("returns newest Module entries first and keeps only the most recent ones", func() {
+		serve(http.MethodPost, "/modules/demo/update")
+		serve(http.MethodPost, "/modules/other/update")
+		serve(http.MethodDelete, "/modules/demo")
+		serve(http.MethodPost, "/modules/demo/update")
+
+		entries := log.ModuleEntries("demo", 0)
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Method).To(BeEquivalentTo(http.MethodPost))
+		Expect(entries[1].Method).To(BeEquivalentTo(http.MethodDelete))
+
+		Expect(log.ModuleEntries("demo", 1)).To(HaveLen(1))
+		Expect(log.ModuleEntries("other", 0)).To(HaveLen(1))
+	})
+
+	It("creates events only for Module entries", func() {
+		k8sClient := &mocks.IKubernetesClient{}
+		k8sClient.On("CreateModuleEvent", "demo", "Warning", eventReason, "jane POST /modules/demo/update: 500 (12ms): failed").
+			Return(nil)
+
+		eventSink := NewEventSink(k8sClient)
+
+		Expect(eventSink.Write(dto.AuditEntry{Method: http.MethodPost, Path: "/templates/store"})).To(Succeed())
+		Expect(eventSink.Write(dto.AuditEntry{
+			Actor:     "jane",
+			Method:    http.MethodPost,
+			Path:      "/modules/demo/update",
+			Module:    "demo",
+			Status:    http.StatusInternalServerError,
+			Outcome:   dto.AuditFailure,
+			Error:     "failed",
+			LatencyMs: 12,
+		})).To(Succeed())
+
+		k8sClient.AssertNumberOfCalls(GinkgoT(), "CreateModuleEvent", 1)
+	})
+})
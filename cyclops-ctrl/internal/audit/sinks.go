@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+const (
+	SinkFile   = "file"
+	SinkStdout = "stdout"
+	SinkEvents = "events"
+
+	eventReason = "APIRequest"
+)
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=create
+
+// Sink persists audit entries
+type Sink interface {
+	Name() string
+	Write(entry dto.AuditEntry) error
+}
+
+// WriterSink writes entries as JSON lines
+type WriterSink struct {
+	name string
+
+	writer io.Writer
+	lock   sync.Mutex
+}
+
+// NewSinks creates the sinks with the given names. filePath is the path of the
+// file sink.
+func NewSinks(names []string, filePath string, kubernetesClient k8sclient.IKubernetesClient) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case SinkFile:
+			if len(filePath) == 0 {
+				return nil, errors.New("audit file sink requires a file path")
+			}
+
+			sink, err := NewFileSink(filePath)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case SinkStdout:
+			sinks = append(sinks, NewStdoutSink())
+		case SinkEvents:
+			sinks = append(sinks, NewEventSink(kubernetesClient))
+		default:
+			return nil, fmt.Errorf("unknown audit sink %v", name)
+		}
+	}
+
+	return sinks, nil
+}
+
+func NewStdoutSink() *WriterSink {
+	return &WriterSink{
+		name:   SinkStdout,
+		writer: os.Stdout,
+	}
+}
+
+// NewFileSink appends entries to the file at path, creating it if needed
+func NewFileSink(path string) (*WriterSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WriterSink{
+		name:   SinkFile,
+		writer: file,
+	}, nil
+}
+
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+func (s *WriterSink) Write(entry dto.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err = s.writer.Write(append(line, '\n'))
+	return err
+}
+
+// EventSink records entries as Kubernetes Events on the affected Module.
+// Entries without a Module are skipped.
+type EventSink struct {
+	kubernetesClient k8sclient.IKubernetesClient
+}
+
+func NewEventSink(kubernetesClient k8sclient.IKubernetesClient) *EventSink {
+	return &EventSink{
+		kubernetesClient: kubernetesClient,
+	}
+}
+
+func (s *EventSink) Name() string {
+	return SinkEvents
+}
+
+func (s *EventSink) Write(entry dto.AuditEntry) error {
+	if len(entry.Module) == 0 {
+		return nil
+	}
+
+	eventType := k8sclient.EventTypeNormal
+	if entry.Outcome == dto.AuditFailure {
+		eventType = k8sclient.EventTypeWarning
+	}
+
+	return s.kubernetesClient.CreateModuleEvent(entry.Module, eventType, eventReason, eventMessage(entry))
+}
+
+func eventMessage(entry dto.AuditEntry) string {
+	actor := entry.Actor
+	if len(actor) == 0 {
+		actor = "anonymous"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v %v %v: %v (%vms)", actor, entry.Method, entry.Path, entry.Status, entry.LatencyMs)
+
+	if entry.Resource != nil {
+		fmt.Fprintf(&sb, " %v %v/%v", entry.Resource.Kind, entry.Resource.Namespace, entry.Resource.Name)
+	}
+
+	if len(entry.Error) != 0 {
+		fmt.Fprintf(&sb, ": %v", entry.Error)
+	}
+
+	return sb.String()
+}
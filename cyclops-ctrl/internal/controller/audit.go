@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/audit"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+)
+
+const defaultAuditEntriesLimit = 50
+
+type Audit struct {
+	auditLog *audit.Log
+}
+
+func NewAuditController(auditLog *audit.Log) *Audit {
+	return &Audit{
+		auditLog: auditLog,
+	}
+}
+
+func (a *Audit) ModuleAuditLog(ctx *gin.Context) {
+	limit := defaultAuditEntriesLimit
+	if len(ctx.Query("limit")) != 0 {
+		var err error
+		limit, err = strconv.Atoi(ctx.Query("limit"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, dto.NewError("Invalid limit", err.Error()))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, a.auditLog.ModuleEntries(ctx.Param("name"), limit))
+}
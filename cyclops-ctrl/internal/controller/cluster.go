@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"k8s.io/apimachinery/pkg/api/errors"
 
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/mapper"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
@@ -22,9 +23,9 @@ func NewClusterController(kubernetes k8sclient.IKubernetesClient) *Cluster {
 }
 
 func (c *Cluster) ListNodes(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, c.kubernetesClient)
 
-	nodes, err := c.kubernetesClient.ListNodes()
+	nodes, err := kubernetesClient.ListNodes()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching nodes", err.Error()))
 		return
@@ -34,11 +35,11 @@ func (c *Cluster) ListNodes(ctx *gin.Context) {
 }
 
 func (c *Cluster) GetNode(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, c.kubernetesClient)
 
 	nodeName := ctx.Param("name")
 
-	node, err := c.kubernetesClient.GetNode(nodeName)
+	node, err := kubernetesClient.GetNode(nodeName)
 	if errors.IsNotFound(err) {
 		ctx.JSON(http.StatusBadRequest, dto.Error{
 			Message:     "Node with name does not exist",
@@ -51,7 +52,7 @@ func (c *Cluster) GetNode(ctx *gin.Context) {
 		return
 	}
 
-	pods, err := c.kubernetesClient.GetPodsForNode(nodeName)
+	pods, err := kubernetesClient.GetPodsForNode(nodeName)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching pod nodes", err.Error()))
 		return
@@ -63,9 +64,9 @@ func (c *Cluster) GetNode(ctx *gin.Context) {
 }
 
 func (c *Cluster) ListNamespaces(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, c.kubernetesClient)
 
-	namespaces, err := c.kubernetesClient.ListNamespaces()
+	namespaces, err := kubernetesClient.ListNamespaces()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching namespaces", err.Error()))
 		return
@@ -5,6 +5,10 @@ import (
 	"net/http"
 	"sort"
 
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/audit"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/integrations/helm"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/mapper"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
@@ -21,24 +25,25 @@ import (
 type Helm struct {
 	kubernetesClient k8sclient.IKubernetesClient
 	releaseClient    *helm.ReleaseClient
+	gitWriteClient   *git.WriteClient
 	telemetryClient  telemetry.Client
 }
 
 func NewHelmController(
 	kubernetes k8sclient.IKubernetesClient,
 	releaseClient *helm.ReleaseClient,
+	gitWriteClient *git.WriteClient,
 	telemetryClient telemetry.Client,
 ) *Helm {
 	return &Helm{
 		kubernetesClient: kubernetes,
 		releaseClient:    releaseClient,
+		gitWriteClient:   gitWriteClient,
 		telemetryClient:  telemetryClient,
 	}
 }
 
 func (h *Helm) ListReleases(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	releases, err := h.releaseClient.ListReleases()
 	if err != nil {
 		fmt.Println(err)
@@ -56,8 +61,6 @@ func (h *Helm) ListReleases(ctx *gin.Context) {
 }
 
 func (h *Helm) GetRelease(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	name := ctx.Param("name")
 	namespace := ctx.Param("namespace")
 
@@ -73,10 +76,9 @@ func (h *Helm) GetRelease(ctx *gin.Context) {
 }
 
 func (h *Helm) UpgradeRelease(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	name := ctx.Param("name")
 	namespace := ctx.Param("namespace")
+	audit.SetResource(ctx, helmReleaseAuditResource(namespace, name))
 
 	var values map[string]interface{}
 	if err := ctx.BindJSON(&values); err != nil {
@@ -101,10 +103,9 @@ func (h *Helm) UpgradeRelease(ctx *gin.Context) {
 }
 
 func (h *Helm) UninstallRelease(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	name := ctx.Param("name")
 	namespace := ctx.Param("namespace")
+	audit.SetResource(ctx, helmReleaseAuditResource(namespace, name))
 
 	if err := h.releaseClient.UninstallRelease(namespace, name); err != nil {
 		fmt.Println(err)
@@ -116,8 +117,6 @@ func (h *Helm) UninstallRelease(ctx *gin.Context) {
 }
 
 func (h *Helm) GetReleaseResources(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	namespace := ctx.Param("namespace")
 	name := ctx.Param("name")
 
@@ -132,8 +131,6 @@ func (h *Helm) GetReleaseResources(ctx *gin.Context) {
 }
 
 func (h *Helm) GetReleaseSchema(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	name := ctx.Param("name")
 	namespace := ctx.Param("namespace")
 
@@ -171,8 +168,6 @@ func (h *Helm) GetReleaseSchema(ctx *gin.Context) {
 }
 
 func (h *Helm) GetReleaseValues(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	name := ctx.Param("name")
 	namespace := ctx.Param("namespace")
 
@@ -197,7 +192,7 @@ func (h *Helm) GetReleaseValues(ctx *gin.Context) {
 }
 
 func (h *Helm) MigrateHelmRelease(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, h.kubernetesClient)
 
 	var req dto.Module
 	if err := ctx.BindJSON(&req); err != nil {
@@ -217,18 +212,48 @@ func (h *Helm) MigrateHelmRelease(ctx *gin.Context) {
 
 	h.telemetryClient.ReleaseMigration()
 
-	err = h.kubernetesClient.CreateModule(module)
-	if err != nil {
+	setAuthor(ctx, &module)
+	auditModuleChange(ctx, module.Name, cyclopsv1alpha1.ModuleSpec{}, module.Spec)
+
+	// the release is handed over to the Module once it is synced from git
+	gitOpsWrite := h.gitWriteClient.Destination(module) != nil
+
+	var pullRequestURL string
+	if gitOpsWrite {
+		pullRequestURL, err = h.gitWriteClient.Write(module)
+		if err != nil {
+			fmt.Println(err)
+			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error pushing to git", err.Error()))
+			return
+		}
+	} else {
+		err = kubernetesClient.CreateModule(module)
+		if err != nil {
+			fmt.Println(err)
+			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error creating module", err.Error()))
+			return
+		}
+	}
+
+	if err := kubernetesClient.DeleteReleaseSecret(req.Name, req.Namespace); err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error creating module", err.Error()))
 		return
 	}
 
-	if err := h.kubernetesClient.DeleteReleaseSecret(req.Name, req.Namespace); err != nil {
-		fmt.Println(err)
-		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error creating module", err.Error()))
+	if gitOpsWrite {
+		ctx.JSON(http.StatusCreated, dto.GitOpsWriteResponse{PullRequestURL: pullRequestURL})
 		return
 	}
 
 	ctx.Status(http.StatusCreated)
 }
+
+// helmReleaseAuditResource identifies a Helm release in audit entries
+func helmReleaseAuditResource(namespace, name string) dto.AuditResource {
+	return dto.AuditResource{
+		Kind:      "HelmRelease",
+		Name:      name,
+		Namespace: namespace,
+	}
+}
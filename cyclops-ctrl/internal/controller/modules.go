@@ -1,6 +1,7 @@
 package controller
 
 import (
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,18 +13,27 @@ import (
 	json "github.com/json-iterator/go"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/render"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/values"
 
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/drift"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/history"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/immutable"
 
 	"sigs.k8s.io/yaml"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/audit"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/mapper"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/prometheus"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/telemetry"
@@ -36,6 +46,7 @@ type Modules struct {
 	templatesRepo    template.ITemplateRepo
 	renderer         *render.Renderer
 	gitWriteClient   *git.WriteClient
+	driftDetector    *drift.Detector
 
 	moduleTargetNamespace string
 
@@ -48,6 +59,7 @@ func NewModulesController(
 	kubernetes k8sclient.IKubernetesClient,
 	renderer *render.Renderer,
 	gitWriteClient *git.WriteClient,
+	driftDetector *drift.Detector,
 	moduleTargetNamespace string,
 	telemetryClient telemetry.Client,
 	monitor prometheus.Monitor,
@@ -57,6 +69,7 @@ func NewModulesController(
 		templatesRepo:         templatesRepo,
 		renderer:              renderer,
 		gitWriteClient:        gitWriteClient,
+		driftDetector:         driftDetector,
 		moduleTargetNamespace: moduleTargetNamespace,
 		telemetryClient:       telemetryClient,
 		monitor:               monitor,
@@ -64,9 +77,9 @@ func NewModulesController(
 }
 
 func (m *Modules) GetModule(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -84,9 +97,9 @@ func (m *Modules) GetModule(ctx *gin.Context) {
 }
 
 func (m *Modules) GetRawModuleManifest(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.Status(http.StatusInternalServerError)
@@ -110,9 +123,9 @@ func (m *Modules) GetRawModuleManifest(ctx *gin.Context) {
 }
 
 func (m *Modules) ListModules(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
-	modules, err := m.kubernetesClient.ListModules()
+	modules, err := kubernetesClient.ListModules()
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching modules", err.Error()))
@@ -122,7 +135,7 @@ func (m *Modules) ListModules(ctx *gin.Context) {
 	dtoModules := mapper.ModuleListToDTO(modules)
 
 	for i, dtoModule := range dtoModules {
-		dtoModuleStatus, err := m.kubernetesClient.GetModuleResourcesHealth(dtoModule.Name)
+		dtoModuleStatus, err := kubernetesClient.GetModuleResourcesHealth(dtoModule.Name)
 		if err != nil {
 			fmt.Println(err)
 			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching modules", err.Error()))
@@ -135,14 +148,38 @@ func (m *Modules) ListModules(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dtoModules)
 }
 
+func (m *Modules) DependencyGraph(ctx *gin.Context) {
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
+
+	modules, err := kubernetesClient.ListModules()
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching modules", err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapper.ModuleDependencyGraphToDTO(modules))
+}
+
 func (m *Modules) DeleteModule(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
+
 	m.monitor.DecModule()
+	audit.SetModule(ctx, ctx.Param("name"))
 
 	deleteMethod := ctx.Query("deleteMethod")
 
+	// without an explicit method, only Modules with their own git destination
+	// are deleted from git
+	if len(deleteMethod) == 0 {
+		module, err := kubernetesClient.GetModule(ctx.Param("name"))
+		if err == nil && module != nil && len(module.GetAnnotations()[v1alpha1.GitOpsWriteRepoAnnotation]) != 0 {
+			deleteMethod = "git"
+		}
+	}
+
 	if deleteMethod == "git" {
-		module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+		module, err := kubernetesClient.GetModule(ctx.Param("name"))
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, dto.NewError("Error fetching module for deletion", err.Error()))
 			return
@@ -153,17 +190,17 @@ func (m *Modules) DeleteModule(ctx *gin.Context) {
 			return
 		}
 
-		err = m.gitWriteClient.DeleteModule(*module)
+		pullRequestURL, err := m.gitWriteClient.DeleteModule(*module)
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, dto.NewError("Error deleting module from git", err.Error()))
 			return
 		}
 
-		ctx.Status(http.StatusOK)
+		ctx.JSON(http.StatusOK, dto.GitOpsWriteResponse{PullRequestURL: pullRequestURL})
 		return
 	}
 
-	err := m.kubernetesClient.DeleteModule(ctx.Param("name"))
+	err := kubernetesClient.DeleteModule(ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error deleting module", err.Error()))
@@ -173,22 +210,58 @@ func (m *Modules) DeleteModule(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// Drift compares the Module in the cluster with the Module committed to its
+// git destination
+func (m *Modules) Drift(ctx *gin.Context) {
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
+
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
+		return
+	}
+
+	if m.gitWriteClient.Destination(*module) == nil {
+		ctx.JSON(http.StatusBadRequest, dto.NewError("Error checking module drift", "Module is not written to git"))
+		return
+	}
+
+	result, err := m.driftDetector.Check(*module)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error checking module drift", err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ModuleDrift{
+		Drifted: result.Drifted,
+		Missing: result.Missing,
+		Diff:    result.Diff,
+	})
+}
+
 func (m *Modules) GetModuleHistory(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.Status(http.StatusInternalServerError)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, module.History)
+	entries, err := history.Entries(kubernetesClient, *module)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module history", err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
 }
 
 func (m *Modules) Manifest(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	var request v1alpha1.HistoryEntry
 	if err := ctx.BindJSON(&request); err != nil {
 		fmt.Println("error binding request", request)
@@ -236,9 +309,9 @@ func (m *Modules) Manifest(ctx *gin.Context) {
 }
 
 func (m *Modules) CurrentManifest(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.Status(http.StatusInternalServerError)
@@ -271,8 +344,201 @@ func (m *Modules) CurrentManifest(ctx *gin.Context) {
 	ctx.String(http.StatusOK, manifest)
 }
 
+func (m *Modules) Diff(ctx *gin.Context) {
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
+
+	var request dto.Module
+	if err := ctx.BindJSON(&request); err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusBadRequest, dto.NewError("Error mapping module request", err.Error()))
+		return
+	}
+
+	curr, err := kubernetesClient.GetModule(ctx.Param("name"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
+		return
+	}
+
+	module, err := mapper.RequestToModule(request)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error mapping module", err.Error()))
+		return
+	}
+
+	module.Name = curr.Name
+	module.SetAnnotations(curr.GetAnnotations())
+	module.Spec.TargetNamespace = curr.Spec.TargetNamespace
+	module.Spec.TemplateRef.SourceType = curr.Spec.TemplateRef.SourceType
+	module.Spec.TemplateRef.UpdatePolicy = curr.Spec.TemplateRef.UpdatePolicy
+
+	if request.DependsOn == nil {
+		module.Spec.DependsOn = curr.Spec.DependsOn
+	}
+	module.Spec.ValuesFrom = curr.Spec.ValuesFrom
+
+	targetTemplate, err := m.templatesRepo.GetTemplate(
+		module.Spec.TemplateRef.URL,
+		module.Spec.TemplateRef.Path,
+		module.Spec.TemplateRef.Version,
+		request.Template.ResolvedVersion,
+		module.Spec.TemplateRef.SourceType,
+	)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching template", err.Error()))
+		return
+	}
+
+	manifest, err := m.renderer.HelmTemplate(module, targetTemplate)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error rendering Module manifest", err.Error()))
+		return
+	}
+
+	diff, err := m.moduleDiff(kubernetesClient, module, curr.Status.ManagedGVRs, manifest)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error computing Module diff", err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, diff)
+}
+
+// moduleDiff dry-runs every object of the rendered manifest and compares the
+// result with the objects running in the cluster. Managed resources that are
+// no longer rendered are reported as removed if they would be pruned.
+func (m *Modules) moduleDiff(
+	kubernetesClient k8sclient.IKubernetesClient,
+	module v1alpha1.Module,
+	managedGVRs []v1alpha1.GroupVersionResource,
+	manifest string,
+) (*dto.ModuleDiff, error) {
+	type resourceKey struct {
+		group     string
+		kind      string
+		namespace string
+		name      string
+	}
+
+	out := &dto.ModuleDiff{
+		Resources: make([]dto.ResourceDiff, 0),
+	}
+	rendered := make(map[resourceKey]struct{})
+
+	forceConflicts := module.GetAnnotations()[v1alpha1.ForceConflictsAnnotation] == "true"
+
+	for _, s := range strings.Split(manifest, "\n---\n") {
+		s := strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+
+		var obj unstructured.Unstructured
+		decoder := yamlutil.NewYAMLOrJSONDecoder(strings.NewReader(s), len(s))
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, err
+		}
+
+		if len(obj.UnstructuredContent()) == 0 {
+			continue
+		}
+
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+
+		labels["app.kubernetes.io/managed-by"] = "cyclops"
+		labels["cyclops.module"] = module.Name
+		obj.SetLabels(labels)
+
+		resourceName, err := kubernetesClient.GVKtoAPIResourceName(obj.GroupVersionKind().GroupVersion(), obj.GroupVersionKind().Kind)
+		if err != nil {
+			out.Resources = append(out.Resources, failedResourceDiff(obj, err))
+			continue
+		}
+
+		current, proposed, err := kubernetesClient.DryRunApply(
+			v1alpha1.GroupVersionResource{
+				Group:    obj.GroupVersionKind().Group,
+				Version:  obj.GroupVersionKind().Version,
+				Resource: resourceName,
+			},
+			&obj,
+			module.Spec.TargetNamespace,
+			forceConflicts,
+		)
+
+		rendered[resourceKey{
+			group:     obj.GroupVersionKind().Group,
+			kind:      obj.GroupVersionKind().Kind,
+			namespace: obj.GetNamespace(),
+			name:      obj.GetName(),
+		}] = struct{}{}
+
+		if err != nil {
+			out.Resources = append(out.Resources, failedResourceDiff(obj, err))
+			continue
+		}
+
+		diff, err := mapper.ResourceDiff(current, proposed)
+		if err != nil {
+			return nil, err
+		}
+
+		if diff != nil {
+			out.Resources = append(out.Resources, *diff)
+		}
+	}
+
+	if !k8sclient.PruneEnabled(module) {
+		return out, nil
+	}
+
+	existing, err := kubernetesClient.ListManagedResources(module.Name, managedGVRs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range existing {
+		key := resourceKey{
+			group:     obj.GroupVersionKind().Group,
+			kind:      obj.GroupVersionKind().Kind,
+			namespace: obj.GetNamespace(),
+			name:      obj.GetName(),
+		}
+
+		if _, ok := rendered[key]; ok || !k8sclient.Prunable(obj) {
+			continue
+		}
+
+		// the same object is listed once for every managed version of its resource
+		rendered[key] = struct{}{}
+
+		out.Resources = append(out.Resources, mapper.RemovedResourceDiff(obj))
+	}
+
+	return out, nil
+}
+
+func failedResourceDiff(obj unstructured.Unstructured, err error) dto.ResourceDiff {
+	return dto.ResourceDiff{
+		Group:     obj.GroupVersionKind().Group,
+		Version:   obj.GroupVersionKind().Version,
+		Kind:      obj.GroupVersionKind().Kind,
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Error:     err.Error(),
+	}
+}
+
 func (m *Modules) DeleteModuleResource(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	var request *dto.Resource
 	if err := ctx.BindJSON(&request); err != nil {
@@ -281,7 +547,15 @@ func (m *Modules) DeleteModuleResource(ctx *gin.Context) {
 		return
 	}
 
-	if err := m.kubernetesClient.Delete(request); err != nil {
+	audit.SetResource(ctx, dto.AuditResource{
+		Group:     request.Group,
+		Version:   request.Version,
+		Kind:      request.Kind,
+		Name:      request.Name,
+		Namespace: request.Namespace,
+	})
+
+	if err := kubernetesClient.Delete(request); err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error deleting module", err.Error()))
 		return
@@ -291,7 +565,7 @@ func (m *Modules) DeleteModuleResource(ctx *gin.Context) {
 }
 
 func (m *Modules) CreateModule(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	var request dto.Module
 	if err := ctx.BindJSON(&request); err != nil {
@@ -311,18 +585,28 @@ func (m *Modules) CreateModule(ctx *gin.Context) {
 		module.Spec.TargetNamespace = m.moduleTargetNamespace
 	}
 
+	if !m.validateValues(ctx, nil, module, "") {
+		return
+	}
+
 	m.telemetryClient.ModuleCreation()
 
-	if module.GetAnnotations() != nil && len(module.GetAnnotations()[v1alpha1.GitOpsWriteRepoAnnotation]) != 0 {
-		err := m.gitWriteClient.Write(module)
+	setAuthor(ctx, &module)
+	auditModuleChange(ctx, module.Name, v1alpha1.ModuleSpec{}, module.Spec)
+
+	if m.gitWriteClient.Destination(module) != nil {
+		pullRequestURL, err := m.gitWriteClient.Write(module)
 		if err != nil {
 			fmt.Println(err)
 			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error pushing to git", err.Error()))
+			return
 		}
+
+		ctx.JSON(http.StatusOK, dto.GitOpsWriteResponse{PullRequestURL: pullRequestURL})
 		return
 	}
 
-	err = m.kubernetesClient.CreateModule(module)
+	err = kubernetesClient.CreateModule(module)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error creating module", err.Error()))
@@ -334,8 +618,6 @@ func (m *Modules) CreateModule(ctx *gin.Context) {
 }
 
 func (m *Modules) UpdateModule(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	var request dto.Module
 	if err := ctx.BindJSON(&request); err != nil {
 		fmt.Println(err)
@@ -343,7 +625,115 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 		return
 	}
 
-	curr, err := m.kubernetesClient.GetModule(request.Name)
+	m.updateModule(ctx, request)
+}
+
+// ModuleValues returns the Module values as a values.yaml file
+func (m *Modules) ModuleValues(ctx *gin.Context) {
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
+
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
+		return
+	}
+
+	moduleValues := make(map[string]interface{})
+	if len(module.Spec.Values.Raw) != 0 {
+		if err := json.Unmarshal(module.Spec.Values.Raw, &moduleValues); err != nil {
+			fmt.Println(err)
+			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error mapping module values", err.Error()))
+			return
+		}
+	}
+
+	data, err := values.ToYAML(moduleValues)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error mapping module values", err.Error()))
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/yaml", data)
+}
+
+// SetModuleValues applies values files and Helm style --set values on top of
+// the current Module values, or on empty values if the request resets them.
+// Values are converted to the types of the template fields before the Module
+// is updated.
+func (m *Modules) SetModuleValues(ctx *gin.Context) {
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
+
+	var request dto.ModuleValuesRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusBadRequest, dto.NewError("Error mapping module values request", err.Error()))
+		return
+	}
+
+	curr, err := kubernetesClient.GetModule(ctx.Param("name"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
+		return
+	}
+
+	base := make(map[string]interface{})
+	if !request.Reset && len(curr.Spec.Values.Raw) != 0 {
+		if err := json.Unmarshal(curr.Spec.Values.Raw, &base); err != nil {
+			fmt.Println(err)
+			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error mapping module values", err.Error()))
+			return
+		}
+	}
+
+	valuesFiles := make([][]byte, 0, len(request.ValuesFiles))
+	for _, file := range request.ValuesFiles {
+		valuesFiles = append(valuesFiles, []byte(file))
+	}
+
+	moduleValues, err := values.Options{
+		ValuesFiles:  valuesFiles,
+		Values:       request.Set,
+		StringValues: request.SetString,
+		JSONValues:   request.SetJSON,
+		FileValues:   request.SetFile,
+	}.Merge(base, func(path string) ([]byte, error) {
+		file, ok := request.Files[path]
+		if !ok {
+			return nil, fmt.Errorf("file %v not found in request", path)
+		}
+
+		return []byte(file), nil
+	})
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusBadRequest, dto.NewError("Error setting module values", err.Error()))
+		return
+	}
+
+	targetTemplate, ok := m.moduleTemplate(ctx, *curr, curr.Status.TemplateResolvedVersion)
+	if !ok {
+		return
+	}
+
+	module, err := mapper.ModuleToDTO(*curr)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error mapping module", err.Error()))
+		return
+	}
+
+	module.Values = values.Coerce(mapper.ValuesField(targetTemplate.RootField), moduleValues)
+
+	m.updateModule(ctx, module)
+}
+
+func (m *Modules) updateModule(ctx *gin.Context, request dto.Module) {
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
+
+	curr, err := kubernetesClient.GetModule(request.Name)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -358,15 +748,28 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 	}
 
 	module.Spec.TemplateRef.SourceType = curr.Spec.TemplateRef.SourceType
+	module.Spec.TemplateRef.UpdatePolicy = curr.Spec.TemplateRef.UpdatePolicy
 
 	module.Status.TemplateResolvedVersion = request.Template.ResolvedVersion
 	module.Status.ReconciliationStatus = curr.Status.ReconciliationStatus
 	module.Status.IconURL = curr.Status.IconURL
 	module.Status.ManagedGVRs = curr.Status.ManagedGVRs
+	module.Status.PrunedResources = curr.Status.PrunedResources
+	module.Status.ObservedGeneration = curr.Status.ObservedGeneration
+	module.Status.Conditions = curr.Status.Conditions
 
 	module.Spec.TargetNamespace = curr.Spec.TargetNamespace
 	module.SetLabels(curr.GetLabels())
 
+	if request.DependsOn == nil {
+		module.Spec.DependsOn = curr.Spec.DependsOn
+	}
+	module.Spec.ValuesFrom = curr.Spec.ValuesFrom
+
+	if !m.validateValues(ctx, curr, module, module.Status.TemplateResolvedVersion) {
+		return
+	}
+
 	annotations := curr.GetAnnotations()
 	moduleAnnotations := module.GetAnnotations()
 
@@ -398,40 +801,26 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 
 	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
 	module.SetAnnotations(annotations)
+	setAuthor(ctx, &module)
+	auditModuleChange(ctx, module.Name, curr.Spec, module.Spec)
 
-	if len(module.GetAnnotations()[v1alpha1.GitOpsWriteRepoAnnotation]) != 0 {
-		err := m.gitWriteClient.Write(module)
+	if m.gitWriteClient.Destination(module) != nil {
+		pullRequestURL, err := m.gitWriteClient.Write(module)
 		if err != nil {
 			fmt.Println(err)
 			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error pushing to git", err.Error()))
+			return
 		}
-		return
-	}
 
-	history := curr.History
-	if curr.History == nil {
-		history = make([]v1alpha1.HistoryEntry, 0)
+		ctx.JSON(http.StatusOK, dto.GitOpsWriteResponse{PullRequestURL: pullRequestURL})
+		return
 	}
 
-	module.History = append([]v1alpha1.HistoryEntry{{
-		Generation:      curr.Generation,
-		TargetNamespace: curr.Spec.TargetNamespace,
-		TemplateRef: v1alpha1.HistoryTemplateRef{
-			URL:        curr.Spec.TemplateRef.URL,
-			Path:       curr.Spec.TemplateRef.Path,
-			Version:    curr.Status.TemplateResolvedVersion,
-			SourceType: curr.Spec.TemplateRef.SourceType,
-		},
-		Values: curr.Spec.Values,
-	}}, history...)
-
-	if len(module.History) > 10 {
-		module.History = module.History[:len(module.History)-1]
-	}
+	module.History = curr.History
 
 	module.SetResourceVersion(curr.GetResourceVersion())
 
-	result, err := m.kubernetesClient.UpdateModuleStatus(&module)
+	result, err := kubernetesClient.UpdateModuleStatus(&module)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error updating module status", err.Error()))
@@ -439,7 +828,7 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 	}
 
 	module.ResourceVersion = result.ResourceVersion
-	err = m.kubernetesClient.UpdateModule(&module)
+	err = kubernetesClient.UpdateModule(&module)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error updating module", err.Error()))
@@ -449,8 +838,95 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// validateValues validates Module values against the schema of the template
+// version the Module is rendered from. Invalid values are rejected with the
+// fields that do not match the schema. If the Module is updated, changes to
+// values the template marks as immutable are rejected as well.
+func (m *Modules) validateValues(ctx *gin.Context, curr *v1alpha1.Module, module v1alpha1.Module, resolvedVersion string) bool {
+	targetTemplate, ok := m.moduleTemplate(ctx, module, resolvedVersion)
+	if !ok {
+		return false
+	}
+
+	err := m.renderer.ValidateValues(module, targetTemplate)
+	if err == nil {
+		return curr == nil || validateImmutableValues(ctx, targetTemplate, *curr, module)
+	}
+
+	fmt.Println(err)
+
+	var validationErr *render.ValidationError
+	if !stderrors.As(err, &validationErr) {
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error validating module values", err.Error()))
+		return false
+	}
+
+	fields := make([]dto.FieldError, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		fields = append(fields, dto.FieldError{Field: field.Field, Description: field.Description})
+	}
+
+	ctx.JSON(http.StatusBadRequest, dto.ValidationError{
+		Message:     "Invalid module values",
+		Description: err.Error(),
+		Fields:      fields,
+	})
+	return false
+}
+
+func (m *Modules) moduleTemplate(ctx *gin.Context, module v1alpha1.Module, resolvedVersion string) (*models.Template, bool) {
+	version := resolvedVersion
+	if len(version) == 0 {
+		version = module.Spec.TemplateRef.Version
+	}
+
+	targetTemplate, err := m.templatesRepo.GetTemplate(
+		module.Spec.TemplateRef.URL,
+		module.Spec.TemplateRef.Path,
+		version,
+		resolvedVersion,
+		module.Spec.TemplateRef.SourceType,
+	)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching template", err.Error()))
+		return nil, false
+	}
+
+	return targetTemplate, true
+}
+
+// validateImmutableValues rejects changes to values the template marks as
+// immutable with the paths of the changed values
+func validateImmutableValues(ctx *gin.Context, targetTemplate *models.Template, curr, module v1alpha1.Module) bool {
+	err := immutable.ValidateRaw(targetTemplate.RootField, curr.Spec.Values.Raw, module.Spec.Values.Raw)
+	if err == nil {
+		return true
+	}
+
+	fmt.Println(err)
+
+	var immutableErr *immutable.Error
+	if !stderrors.As(err, &immutableErr) {
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error validating module values", err.Error()))
+		return false
+	}
+
+	fields := make([]dto.FieldError, 0, len(immutableErr.Paths))
+	for _, path := range immutableErr.Paths {
+		fields = append(fields, dto.FieldError{Field: path, Description: "field is immutable"})
+	}
+
+	ctx.JSON(http.StatusBadRequest, dto.ValidationError{
+		Message:     "Immutable module values changed",
+		Description: err.Error(),
+		Fields:      fields,
+	})
+	return false
+}
+
 func (m *Modules) HistoryEntryManifest(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	var request dto.RollbackRequest
 	if err := ctx.BindJSON(&request); err != nil {
@@ -459,19 +935,18 @@ func (m *Modules) HistoryEntryManifest(ctx *gin.Context) {
 		return
 	}
 
-	curr, err := m.kubernetesClient.GetModule(request.ModuleName)
+	curr, err := kubernetesClient.GetModule(request.ModuleName)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
 		return
 	}
 
-	var targetGeneration *v1alpha1.HistoryEntry
-	for _, entry := range curr.History {
-		if entry.Generation == request.Generation {
-			targetGeneration = &entry
-			break
-		}
+	targetGeneration, err := history.Find(kubernetesClient, *curr, request.Generation)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module history", err.Error()))
+		return
 	}
 
 	if targetGeneration == nil {
@@ -520,7 +995,7 @@ func (m *Modules) HistoryEntryManifest(ctx *gin.Context) {
 }
 
 func (m *Modules) RollbackModule(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	var request dto.RollbackRequest
 	if err := ctx.BindJSON(&request); err != nil {
@@ -529,19 +1004,18 @@ func (m *Modules) RollbackModule(ctx *gin.Context) {
 		return
 	}
 
-	curr, err := m.kubernetesClient.GetModule(request.ModuleName)
+	curr, err := kubernetesClient.GetModule(request.ModuleName)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
 		return
 	}
 
-	var targetGeneration *v1alpha1.HistoryEntry
-	for _, entry := range curr.History {
-		if entry.Generation == request.Generation {
-			targetGeneration = &entry
-			break
-		}
+	targetGeneration, err := history.Find(kubernetesClient, *curr, request.Generation)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module history", err.Error()))
+		return
 	}
 
 	if targetGeneration == nil {
@@ -554,39 +1028,35 @@ func (m *Modules) RollbackModule(ctx *gin.Context) {
 	module.Kind = "Module"
 	module.APIVersion = "cyclops-ui.com/v1alpha1"
 
-	history := module.History
-	if module.History == nil {
-		history = make([]v1alpha1.HistoryEntry, 0)
-	}
+	history.Rollback(module, *targetGeneration)
 
-	module.History = append([]v1alpha1.HistoryEntry{{
-		Generation:      curr.Generation,
-		TargetNamespace: curr.Spec.TargetNamespace,
-		TemplateRef: v1alpha1.HistoryTemplateRef{
-			URL:        curr.Spec.TemplateRef.URL,
-			Path:       curr.Spec.TemplateRef.Path,
-			Version:    curr.Status.TemplateResolvedVersion,
-			SourceType: curr.Spec.TemplateRef.SourceType,
-		},
-		Values: curr.Spec.Values,
-	}}, history...)
+	targetTemplate, ok := m.moduleTemplate(ctx, *module, "")
+	if !ok {
+		return
+	}
 
-	if len(module.History) > 10 {
-		module.History = module.History[:len(module.History)-1]
+	if !validateImmutableValues(ctx, targetTemplate, *curr, *module) {
+		return
 	}
 
-	module.Spec.Values = targetGeneration.Values
-	module.Spec.TemplateRef = v1alpha1.TemplateRef{
-		URL:        targetGeneration.TemplateRef.URL,
-		Path:       targetGeneration.TemplateRef.Path,
-		Version:    targetGeneration.TemplateRef.Version,
-		SourceType: targetGeneration.TemplateRef.SourceType,
+	setAuthor(ctx, module)
+	auditModuleChange(ctx, module.Name, curr.Spec, module.Spec)
+
+	if m.gitWriteClient.Destination(*module) != nil {
+		pullRequestURL, err := m.gitWriteClient.Write(*module)
+		if err != nil {
+			fmt.Println(err)
+			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error pushing to git", err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dto.GitOpsWriteResponse{PullRequestURL: pullRequestURL})
+		return
 	}
-	module.Spec.TargetNamespace = targetGeneration.TargetNamespace
 
 	module.SetResourceVersion(curr.GetResourceVersion())
 
-	result, err := m.kubernetesClient.UpdateModuleStatus(module)
+	result, err := kubernetesClient.UpdateModuleStatus(module)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error updating module status", err.Error()))
@@ -594,7 +1064,7 @@ func (m *Modules) RollbackModule(ctx *gin.Context) {
 	}
 
 	module.ResourceVersion = result.ResourceVersion
-	err = m.kubernetesClient.UpdateModule(module)
+	err = kubernetesClient.UpdateModule(module)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error updating module", err.Error()))
@@ -605,11 +1075,12 @@ func (m *Modules) RollbackModule(ctx *gin.Context) {
 }
 
 func (m *Modules) ReconcileModule(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	moduleName := ctx.Param("name")
+	audit.SetModule(ctx, moduleName)
 
-	module, err := m.kubernetesClient.GetModule(moduleName)
+	module, err := kubernetesClient.GetModule(moduleName)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -627,7 +1098,7 @@ func (m *Modules) ReconcileModule(ctx *gin.Context) {
 	module.Kind = "Module"
 	module.APIVersion = "cyclops-ui.com/v1alpha1"
 
-	err = m.kubernetesClient.UpdateModule(module)
+	err = kubernetesClient.UpdateModule(module)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error updating module", err.Error()))
@@ -638,9 +1109,9 @@ func (m *Modules) ReconcileModule(ctx *gin.Context) {
 }
 
 func (m *Modules) ResourcesForModule(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, dto.NewError("Error mapping module request", err.Error()))
 		return
@@ -663,7 +1134,7 @@ func (m *Modules) ResourcesForModule(ctx *gin.Context) {
 		return
 	}
 
-	resources, err := m.kubernetesClient.GetResourcesForModule(ctx.Param("name"))
+	resources, err := kubernetesClient.GetResourcesForModule(ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module resources", err.Error()))
@@ -677,7 +1148,7 @@ func (m *Modules) ResourcesForModule(ctx *gin.Context) {
 		return
 	}
 
-	resources, err = m.kubernetesClient.GetDeletedResources(resources, manifest, module.Spec.TargetNamespace)
+	resources, err = kubernetesClient.GetDeletedResources(resources, manifest, module.Spec.TargetNamespace)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching deleted module resources", err.Error()))
@@ -696,9 +1167,9 @@ func (m *Modules) ResourcesForModule(ctx *gin.Context) {
 }
 
 func (m *Modules) Template(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -754,9 +1225,9 @@ func (m *Modules) Template(ctx *gin.Context) {
 }
 
 func (m *Modules) HelmTemplate(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := kubernetesClient.GetModule(ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -787,7 +1258,7 @@ func (m *Modules) HelmTemplate(ctx *gin.Context) {
 }
 
 //func (m *Modules) ModuleToResources(ctx *gin.Context) {
-//	err := m.kubernetesClient.ModuleToResources("test")
+//	err := kubernetesClient.ModuleToResources("test")
 //	if err != nil {
 //		fmt.Println(err)
 //	}
@@ -797,7 +1268,7 @@ func (m *Modules) HelmTemplate(ctx *gin.Context) {
 //}
 //
 //func (m *Modules) ResourcesForModule(ctx *gin.Context) {
-//	resources, err := m.kubernetesClient.ResourcesForModule(ctx.Param("name"))
+//	resources, err := kubernetesClient.ResourcesForModule(ctx.Param("name"))
 //	if err != nil {
 //		fmt.Println(err)
 //	}
@@ -807,10 +1278,10 @@ func (m *Modules) HelmTemplate(ctx *gin.Context) {
 //}
 
 func (m *Modules) GetLogs(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	logCount := int64(100)
-	rawLogs, err := m.kubernetesClient.GetPodLogs(
+	rawLogs, err := kubernetesClient.GetPodLogs(
 		ctx.Param("namespace"),
 		ctx.Param("container"),
 		ctx.Param("name"),
@@ -831,7 +1302,7 @@ func (m *Modules) GetLogs(ctx *gin.Context) {
 }
 
 func (m *Modules) GetLogsStream(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	logCount := int64(100)
 
@@ -840,7 +1311,7 @@ func (m *Modules) GetLogsStream(ctx *gin.Context) {
 	go func() {
 		defer close(logChan)
 
-		err := m.kubernetesClient.GetStreamedPodLogs(
+		err := kubernetesClient.GetStreamedPodLogs(
 			ctx.Request.Context(), // we will have to pass the context for the k8s podClient - so it can stop the stream when the client disconnects
 			ctx.Param("namespace"),
 			ctx.Param("container"),
@@ -874,10 +1345,10 @@ func (m *Modules) GetLogsStream(ctx *gin.Context) {
 }
 
 func (m *Modules) GetDeploymentLogs(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	logCount := int64(100)
-	logs, err := m.kubernetesClient.GetDeploymentLogs(
+	logs, err := kubernetesClient.GetDeploymentLogs(
 		ctx.Param("namespace"),
 		ctx.Param("container"),
 		ctx.Param("deployment"),
@@ -892,10 +1363,10 @@ func (m *Modules) GetDeploymentLogs(ctx *gin.Context) {
 }
 
 func (m *Modules) GetStatefulSetsLogs(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	logCount := int64(100)
-	logs, err := m.kubernetesClient.GetStatefulSetsLogs(
+	logs, err := kubernetesClient.GetStatefulSetsLogs(
 		ctx.Param("namespace"),
 		ctx.Param("container"),
 		ctx.Param("name"),
@@ -911,13 +1382,13 @@ func (m *Modules) GetStatefulSetsLogs(ctx *gin.Context) {
 }
 
 func (m *Modules) DownloadLogs(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	namespace := ctx.Param("namespace")
 	container := ctx.Param("container")
 	name := ctx.Param("name")
 
-	logs, err := m.kubernetesClient.GetPodLogs(
+	logs, err := kubernetesClient.GetPodLogs(
 		namespace,
 		container,
 		name,
@@ -952,7 +1423,7 @@ func (m *Modules) DownloadLogs(ctx *gin.Context) {
 }
 
 func (m *Modules) GetManifest(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	group := ctx.Query("group")
 	version := ctx.Query("version")
@@ -961,7 +1432,7 @@ func (m *Modules) GetManifest(ctx *gin.Context) {
 	namespace := ctx.Query("namespace")
 	includeManagedFields := ctx.Query("includeManagedFields") == "true"
 
-	manifest, err := m.kubernetesClient.GetManifest(group, version, kind, name, namespace, includeManagedFields)
+	manifest, err := kubernetesClient.GetManifest(group, version, kind, name, namespace, includeManagedFields)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "Failed to fetch resource manifest",
@@ -974,7 +1445,7 @@ func (m *Modules) GetManifest(ctx *gin.Context) {
 }
 
 func (m *Modules) Restart(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	group := ctx.Query("group")
 	version := ctx.Query("version")
@@ -982,7 +1453,15 @@ func (m *Modules) Restart(ctx *gin.Context) {
 	name := ctx.Query("name")
 	namespace := ctx.Query("namespace")
 
-	err := m.kubernetesClient.Restart(group, version, kind, name, namespace)
+	audit.SetResource(ctx, dto.AuditResource{
+		Group:     group,
+		Version:   version,
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+	})
+
+	err := kubernetesClient.Restart(group, version, kind, name, namespace)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "Failed to restart resource",
@@ -995,7 +1474,7 @@ func (m *Modules) Restart(ctx *gin.Context) {
 }
 
 func (m *Modules) GetResource(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	group := ctx.Query("group")
 	version := ctx.Query("version")
@@ -1003,7 +1482,7 @@ func (m *Modules) GetResource(ctx *gin.Context) {
 	name := ctx.Query("name")
 	namespace := ctx.Query("namespace")
 
-	resource, err := m.kubernetesClient.GetResource(group, version, kind, name, namespace)
+	resource, err := kubernetesClient.GetResource(group, version, kind, name, namespace)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "Failed to fetch resource",
@@ -1016,7 +1495,7 @@ func (m *Modules) GetResource(ctx *gin.Context) {
 }
 
 func (m *Modules) InstallMCPServer(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	mcpModuleValues := map[string]interface{}{
 		"replicas": 1,
@@ -1060,7 +1539,9 @@ func (m *Modules) InstallMCPServer(ctx *gin.Context) {
 		History: make([]v1alpha1.HistoryEntry, 0),
 	}
 
-	if err := m.kubernetesClient.CreateModule(mcpServerModule); err != nil {
+	audit.SetModule(ctx, mcpServerModule.Name)
+
+	if err := kubernetesClient.CreateModule(mcpServerModule); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "Failed to create Cyclops MCP server module",
 			"reason": err.Error(),
@@ -1072,13 +1553,13 @@ func (m *Modules) InstallMCPServer(ctx *gin.Context) {
 }
 
 func (m *Modules) MCPServerStatus(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, m.kubernetesClient)
 
 	type MCPServerStatus struct {
 		Installed bool `json:"installed"`
 	}
 
-	module, err := m.kubernetesClient.GetModule("mcp-cyclops")
+	module, err := kubernetesClient.GetModule("mcp-cyclops")
 	if err != nil {
 		if errors.IsNotFound(err) {
 			ctx.JSON(http.StatusOK, MCPServerStatus{Installed: false})
@@ -1101,6 +1582,37 @@ func (m *Modules) MCPServerStatus(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, MCPServerStatus{Installed: ok})
 }
 
+// auditModuleChange adds the Module and the changes to its spec to the audit
+// entry of the request
+func auditModuleChange(ctx *gin.Context, name string, current, proposed v1alpha1.ModuleSpec) {
+	audit.SetModule(ctx, name)
+
+	diff, err := mapper.ModuleSpecDiff(current, proposed)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	audit.SetDiff(ctx, diff)
+}
+
+// setAuthor sets the request user as the author of the Module change, which is
+// recorded on the Module revision
+func setAuthor(ctx *gin.Context, module *v1alpha1.Module) {
+	user := apiauth.UserFrom(ctx)
+	if user == nil {
+		return
+	}
+
+	annotations := module.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations[v1alpha1.AuthorAnnotation] = user.Name
+	module.SetAnnotations(annotations)
+}
+
 func getTargetGeneration(generation string, module *v1alpha1.Module) (*v1alpha1.Module, bool) {
 	// no generation specified means current generation
 	if len(generation) == 0 {
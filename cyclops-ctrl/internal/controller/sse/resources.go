@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
 
 	"github.com/pkg/errors"
@@ -16,7 +17,9 @@ import (
 )
 
 func (s *Server) Resources(ctx *gin.Context) {
-	resources, err := s.k8sClient.GetWorkloadsForModule(ctx.Param("name"))
+	k8sClient := apiauth.KubernetesClient(ctx, s.k8sClient)
+
+	resources, err := k8sClient.GetWorkloadsForModule(ctx.Param("name"))
 	if err != nil {
 		ctx.String(http.StatusInternalServerError, err.Error())
 		return
@@ -36,6 +39,8 @@ func (s *Server) ReleaseResources(ctx *gin.Context) {
 }
 
 func (s *Server) streamResources(ctx *gin.Context, resources []*dto.Resource) {
+	k8sClient := apiauth.KubernetesClient(ctx, s.k8sClient)
+
 	watchSpecs := make([]k8sclient.ResourceWatchSpec, 0, len(resources))
 	for _, resource := range resources {
 		if !k8sclient.IsWorkload(resource.GetGroup(), resource.GetVersion(), resource.GetKind()) {
@@ -61,7 +66,7 @@ func (s *Server) streamResources(ctx *gin.Context, resources []*dto.Resource) {
 
 	stopCh := make(chan struct{})
 
-	watchResource, err := s.k8sClient.WatchKubernetesResources(watchSpecs, stopCh)
+	watchResource, err := k8sClient.WatchKubernetesResources(watchSpecs, stopCh)
 	if err != nil {
 		ctx.String(http.StatusInternalServerError, err.Error())
 		return
@@ -75,7 +80,7 @@ func (s *Server) streamResources(ctx *gin.Context, resources []*dto.Resource) {
 					return false
 				}
 
-				res, err := s.k8sClient.GetResource(
+				res, err := k8sClient.GetResource(
 					u.GroupVersionKind().Group,
 					u.GroupVersionKind().Version,
 					u.GroupVersionKind().Kind,
@@ -100,6 +105,8 @@ func (s *Server) streamResources(ctx *gin.Context, resources []*dto.Resource) {
 }
 
 func (s *Server) SingleResource(ctx *gin.Context) {
+	k8sClient := apiauth.KubernetesClient(ctx, s.k8sClient)
+
 	type Ref struct {
 		Group     string `json:"group" form:"group"`
 		Version   string `json:"version" form:"version"`
@@ -114,7 +121,7 @@ func (s *Server) SingleResource(ctx *gin.Context) {
 		return
 	}
 
-	resourceName, err := s.k8sClient.GVKtoAPIResourceName(
+	resourceName, err := k8sClient.GVKtoAPIResourceName(
 		schema.GroupVersion{
 			Group:   r.Group,
 			Version: r.Version,
@@ -122,7 +129,7 @@ func (s *Server) SingleResource(ctx *gin.Context) {
 		r.Kind,
 	)
 
-	watchResource, err := s.k8sClient.WatchResource(r.Group, r.Version, resourceName, r.Name, r.Namespace)
+	watchResource, err := k8sClient.WatchResource(r.Group, r.Version, resourceName, r.Name, r.Namespace)
 	if err != nil {
 		ctx.String(http.StatusInternalServerError, err.Error())
 		return
@@ -138,7 +145,7 @@ func (s *Server) SingleResource(ctx *gin.Context) {
 					return false
 				}
 
-				res, err := s.k8sClient.GetResource(
+				res, err := k8sClient.GetResource(
 					r.Group,
 					r.Version,
 					r.Kind,
@@ -10,6 +10,9 @@ import (
 	json "github.com/json-iterator/go"
 
 	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/audit"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/mapper"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/telemetry"
@@ -19,24 +22,25 @@ import (
 type Templates struct {
 	templatesRepo    template.ITemplateRepo
 	kubernetesClient k8sclient.IKubernetesClient
+	gitWriteClient   *git.WriteClient
 	telemetryClient  telemetry.Client
 }
 
 func NewTemplatesController(
 	templatesRepo template.ITemplateRepo,
 	kubernetes k8sclient.IKubernetesClient,
+	gitWriteClient *git.WriteClient,
 	telemetryClient telemetry.Client,
 ) *Templates {
 	return &Templates{
 		templatesRepo:    templatesRepo,
 		kubernetesClient: kubernetes,
+		gitWriteClient:   gitWriteClient,
 		telemetryClient:  telemetryClient,
 	}
 }
 
 func (c *Templates) GetTemplate(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	repo := ctx.Query("repo")
 	path := ctx.Query("path")
 	commit := ctx.Query("commit")
@@ -63,8 +67,6 @@ func (c *Templates) GetTemplate(ctx *gin.Context) {
 }
 
 func (c *Templates) GetTemplateInitialValues(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	repo := ctx.Query("repo")
 	path := ctx.Query("path")
 	commit := ctx.Query("commit")
@@ -96,9 +98,9 @@ func (c *Templates) GetTemplateInitialValues(ctx *gin.Context) {
 }
 
 func (c *Templates) ListTemplatesStore(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, c.kubernetesClient)
 
-	store, err := c.kubernetesClient.ListTemplateStore()
+	store, err := kubernetesClient.ListTemplateStore()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching templates store", err.Error()))
 		return
@@ -110,7 +112,7 @@ func (c *Templates) ListTemplatesStore(ctx *gin.Context) {
 }
 
 func (c *Templates) CreateTemplatesStore(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, c.kubernetesClient)
 
 	var templateStore *dto.TemplateStore
 	if err := ctx.ShouldBind(&templateStore); err != nil {
@@ -143,7 +145,20 @@ func (c *Templates) CreateTemplatesStore(ctx *gin.Context) {
 
 	c.telemetryClient.TemplateCreation()
 
-	if err := c.kubernetesClient.CreateTemplateStore(k8sTemplateStore); err != nil {
+	audit.SetResource(ctx, templateStoreAuditResource(k8sTemplateStore.Name))
+
+	if c.gitWriteClient.DefaultDestination() != nil {
+		pullRequestURL, err := c.gitWriteClient.WriteTemplateStore(*k8sTemplateStore)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error pushing to git", err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, dto.GitOpsWriteResponse{PullRequestURL: pullRequestURL})
+		return
+	}
+
+	if err := kubernetesClient.CreateTemplateStore(k8sTemplateStore); err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error creating module", err.Error()))
 		return
 	}
@@ -152,7 +167,7 @@ func (c *Templates) CreateTemplatesStore(ctx *gin.Context) {
 }
 
 func (c *Templates) EditTemplatesStore(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, c.kubernetesClient)
 
 	var templateStore *dto.TemplateStore
 	if err := ctx.ShouldBind(&templateStore); err != nil {
@@ -187,7 +202,20 @@ func (c *Templates) EditTemplatesStore(ctx *gin.Context) {
 
 	c.telemetryClient.TemplateEdit()
 
-	if err := c.kubernetesClient.UpdateTemplateStore(k8sTemplateStore); err != nil {
+	audit.SetResource(ctx, templateStoreAuditResource(k8sTemplateStore.Name))
+
+	if c.gitWriteClient.DefaultDestination() != nil {
+		pullRequestURL, err := c.gitWriteClient.WriteTemplateStore(*k8sTemplateStore)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error pushing to git", err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, dto.GitOpsWriteResponse{PullRequestURL: pullRequestURL})
+		return
+	}
+
+	if err := kubernetesClient.UpdateTemplateStore(k8sTemplateStore); err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error creating module", err.Error()))
 		return
 	}
@@ -196,11 +224,23 @@ func (c *Templates) EditTemplatesStore(ctx *gin.Context) {
 }
 
 func (c *Templates) DeleteTemplatesStore(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	kubernetesClient := apiauth.KubernetesClient(ctx, c.kubernetesClient)
 
 	templateRefName := ctx.Param("name")
+	audit.SetResource(ctx, templateStoreAuditResource(templateRefName))
+
+	if c.gitWriteClient.DefaultDestination() != nil {
+		pullRequestURL, err := c.gitWriteClient.DeleteTemplateStore(templateRefName)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error deleting template store from git", err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dto.GitOpsWriteResponse{PullRequestURL: pullRequestURL})
+		return
+	}
 
-	if err := c.kubernetesClient.DeleteTemplateStore(templateRefName); err != nil {
+	if err := kubernetesClient.DeleteTemplateStore(templateRefName); err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error deleting module", err.Error()))
 		return
 	}
@@ -209,8 +249,6 @@ func (c *Templates) DeleteTemplatesStore(ctx *gin.Context) {
 }
 
 func (c *Templates) GetTemplateRevisions(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
-
 	repo := ctx.Query("repo")
 	path := ctx.Query("path")
 
@@ -232,3 +270,13 @@ func (c *Templates) GetTemplateRevisions(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, revisions)
 }
+
+// templateStoreAuditResource identifies a TemplateStore in audit entries
+func templateStoreAuditResource(name string) dto.AuditResource {
+	return dto.AuditResource{
+		Group:   cyclopsv1alpha1.GroupVersion.Group,
+		Version: cyclopsv1alpha1.GroupVersion.Version,
+		Kind:    "TemplateStore",
+		Name:    name,
+	}
+}
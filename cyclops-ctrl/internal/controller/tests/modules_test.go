@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/controller"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/helm"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/prometheus"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/mocks"
+	k8smocks "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/render"
+)
+
+var _ = Describe("Modules controller test", func() {
+	const repo = "https://github.com/my-org/templates"
+
+	var w *httptest.ResponseRecorder
+	var templatesRepo *mocks.ITemplateRepo
+	var k8sClient *k8smocks.IKubernetesClient
+	var r *gin.Engine
+
+	moduleTemplate := &models.Template{
+		RootField: models.Field{
+			Properties: []models.Field{
+				{Name: "replicas", Type: "number"},
+				{
+					Name: "storage",
+					Properties: []models.Field{
+						{Name: "class", Immutable: true},
+					},
+				},
+			},
+		},
+	}
+
+	current := &v1alpha1.Module{
+		ObjectMeta: v1.ObjectMeta{Name: "db", Generation: 2},
+		Spec: v1alpha1.ModuleSpec{
+			TemplateRef: v1alpha1.TemplateRef{URL: repo, Path: "db", Version: "main"},
+			Values:      apiextensionsv1.JSON{Raw: []byte(`{"replicas":1,"storage":{"class":"fast"}}`)},
+		},
+		Status: v1alpha1.ModuleStatus{TemplateResolvedVersion: "3f2a1c"},
+	}
+
+	send := func(path string, body interface{}) {
+		data, err := json.Marshal(body)
+		Expect(err).NotTo(HaveOccurred())
+
+		req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+		r.ServeHTTP(w, req)
+	}
+
+	expectImmutableError := func(fields ...string) {
+		Expect(w.Code).To(BeEquivalentTo(http.StatusBadRequest))
+
+		var response dto.ValidationError
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Message).To(BeEquivalentTo("Immutable module values changed"))
+
+		paths := make([]string, 0, len(response.Fields))
+		for _, field := range response.Fields {
+			paths = append(paths, field.Field)
+		}
+		Expect(paths).To(Equal(fields))
+
+		k8sClient.AssertNotCalled(GinkgoT(), "UpdateModule", mock.Anything)
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		k8sClient = &k8smocks.IKubernetesClient{}
+		templatesRepo = &mocks.ITemplateRepo{}
+		w = httptest.NewRecorder()
+		_, r = gin.CreateTestContext(w)
+
+		modules := controller.NewModulesController(
+			templatesRepo,
+			k8sClient,
+			render.NewRenderer(k8sClient),
+			nil,
+			nil,
+			"",
+			nil,
+			prometheus.Monitor{},
+		)
+		r.POST("/modules/update", modules.UpdateModule)
+		r.POST("/modules/rollback", modules.RollbackModule)
+		r.POST("/modules/:name/values", modules.SetModuleValues)
+		r.POST("/modules/:name/diff", modules.Diff)
+
+		k8sClient.On("GetModule", "db").Return(current, nil)
+		templatesRepo.On("GetTemplate", repo, "db", mock.Anything, mock.Anything, mock.Anything).Return(moduleTemplate, nil)
+	})
+
+	Describe("UpdateModule method", func() {
+		It("rejects changes to immutable values", func() {
+			send("/modules/update", dto.Module{
+				Name:     "db",
+				Template: dto.Template{URL: repo, Path: "db", Version: "main", ResolvedVersion: "3f2a1c"},
+				Values: map[string]interface{}{
+					"replicas": 3,
+					"storage":  map[string]interface{}{"class": "slow"},
+				},
+			})
+
+			expectImmutableError("storage.class")
+		})
+	})
+
+	Describe("RollbackModule method", func() {
+		It("rejects rollbacks changing immutable values", func() {
+			k8sClient.On("ListModuleRevisions", "db").Return([]v1alpha1.ModuleRevision{
+				{
+					ObjectMeta: v1.ObjectMeta{Name: "db-1"},
+					Spec: v1alpha1.ModuleRevisionSpec{
+						ModuleName: "db",
+						HistoryEntry: v1alpha1.HistoryEntry{
+							Generation:  1,
+							TemplateRef: v1alpha1.HistoryTemplateRef{URL: repo, Path: "db", Version: "1a2b3c"},
+							Values:      apiextensionsv1.JSON{Raw: []byte(`{"replicas":1,"storage":{"class":"standard"}}`)},
+						},
+					},
+				},
+			}, nil)
+
+			send("/modules/rollback", dto.RollbackRequest{ModuleName: "db", Generation: 1})
+
+			expectImmutableError("storage.class")
+			templatesRepo.AssertCalled(GinkgoT(), "GetTemplate", repo, "db", "1a2b3c", "", v1alpha1.TemplateSourceType(""))
+		})
+	})
+
+	Describe("SetModuleValues method", func() {
+		It("sets values on top of the current values", func() {
+			k8sClient.On("UpdateModuleStatus", mock.Anything).Return(current, nil)
+			k8sClient.On("UpdateModule", mock.Anything).Return(nil)
+
+			send("/modules/db/values", dto.ModuleValuesRequest{
+				ValuesFiles: []string{"storage:\n  size: 10Gi\n"},
+				SetString:   []string{"replicas=3"},
+			})
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+
+			var values map[string]interface{}
+			updated := k8sClient.Calls[len(k8sClient.Calls)-1].Arguments.Get(0).(*v1alpha1.Module)
+			Expect(json.Unmarshal(updated.Spec.Values.Raw, &values)).To(Succeed())
+			Expect(values).To(Equal(map[string]interface{}{
+				"replicas": float64(3),
+				"storage":  map[string]interface{}{"class": "fast", "size": "10Gi"},
+			}))
+			Expect(updated.Status.TemplateResolvedVersion).To(BeEquivalentTo("3f2a1c"))
+		})
+
+		It("rejects changes to immutable values", func() {
+			send("/modules/db/values", dto.ModuleValuesRequest{
+				Set: []string{"storage.class=slow"},
+			})
+
+			expectImmutableError("storage.class")
+		})
+
+		It("rejects invalid values", func() {
+			send("/modules/db/values", dto.ModuleValuesRequest{
+				SetFile: []string{"config=app.conf"},
+			})
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusBadRequest))
+			k8sClient.AssertNotCalled(GinkgoT(), "UpdateModule", mock.Anything)
+		})
+	})
+
+	Describe("Diff method", func() {
+		It("renders the module with its values sources", func() {
+			api := &v1alpha1.Module{
+				ObjectMeta: v1.ObjectMeta{Name: "api"},
+				Spec: v1alpha1.ModuleSpec{
+					TemplateRef: v1alpha1.TemplateRef{URL: repo, Path: "api", Version: "main"},
+					Values:      apiextensionsv1.JSON{Raw: []byte(`{"replicas":1}`)},
+					ValuesFrom: []v1alpha1.ValuesReference{
+						{Kind: v1alpha1.ValuesFromKindSecret, Name: "api-values", Key: "values.yaml"},
+					},
+				},
+			}
+
+			k8sClient.On("GetModule", "api").Return(api, nil)
+			k8sClient.On("GetValuesSource", v1alpha1.ValuesFromKindSecret, "api-values").
+				Return(map[string]string{"values.yaml": "replicas: 3\n"}, nil)
+			k8sClient.On("VersionInfo").Return(&version.Info{Major: "1", Minor: "30"}, nil)
+			k8sClient.On("ListManagedResources", "api", mock.Anything).Return([]unstructured.Unstructured{}, nil)
+			templatesRepo.On("GetTemplate", repo, "api", "main", "3f2a1c", mock.Anything).Return(&models.Template{
+				HelmChartMetadata: &helm.Metadata{Name: "api"},
+			}, nil)
+
+			send("/modules/api/diff", dto.Module{
+				Name:     "api",
+				Template: dto.Template{URL: repo, Path: "api", Version: "main", ResolvedVersion: "3f2a1c"},
+				Values:   map[string]interface{}{"replicas": 2},
+			})
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+			k8sClient.AssertCalled(GinkgoT(), "GetValuesSource", v1alpha1.ValuesFromKindSecret, "api-values")
+		})
+	})
+})
@@ -35,7 +35,7 @@ var _ = Describe("Templates controller test", func() {
 		gin.SetMode(gin.TestMode)
 		k8sClient = &k8smocks.IKubernetesClient{}
 		templatesRepo = &mocks.ITemplateRepo{}
-		templatesController = controller.NewTemplatesController(templatesRepo, k8sClient, telemetry.MockClient{})
+		templatesController = controller.NewTemplatesController(templatesRepo, k8sClient, nil, telemetry.MockClient{})
 		w = httptest.NewRecorder()
 		ctx, r = gin.CreateTestContext(w)
 	})
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/controller"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/mocks"
+	k8smocks "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+var _ = Describe("Webhooks controller test", func() {
+	const secret = "webhook-secret"
+
+	var w *httptest.ResponseRecorder
+	var templatesRepo *mocks.ITemplateRepo
+	var k8sClient *k8smocks.IKubernetesClient
+	var r *gin.Engine
+
+	module := func(name, repo, path, version, resolvedVersion string) v1alpha1.Module {
+		return v1alpha1.Module{
+			ObjectMeta: v1.ObjectMeta{Name: name},
+			Spec: v1alpha1.ModuleSpec{
+				TemplateRef: v1alpha1.TemplateRef{URL: repo, Path: path, Version: version},
+			},
+			Status: v1alpha1.ModuleStatus{TemplateResolvedVersion: resolvedVersion},
+		}
+	}
+
+	sign := func(body []byte) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	send := func(body []byte, header http.Header) {
+		req, err := http.NewRequest(http.MethodPost, "/webhooks/templates", bytes.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header = header
+		r.ServeHTTP(w, req)
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		k8sClient = &k8smocks.IKubernetesClient{}
+		templatesRepo = &mocks.ITemplateRepo{}
+		w = httptest.NewRecorder()
+		_, r = gin.CreateTestContext(w)
+		r.POST("/webhooks/templates", controller.NewWebhooksController(templatesRepo, k8sClient, secret).TemplatePush)
+	})
+
+	It("refreshes modules using the pushed branch", func() {
+		body := []byte(`{"ref":"refs/heads/main","repository":{"clone_url":"https://github.com/my-org/templates.git","html_url":"https://github.com/my-org/templates","default_branch":"main"}}`)
+
+		k8sClient.On("ListModules").Return([]v1alpha1.Module{
+			module("api", "https://github.com/my-org/templates", "api", "main", "4b825dc642cb6eb9a060e54bf8d69288fbee4904"),
+			module("worker", "https://github.com/my-org/templates", "worker", "release", "4b825dc642cb6eb9a060e54bf8d69288fbee4904"),
+			module("other", "https://github.com/my-org/other", "api", "main", "4b825dc642cb6eb9a060e54bf8d69288fbee4904"),
+		}, nil)
+		k8sClient.On("UpdateModuleStatus", mock.MatchedBy(func(m *v1alpha1.Module) bool {
+			return m.Name == "api" && m.Status.TemplateResolvedVersion == ""
+		})).Return(nil, nil)
+
+		send(body, http.Header{
+			"X-Github-Event":      []string{"push"},
+			"X-Hub-Signature-256": []string{sign(body)},
+		})
+
+		Expect(w.Code).To(BeEquivalentTo(http.StatusAccepted))
+
+		var response dto.TemplatePushResponse
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Modules).To(ConsistOf("api"))
+		k8sClient.AssertNumberOfCalls(GinkgoT(), "UpdateModuleStatus", 1)
+	})
+
+	It("invalidates cached charts on registry pushes", func() {
+		body := []byte(`{"repo":"oci://registry.my-org.com/charts","chart":"demo","version":"1.2.0"}`)
+
+		k8sClient.On("ListModules").Return([]v1alpha1.Module{
+			module("demo", "oci://registry.my-org.com/charts", "demo", "^1.0.0", "1.1.0"),
+		}, nil)
+		k8sClient.On("UpdateModuleStatus", mock.Anything).Return(nil, nil)
+		templatesRepo.On("InvalidateTemplate", "oci://registry.my-org.com/charts", "demo", "1.2.0").Return()
+
+		send(body, http.Header{"X-Hub-Signature-256": []string{sign(body)}})
+
+		Expect(w.Code).To(BeEquivalentTo(http.StatusAccepted))
+		templatesRepo.AssertCalled(GinkgoT(), "InvalidateTemplate", "oci://registry.my-org.com/charts", "demo", "1.2.0")
+	})
+
+	It("rejects invalid signatures", func() {
+		body := []byte(`{"repo":"oci://registry.my-org.com/charts","chart":"demo"}`)
+
+		send(body, http.Header{"X-Hub-Signature-256": []string{sign([]byte("other payload"))}})
+
+		Expect(w.Code).To(BeEquivalentTo(http.StatusUnauthorized))
+		k8sClient.AssertNotCalled(GinkgoT(), "ListModules")
+	})
+})
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/webhook"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+	templaterepo "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template"
+)
+
+const maxWebhookPayloadSize = 25 << 20
+
+type Webhooks struct {
+	templatesRepo    templaterepo.ITemplateRepo
+	kubernetesClient k8sclient.IKubernetesClient
+	secret           string
+}
+
+func NewWebhooksController(
+	templatesRepo templaterepo.ITemplateRepo,
+	kubernetes k8sclient.IKubernetesClient,
+	secret string,
+) *Webhooks {
+	return &Webhooks{
+		templatesRepo:    templatesRepo,
+		kubernetesClient: kubernetes,
+		secret:           secret,
+	}
+}
+
+// TemplatePush refreshes Modules using the pushed template. Their resolved
+// template version is cleared, so the reconciler resolves branches, tags and
+// chart versions again.
+func (w *Webhooks) TemplatePush(ctx *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxWebhookPayloadSize))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.NewError("Error reading webhook payload", err.Error()))
+		return
+	}
+
+	if err := webhook.ValidateSignature(ctx.Request.Header, body, w.secret); err != nil {
+		ctx.JSON(http.StatusUnauthorized, dto.NewError("Error validating webhook", err.Error()))
+		return
+	}
+
+	push, err := webhook.Parse(ctx.Request.Header, body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.NewError("Error parsing webhook payload", err.Error()))
+		return
+	}
+
+	refreshed := make([]string, 0)
+	if push == nil {
+		ctx.JSON(http.StatusOK, dto.TemplatePushResponse{Modules: refreshed})
+		return
+	}
+
+	modules, err := w.kubernetesClient.ListModules()
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error listing modules", err.Error()))
+		return
+	}
+
+	var refreshErrors []error
+	for _, module := range modules {
+		ref := module.Spec.TemplateRef
+		if !push.Matches(ref) {
+			continue
+		}
+
+		if len(push.Chart) != 0 {
+			version := push.Version
+			if len(version) == 0 {
+				version = module.Status.TemplateResolvedVersion
+			}
+
+			w.templatesRepo.InvalidateTemplate(ref.URL, ref.Path, version)
+		}
+
+		if err := w.refreshModule(module); err != nil {
+			refreshErrors = append(refreshErrors, fmt.Errorf("module %v: %w", module.Name, err))
+			continue
+		}
+
+		refreshed = append(refreshed, module.Name)
+	}
+
+	if len(refreshErrors) != 0 {
+		err := errors.Join(refreshErrors...)
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error refreshing modules", err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, dto.TemplatePushResponse{Modules: refreshed})
+}
+
+func (w *Webhooks) refreshModule(module cyclopsv1alpha1.Module) error {
+	if len(module.Status.TemplateResolvedVersion) != 0 {
+		module.Status.TemplateResolvedVersion = ""
+		_, err := w.kubernetesClient.UpdateModuleStatus(&module)
+		return err
+	}
+
+	// the version is not resolved yet; the Module is only reconciled again
+	annotations := module.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations["cyclops/reconciled-at"] = time.Now().Format(time.RFC3339)
+	module.SetAnnotations(annotations)
+
+	module.Kind = "Module"
+	module.APIVersion = "cyclops-ui.com/v1alpha1"
+
+	return w.kubernetesClient.UpdateModule(&module)
+}
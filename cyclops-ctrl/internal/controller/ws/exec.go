@@ -10,14 +10,25 @@ import (
 	"github.com/gorilla/websocket"
 	"k8s.io/client-go/tools/remotecommand"
 
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/audit"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
 )
 
 func (s *Server) ExecCommand(c *gin.Context) {
+	k8sClient := apiauth.KubernetesClient(c, s.k8sClient)
+
 	namespace := c.Param("podNamespace")
 	pod := c.Param("podName")
 	container := c.Param("containerName")
 
+	audit.SetResource(c, dto.AuditResource{
+		Version:   "v1",
+		Kind:      "Pod",
+		Name:      pod,
+		Namespace: namespace,
+	})
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		fmt.Println(err)
@@ -26,7 +37,7 @@ func (s *Server) ExecCommand(c *gin.Context) {
 	}
 	defer conn.Close()
 
-	exec, err := s.k8sClient.CommandExecutor(namespace, pod, container)
+	exec, err := k8sClient.CommandExecutor(namespace, pod, container)
 	if err != nil {
 		fmt.Println(err)
 		c.JSON(http.StatusInternalServerError, dto.NewError("failed to init command exector", err.Error()))
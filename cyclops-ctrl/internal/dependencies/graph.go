@@ -0,0 +1,84 @@
+package dependencies
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// Graph maps a Module name to the names of Modules it depends on
+type Graph map[string][]string
+
+func NewGraph(modules []cyclopsv1alpha1.Module) Graph {
+	g := make(Graph, len(modules))
+
+	for _, module := range modules {
+		dependsOn := make([]string, 0, len(module.Spec.DependsOn))
+		for _, dependency := range module.Spec.DependsOn {
+			dependsOn = append(dependsOn, dependency.Name)
+		}
+
+		g[module.Name] = dependsOn
+	}
+
+	return g
+}
+
+// Cycle returns a dependency cycle the given Module is part of, starting and
+// ending with the Module name. Returns nil if there is no such cycle.
+func (g Graph) Cycle(name string) []string {
+	visited := make(map[string]bool)
+
+	var visit func(current string, path []string) []string
+	visit = func(current string, path []string) []string {
+		for _, dependency := range g[current] {
+			if dependency == name {
+				return append(path, dependency)
+			}
+
+			if visited[dependency] {
+				continue
+			}
+			visited[dependency] = true
+
+			if cycle := visit(dependency, append(path, dependency)); cycle != nil {
+				return cycle
+			}
+		}
+
+		return nil
+	}
+
+	return visit(name, []string{name})
+}
+
+// Dependents returns the names of Modules that directly depend on the given Module
+func (g Graph) Dependents(name string) []string {
+	dependents := make([]string, 0)
+
+	for module, dependsOn := range g {
+		for _, dependency := range dependsOn {
+			if dependency == name {
+				dependents = append(dependents, module)
+				break
+			}
+		}
+	}
+
+	sort.Strings(dependents)
+
+	return dependents
+}
+
+// Ready reports whether the Module has been reconciled at its current generation
+// and all of its workloads are available
+func Ready(module cyclopsv1alpha1.Module) bool {
+	ready := meta.FindStatusCondition(module.Status.Conditions, cyclopsv1alpha1.ModuleReady)
+
+	return ready != nil &&
+		ready.Status == metav1.ConditionTrue &&
+		ready.ObservedGeneration == module.Generation
+}
@@ -0,0 +1,86 @@
+package dependencies
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+func TestDependencies(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test dependencies")
+}
+
+func module(name string, dependsOn ...string) cyclopsv1alpha1.Module {
+	refs := make([]cyclopsv1alpha1.ModuleReference, 0, len(dependsOn))
+	for _, d := range dependsOn {
+		refs = append(refs, cyclopsv1alpha1.ModuleReference{Name: d})
+	}
+
+	return cyclopsv1alpha1.Module{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       cyclopsv1alpha1.ModuleSpec{DependsOn: refs},
+	}
+}
+
+var _ = Describe("Dependency graph test", func() {
+	Describe("Cycle", func() {
+		It("returns nil for acyclic dependencies", func() {
+			g := NewGraph([]cyclopsv1alpha1.Module{
+				module("app", "migrations", "db"),
+				module("migrations", "db"),
+				module("db"),
+			})
+
+			Expect(g.Cycle("app")).To(BeNil())
+			Expect(g.Cycle("db")).To(BeNil())
+		})
+
+		It("returns the cycle a module is part of", func() {
+			g := NewGraph([]cyclopsv1alpha1.Module{
+				module("app", "migrations"),
+				module("migrations", "db"),
+				module("db", "app"),
+			})
+
+			Expect(g.Cycle("migrations")).To(Equal([]string{"migrations", "db", "app", "migrations"}))
+		})
+
+		It("returns self dependency as a cycle", func() {
+			g := NewGraph([]cyclopsv1alpha1.Module{
+				module("app", "app"),
+			})
+
+			Expect(g.Cycle("app")).To(Equal([]string{"app", "app"}))
+		})
+
+		It("ignores cycles the module only depends on", func() {
+			g := NewGraph([]cyclopsv1alpha1.Module{
+				module("app", "db"),
+				module("db", "cache"),
+				module("cache", "db"),
+			})
+
+			Expect(g.Cycle("app")).To(BeNil())
+			Expect(g.Cycle("db")).To(Equal([]string{"db", "cache", "db"}))
+		})
+	})
+
+	Describe("Dependents", func() {
+		It("returns modules depending on the given module", func() {
+			g := NewGraph([]cyclopsv1alpha1.Module{
+				module("app", "migrations", "db"),
+				module("migrations", "db"),
+				module("db"),
+			})
+
+			Expect(g.Dependents("db")).To(Equal([]string{"app", "migrations"}))
+			Expect(g.Dependents("app")).To(BeEmpty())
+		})
+	})
+})
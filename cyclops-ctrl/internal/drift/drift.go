@@ -0,0 +1,255 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/mapper"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/prometheus"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+// DefaultInterval is how often Modules are compared with git if the interval
+// is not configured
+const DefaultInterval = 5 * time.Minute
+
+const (
+	reasonDriftDetected    = "DriftDetected"
+	reasonMissingFromGit   = "MissingFromGit"
+	reasonInSync           = "InSync"
+	reasonResynced         = "ResyncedFromGit"
+	reasonDriftCheckFailed = "DriftCheckFailed"
+)
+
+// Result of comparing a Module in the cluster with its git destination
+type Result struct {
+	Drifted bool
+	// Missing is true when there is no file for the Module in git
+	Missing bool
+	// Diff is the JSON patch from the Module spec in git to the spec in the
+	// cluster
+	Diff []dto.JSONPatch
+}
+
+// Detector periodically compares Modules written to git with the Modules in
+// the cluster. With resync enabled, drifted Modules are updated from git.
+type Detector struct {
+	kubernetesClient k8sclient.IKubernetesClient
+	gitClient        *git.WriteClient
+	monitor          *prometheus.Monitor
+	interval         time.Duration
+	resync           bool
+	logger           logr.Logger
+
+	// checked holds the Modules with drift metrics from the last check
+	checked map[string]struct{}
+}
+
+func NewDetector(
+	kubernetesClient k8sclient.IKubernetesClient,
+	gitClient *git.WriteClient,
+	monitor *prometheus.Monitor,
+	interval time.Duration,
+	resync bool,
+	logger logr.Logger,
+) *Detector {
+	return &Detector{
+		kubernetesClient: kubernetesClient,
+		gitClient:        gitClient,
+		monitor:          monitor,
+		interval:         interval,
+		resync:           resync,
+		logger:           logger,
+		checked:          make(map[string]struct{}),
+	}
+}
+
+// Start runs drift checks until the context is done. A non-positive interval
+// disables periodic checks.
+func (d *Detector) Start(ctx context.Context) error {
+	if d.interval <= 0 {
+		d.logger.Info("periodic drift detection disabled")
+		return nil
+	}
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	d.logger.Info("starting drift detection", "interval", d.interval, "resync", d.resync)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := d.CheckAll(); err != nil {
+				d.logger.Error(err, "failed to check modules drift")
+			}
+		}
+	}
+}
+
+// CheckAll compares all Modules with a git destination and updates their
+// DriftDetected condition and drift metrics
+func (d *Detector) CheckAll() error {
+	modules, err := d.kubernetesClient.ListModules()
+	if err != nil {
+		return err
+	}
+
+	reader := d.gitClient.NewReader()
+	checked := make(map[string]struct{}, len(modules))
+
+	for _, module := range modules {
+		if d.gitClient.Destination(module) == nil {
+			continue
+		}
+
+		checked[module.Name] = struct{}{}
+
+		desired, err := reader.ReadModule(module)
+		if err != nil {
+			d.logger.Error(err, "failed to read module from git", "module", module.Name)
+			d.setCondition(module, metav1.ConditionUnknown, reasonDriftCheckFailed, err.Error())
+			continue
+		}
+
+		result, err := Compare(module, desired)
+		if err != nil {
+			d.logger.Error(err, "failed to compare module with git", "module", module.Name)
+			d.setCondition(module, metav1.ConditionUnknown, reasonDriftCheckFailed, err.Error())
+			continue
+		}
+
+		d.monitor.SetModuleDrift(module.Name, result.Drifted)
+
+		switch {
+		case result.Missing:
+			d.setCondition(module, metav1.ConditionTrue, reasonMissingFromGit, "module is not committed to its git destination")
+		case result.Drifted && d.resync:
+			if err := d.resyncModule(module, *desired); err != nil {
+				d.logger.Error(err, "failed to resync module from git", "module", module.Name)
+				d.setCondition(module, metav1.ConditionTrue, reasonDriftDetected, driftMessage(result))
+				continue
+			}
+
+			d.logger.Info("resynced module from git", "module", module.Name)
+			d.monitor.SetModuleDrift(module.Name, false)
+		case result.Drifted:
+			d.setCondition(module, metav1.ConditionTrue, reasonDriftDetected, driftMessage(result))
+		default:
+			d.setCondition(module, metav1.ConditionFalse, reasonInSync, "module matches its git destination")
+		}
+	}
+
+	for name := range d.checked {
+		if _, ok := checked[name]; !ok {
+			d.monitor.DeleteModuleDrift(name)
+		}
+	}
+	d.checked = checked
+
+	return nil
+}
+
+// Check compares a single Module with its git destination
+func (d *Detector) Check(module cyclopsv1alpha1.Module) (Result, error) {
+	if d.gitClient.Destination(module) == nil {
+		return Result{}, fmt.Errorf("module %v is not written to git", module.Name)
+	}
+
+	desired, err := d.gitClient.NewReader().ReadModule(module)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Compare(module, desired)
+}
+
+// Compare returns the difference between the Module in the cluster and the
+// Module committed to git. A nil desired Module means it is missing from git.
+func Compare(live cyclopsv1alpha1.Module, desired *cyclopsv1alpha1.Module) (Result, error) {
+	if desired == nil {
+		return Result{Drifted: true, Missing: true}, nil
+	}
+
+	diff, err := mapper.ModuleSpecDiff(normalizeSpec(desired.Spec), normalizeSpec(live.Spec))
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Drifted: len(diff) != 0,
+		Diff:    diff,
+	}, nil
+}
+
+// normalizeSpec makes specs comparable regardless of how empty values are
+// written; differences in values formatting are handled by the JSON patch
+func normalizeSpec(spec cyclopsv1alpha1.ModuleSpec) cyclopsv1alpha1.ModuleSpec {
+	spec = *spec.DeepCopy()
+
+	if raw := string(spec.Values.Raw); len(raw) == 0 || raw == "null" {
+		spec.Values.Raw = []byte("{}")
+	}
+
+	if len(spec.DependsOn) == 0 {
+		spec.DependsOn = nil
+	}
+
+	if len(spec.ValuesFrom) == 0 {
+		spec.ValuesFrom = nil
+	}
+
+	return spec
+}
+
+func (d *Detector) resyncModule(live, desired cyclopsv1alpha1.Module) error {
+	live.Spec = desired.Spec
+	if err := d.kubernetesClient.UpdateModule(&live); err != nil {
+		return err
+	}
+
+	updated, err := d.kubernetesClient.GetModule(live.Name)
+	if err != nil {
+		return err
+	}
+
+	d.setCondition(*updated, metav1.ConditionFalse, reasonResynced, "module was updated from its git destination")
+	return nil
+}
+
+// setCondition updates the DriftDetected condition of the Module if it changed
+func (d *Detector) setCondition(module cyclopsv1alpha1.Module, status metav1.ConditionStatus, reason, message string) {
+	changed := meta.SetStatusCondition(&module.Status.Conditions, metav1.Condition{
+		Type:               cyclopsv1alpha1.ModuleDriftDetected,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: module.Generation,
+	})
+	if !changed {
+		return
+	}
+
+	if _, err := d.kubernetesClient.UpdateModuleStatus(&module); err != nil {
+		d.logger.Error(err, "failed to update module drift condition", "module", module.Name)
+	}
+}
+
+func driftMessage(result Result) string {
+	paths := make([]string, 0, len(result.Diff))
+	for _, patch := range result.Diff {
+		paths = append(paths, patch.Path)
+	}
+
+	return fmt.Sprintf("module differs from its git destination at %v", paths)
+}
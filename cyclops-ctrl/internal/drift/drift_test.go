@@ -0,0 +1,175 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	apiv1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	cyclopsgit "github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/prometheus"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+func TestDrift(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test drift")
+}
+
+// bareRepo creates a bare repository with a main branch for Modules
+func bareRepo() string {
+	dir := GinkgoT().TempDir()
+
+	source, err := git.PlainInitWithOptions(filepath.Join(dir, "source"), &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(os.WriteFile(filepath.Join(dir, "source", "README.md"), []byte("modules"), 0o600)).To(Succeed())
+
+	worktree, err := source.Worktree()
+	Expect(err).ToNot(HaveOccurred())
+	_, err = worktree.Add("README.md")
+	Expect(err).ToNot(HaveOccurred())
+	_, err = worktree.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "test", When: time.Now()}})
+	Expect(err).ToNot(HaveOccurred())
+
+	remote := filepath.Join(dir, "remote.git")
+	_, err = git.PlainClone(remote, true, &git.CloneOptions{URL: filepath.Join(dir, "source")})
+	Expect(err).ToNot(HaveOccurred())
+
+	return remote
+}
+
+func module(values string) cyclopsv1alpha1.Module {
+	return cyclopsv1alpha1.Module{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo"},
+		Spec: cyclopsv1alpha1.ModuleSpec{
+			TargetNamespace: "default",
+			TemplateRef: cyclopsv1alpha1.TemplateRef{
+				URL:     "https://github.com/cyclops-ui/templates",
+				Path:    "demo",
+				Version: "main",
+			},
+			Values: apiextensionsv1.JSON{Raw: []byte(values)},
+		},
+	}
+}
+
+var _ = Describe("Drift detection", func() {
+	Describe("Compare", func() {
+		It("ignores values formatting", func() {
+			desired := module(`{"image": {"name": "nginx", "tag": "v1"}, "replicas": 3}`)
+			result, err := Compare(module(`{"replicas":3,"image":{"tag":"v1","name":"nginx"}}`), &desired)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Drifted).To(BeFalse())
+
+			desired = module(``)
+			result, err = Compare(module(`null`), &desired)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Drifted).To(BeFalse())
+		})
+
+		It("returns the diff from git to the cluster", func() {
+			desired := module(`{"replicas":3}`)
+			result, err := Compare(module(`{"replicas":5}`), &desired)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Drifted).To(BeTrue())
+			Expect(result.Diff).To(HaveLen(1))
+			Expect(result.Diff[0].Path).To(BeEquivalentTo("/values/replicas"))
+			Expect(result.Diff[0].Value).To(BeEquivalentTo(5))
+		})
+
+		It("reports Modules missing from git", func() {
+			result, err := Compare(module(`{}`), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Drifted).To(BeTrue())
+			Expect(result.Missing).To(BeTrue())
+		})
+	})
+
+	Describe("CheckAll", func() {
+		var k8sClient *mocks.IKubernetesClient
+		var gitClient *cyclopsgit.WriteClient
+		var live cyclopsv1alpha1.Module
+
+		BeforeEach(func() {
+			k8sClient = &mocks.IKubernetesClient{}
+			k8sClient.On("ListTemplateAuthRules").Return([]cyclopsv1alpha1.TemplateAuthRule{
+				{
+					Spec: cyclopsv1alpha1.TemplateAuthRuleSpec{
+						Repo: ".*",
+						Username: apiv1.SecretKeySelector{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: "git"},
+							Key:                  "username",
+						},
+						Password: apiv1.SecretKeySelector{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: "git"},
+							Key:                  "token",
+						},
+					},
+				},
+			}, nil)
+			k8sClient.On("GetTemplateAuthRuleSecret", "git", "username").Return("cyclops", nil)
+			k8sClient.On("GetTemplateAuthRuleSecret", "git", "token").Return("git-token", nil)
+
+			gitClient = cyclopsgit.NewWriteClient(
+				auth.NewTemplatesResolver(k8sClient),
+				"",
+				&cyclopsv1alpha1.GitOpsWriteDestination{Repo: bareRepo(), Path: "modules", Version: "main"},
+				cyclopsgit.PullRequestConfig{},
+				logr.Discard(),
+			)
+
+			_, err := gitClient.Write(module(`{"replicas":3}`))
+			Expect(err).ToNot(HaveOccurred())
+
+			live = module(`{"replicas":5}`)
+			k8sClient.On("ListModules").Return([]cyclopsv1alpha1.Module{live}, nil)
+		})
+
+		driftCondition := func(status metav1.ConditionStatus, reason string) interface{} {
+			return mock.MatchedBy(func(m *cyclopsv1alpha1.Module) bool {
+				condition := meta.FindStatusCondition(m.Status.Conditions, cyclopsv1alpha1.ModuleDriftDetected)
+				return condition != nil && condition.Status == status && condition.Reason == reason
+			})
+		}
+
+		It("sets the DriftDetected condition", func() {
+			k8sClient.On("UpdateModuleStatus", driftCondition(metav1.ConditionTrue, reasonDriftDetected)).Return(&live, nil)
+
+			detector := NewDetector(k8sClient, gitClient, &prometheus.Monitor{}, DefaultInterval, false, logr.Discard())
+			Expect(detector.CheckAll()).To(Succeed())
+
+			k8sClient.AssertExpectations(GinkgoT())
+			k8sClient.AssertNotCalled(GinkgoT(), "UpdateModule", mock.Anything)
+		})
+
+		It("resyncs drifted Modules from git", func() {
+			k8sClient.On("UpdateModule", mock.MatchedBy(func(m *cyclopsv1alpha1.Module) bool {
+				return string(m.Spec.Values.Raw) == `{"replicas":3}`
+			})).Return(nil)
+			k8sClient.On("GetModule", "demo").Return(&live, nil)
+			k8sClient.On("UpdateModuleStatus", driftCondition(metav1.ConditionFalse, reasonResynced)).Return(&live, nil)
+
+			detector := NewDetector(k8sClient, gitClient, &prometheus.Monitor{}, DefaultInterval, true, logr.Discard())
+			Expect(detector.CheckAll()).To(Succeed())
+
+			k8sClient.AssertExpectations(GinkgoT())
+		})
+	})
+})
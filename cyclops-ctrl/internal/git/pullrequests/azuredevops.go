@@ -0,0 +1,70 @@
+package pullrequests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type azureDevOps struct {
+	apiURL string
+	client *http.Client
+}
+
+func (a *azureDevOps) Name() string {
+	return ProviderAzureDevOps
+}
+
+// CreatePullRequest opens a pull request on repositories with
+// https://dev.azure.com/<organization>/<project>/_git/<repository> URLs
+func (a *azureDevOps) CreatePullRequest(repo Repository, request Request, token string) (string, error) {
+	// ssh URLs have a version segment, e.g. v3/<organization>/<project>/<repository>
+	path := repo.Path
+	if len(path) == 4 && path[0] == "v3" {
+		path = []string{path[1], path[2], "_git", path[3]}
+	}
+
+	if len(path) != 4 || path[2] != "_git" {
+		return "", fmt.Errorf("invalid Azure DevOps repository path %v", path)
+	}
+
+	organization, project, repository := path[0], path[1], path[3]
+
+	var response struct {
+		PullRequestID int `json:"pullRequestId"`
+		Repository    struct {
+			WebURL string `json:"webUrl"`
+		} `json:"repository"`
+	}
+
+	err := postJSON(
+		a.client,
+		fmt.Sprintf(
+			"%v/%v/%v/_apis/git/repositories/%v/pullrequests?api-version=7.0",
+			a.apiURL,
+			url.PathEscape(organization),
+			url.PathEscape(project),
+			url.PathEscape(repository),
+		),
+		map[string]string{
+			"title":         request.Title,
+			"description":   request.Body,
+			"sourceRefName": "refs/heads/" + request.Head,
+			"targetRefName": "refs/heads/" + request.Base,
+		},
+		func(req *http.Request) {
+			// personal access tokens are sent as the basic auth password
+			req.SetBasicAuth("", token)
+		},
+		&response,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.Repository.WebURL) == 0 {
+		return "", errNoURL
+	}
+
+	return fmt.Sprintf("%v/pullrequest/%v", response.Repository.WebURL, response.PullRequestID), nil
+}
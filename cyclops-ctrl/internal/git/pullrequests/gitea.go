@@ -0,0 +1,46 @@
+package pullrequests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type gitea struct {
+	apiURL string
+	client *http.Client
+}
+
+func (g *gitea) Name() string {
+	return ProviderGitea
+}
+
+func (g *gitea) CreatePullRequest(repo Repository, request Request, token string) (string, error) {
+	var response struct {
+		HTMLURL string `json:"html_url"`
+	}
+
+	err := postJSON(
+		g.client,
+		fmt.Sprintf("%v/repos/%v/pulls", g.apiURL, strings.Join(repo.Path, "/")),
+		map[string]string{
+			"title": request.Title,
+			"body":  request.Body,
+			"head":  request.Head,
+			"base":  request.Base,
+		},
+		func(req *http.Request) {
+			req.Header.Set("Authorization", fmt.Sprintf("token %v", token))
+		},
+		&response,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.HTMLURL) == 0 {
+		return "", errNoURL
+	}
+
+	return response.HTMLURL, nil
+}
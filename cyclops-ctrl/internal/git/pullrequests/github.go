@@ -0,0 +1,47 @@
+package pullrequests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type gitHub struct {
+	apiURL string
+	client *http.Client
+}
+
+func (g *gitHub) Name() string {
+	return ProviderGitHub
+}
+
+func (g *gitHub) CreatePullRequest(repo Repository, request Request, token string) (string, error) {
+	var response struct {
+		HTMLURL string `json:"html_url"`
+	}
+
+	err := postJSON(
+		g.client,
+		fmt.Sprintf("%v/repos/%v/pulls", g.apiURL, strings.Join(repo.Path, "/")),
+		map[string]string{
+			"title": request.Title,
+			"body":  request.Body,
+			"head":  request.Head,
+			"base":  request.Base,
+		},
+		func(req *http.Request) {
+			req.Header.Set("Accept", "application/vnd.github+json")
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
+		},
+		&response,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.HTMLURL) == 0 {
+		return "", errNoURL
+	}
+
+	return response.HTMLURL, nil
+}
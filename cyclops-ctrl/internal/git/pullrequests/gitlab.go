@@ -0,0 +1,49 @@
+package pullrequests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type gitLab struct {
+	apiURL string
+	client *http.Client
+}
+
+func (g *gitLab) Name() string {
+	return ProviderGitLab
+}
+
+// CreatePullRequest opens a merge request. GitLab projects can be nested in
+// subgroups, so the project is identified by its URL encoded path.
+func (g *gitLab) CreatePullRequest(repo Repository, request Request, token string) (string, error) {
+	var response struct {
+		WebURL string `json:"web_url"`
+	}
+
+	err := postJSON(
+		g.client,
+		fmt.Sprintf("%v/projects/%v/merge_requests", g.apiURL, url.PathEscape(strings.Join(repo.Path, "/"))),
+		map[string]string{
+			"title":         request.Title,
+			"description":   request.Body,
+			"source_branch": request.Head,
+			"target_branch": request.Base,
+		},
+		func(req *http.Request) {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
+		},
+		&response,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.WebURL) == 0 {
+		return "", errNoURL
+	}
+
+	return response.WebURL, nil
+}
@@ -0,0 +1,183 @@
+package pullrequests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderGitHub      = "github"
+	ProviderGitLab      = "gitlab"
+	ProviderAzureDevOps = "azuredevops"
+	ProviderGitea       = "gitea"
+)
+
+var errNoURL = errors.New("pull request created but the response has no URL")
+
+// Request describes a pull request from the Head branch to the Base branch
+type Request struct {
+	Title string
+	Body  string
+	Head  string
+	Base  string
+}
+
+// Provider opens pull requests on a git hosting service and returns their URL
+type Provider interface {
+	Name() string
+	CreatePullRequest(repo Repository, request Request, token string) (string, error)
+}
+
+type Config struct {
+	// Provider is detected from the repository host if not set
+	Provider string
+	// APIURL defaults to the API of the provider host, e.g. https://api.github.com
+	APIURL string
+}
+
+// Repository is a git repository on a hosting service. Path holds the path
+// segments of the repository, e.g. [my-org my-repo] for GitHub or
+// [my-org my-project _git my-repo] for Azure DevOps.
+type Repository struct {
+	Host string
+	Path []string
+}
+
+// New returns the provider of the repository
+func New(config Config, repoURL string) (Provider, Repository, error) {
+	repo, err := ParseRepository(repoURL)
+	if err != nil {
+		return nil, Repository{}, err
+	}
+
+	provider := config.Provider
+	if len(provider) == 0 {
+		provider = detectProvider(repo.Host)
+	}
+
+	apiURL := strings.TrimSuffix(config.APIURL, "/")
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	switch provider {
+	case ProviderGitHub:
+		if len(apiURL) == 0 {
+			apiURL = "https://api.github.com"
+			if repo.Host != "github.com" {
+				apiURL = fmt.Sprintf("https://%v/api/v3", repo.Host)
+			}
+		}
+		return &gitHub{apiURL: apiURL, client: client}, repo, nil
+	case ProviderGitLab:
+		if len(apiURL) == 0 {
+			apiURL = fmt.Sprintf("https://%v/api/v4", repo.Host)
+		}
+		return &gitLab{apiURL: apiURL, client: client}, repo, nil
+	case ProviderAzureDevOps:
+		if len(apiURL) == 0 {
+			apiURL = fmt.Sprintf("https://%v", strings.TrimPrefix(repo.Host, "ssh."))
+		}
+		return &azureDevOps{apiURL: apiURL, client: client}, repo, nil
+	case ProviderGitea:
+		if len(apiURL) == 0 {
+			apiURL = fmt.Sprintf("https://%v/api/v1", repo.Host)
+		}
+		return &gitea{apiURL: apiURL, client: client}, repo, nil
+	case "":
+		return nil, Repository{}, fmt.Errorf("could not detect pull request provider of %v; set it in GITOPS_PR_PROVIDER", repo.Host)
+	}
+
+	return nil, Repository{}, fmt.Errorf("unknown pull request provider %v", provider)
+}
+
+func detectProvider(host string) string {
+	switch {
+	case host == "github.com":
+		return ProviderGitHub
+	case host == "gitlab.com":
+		return ProviderGitLab
+	case host == "dev.azure.com" || host == "ssh.dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com"):
+		return ProviderAzureDevOps
+	}
+
+	return ""
+}
+
+// ParseRepository parses https, ssh and scp-like git repository URLs
+func ParseRepository(repoURL string) (Repository, error) {
+	var host, path string
+
+	if strings.Contains(repoURL, "://") {
+		u, err := url.Parse(repoURL)
+		if err != nil {
+			return Repository{}, err
+		}
+
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax, e.g. git@github.com:my-org/my-repo.git
+		userHost, p, ok := strings.Cut(repoURL, ":")
+		if !ok {
+			return Repository{}, fmt.Errorf("invalid repository URL %v", repoURL)
+		}
+
+		_, h, found := strings.Cut(userHost, "@")
+		if !found {
+			h = userHost
+		}
+
+		host, path = h, p
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	segments := strings.Split(path, "/")
+	if len(host) == 0 || len(segments) < 2 {
+		return Repository{}, fmt.Errorf("invalid repository URL %v", repoURL)
+	}
+
+	return Repository{
+		Host: host,
+		Path: segments,
+	}, nil
+}
+
+// postJSON sends the body to the URL and decodes the response into response
+func postJSON(client *http.Client, url string, body interface{}, authorize func(req *http.Request), response interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	authorize(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("request to %v failed with status %v: %v", url, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return json.Unmarshal(respBody, response)
+}
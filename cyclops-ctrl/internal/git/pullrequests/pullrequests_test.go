@@ -0,0 +1,168 @@
+package pullrequests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPullRequests(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test pull requests")
+}
+
+// recordedRequest is a request received by the provider stand-in
+type recordedRequest struct {
+	Method        string
+	Path          string
+	Authorization string
+	Body          map[string]string
+}
+
+var _ = Describe("Pull requests test", func() {
+	var server *httptest.Server
+	var recorded recordedRequest
+	var response string
+
+	request := Request{
+		Title: "Update demo module config",
+		Body:  "Update demo module config",
+		Head:  "cyclops/demo-1700000000",
+		Base:  "main",
+	}
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorded = recordedRequest{
+				Method:        r.Method,
+				Path:          r.URL.EscapedPath(),
+				Authorization: r.Header.Get("Authorization"),
+			}
+			Expect(json.NewDecoder(r.Body).Decode(&recorded.Body)).To(Succeed())
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(response))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("ParseRepository", func() {
+		It("parses https, ssh and scp-like URLs", func() {
+			for _, repoURL := range []string{
+				"https://github.com/my-org/my-repo",
+				"https://github.com/my-org/my-repo.git",
+				"ssh://git@github.com/my-org/my-repo.git",
+				"git@github.com:my-org/my-repo.git",
+			} {
+				repo, err := ParseRepository(repoURL)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(repo).To(BeEquivalentTo(Repository{Host: "github.com", Path: []string{"my-org", "my-repo"}}))
+			}
+		})
+
+		It("fails for URLs without a repository path", func() {
+			_, err := ParseRepository("https://github.com/my-org")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("New", func() {
+		It("detects providers from the repository host", func() {
+			provider, _, err := New(Config{}, "https://dev.azure.com/my-org/my-project/_git/my-repo")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(provider.Name()).To(BeEquivalentTo(ProviderAzureDevOps))
+		})
+
+		It("requires a provider for unknown hosts", func() {
+			_, _, err := New(Config{}, "https://git.my-org.com/my-org/my-repo")
+			Expect(err).To(HaveOccurred())
+
+			provider, _, err := New(Config{Provider: ProviderGitea}, "https://git.my-org.com/my-org/my-repo")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(provider.Name()).To(BeEquivalentTo(ProviderGitea))
+		})
+	})
+
+	It("opens GitHub pull requests", func() {
+		response = `{"html_url":"https://github.com/my-org/my-repo/pull/1"}`
+
+		provider, repo, err := New(Config{APIURL: server.URL}, "https://github.com/my-org/my-repo")
+		Expect(err).ToNot(HaveOccurred())
+
+		url, err := provider.CreatePullRequest(repo, request, "gh-token")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(url).To(BeEquivalentTo("https://github.com/my-org/my-repo/pull/1"))
+
+		Expect(recorded.Method).To(BeEquivalentTo(http.MethodPost))
+		Expect(recorded.Path).To(BeEquivalentTo("/repos/my-org/my-repo/pulls"))
+		Expect(recorded.Authorization).To(BeEquivalentTo("Bearer gh-token"))
+		Expect(recorded.Body).To(HaveKeyWithValue("head", "cyclops/demo-1700000000"))
+		Expect(recorded.Body).To(HaveKeyWithValue("base", "main"))
+	})
+
+	It("opens GitLab merge requests for projects in subgroups", func() {
+		response = `{"web_url":"https://gitlab.com/my-org/team/my-repo/-/merge_requests/1"}`
+
+		provider, repo, err := New(Config{APIURL: server.URL}, "https://gitlab.com/my-org/team/my-repo.git")
+		Expect(err).ToNot(HaveOccurred())
+
+		url, err := provider.CreatePullRequest(repo, request, "gl-token")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(url).To(BeEquivalentTo("https://gitlab.com/my-org/team/my-repo/-/merge_requests/1"))
+
+		Expect(recorded.Path).To(BeEquivalentTo("/projects/my-org%2Fteam%2Fmy-repo/merge_requests"))
+		Expect(recorded.Authorization).To(BeEquivalentTo("Bearer gl-token"))
+		Expect(recorded.Body).To(HaveKeyWithValue("source_branch", "cyclops/demo-1700000000"))
+		Expect(recorded.Body).To(HaveKeyWithValue("target_branch", "main"))
+	})
+
+	It("opens Azure DevOps pull requests", func() {
+		response = `{"pullRequestId":7,"repository":{"webUrl":"https://dev.azure.com/my-org/my-project/_git/my-repo"}}`
+
+		provider, repo, err := New(Config{APIURL: server.URL}, "git@ssh.dev.azure.com:v3/my-org/my-project/my-repo")
+		Expect(err).ToNot(HaveOccurred())
+
+		url, err := provider.CreatePullRequest(repo, request, "ado-token")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(url).To(BeEquivalentTo("https://dev.azure.com/my-org/my-project/_git/my-repo/pullrequest/7"))
+
+		Expect(recorded.Path).To(BeEquivalentTo("/my-org/my-project/_apis/git/repositories/my-repo/pullrequests"))
+		Expect(recorded.Authorization).To(HavePrefix("Basic "))
+		Expect(recorded.Body).To(HaveKeyWithValue("sourceRefName", "refs/heads/cyclops/demo-1700000000"))
+		Expect(recorded.Body).To(HaveKeyWithValue("targetRefName", "refs/heads/main"))
+	})
+
+	It("opens Gitea pull requests", func() {
+		response = `{"html_url":"https://git.my-org.com/my-org/my-repo/pulls/1"}`
+
+		provider, repo, err := New(Config{Provider: ProviderGitea, APIURL: server.URL}, "https://git.my-org.com/my-org/my-repo")
+		Expect(err).ToNot(HaveOccurred())
+
+		url, err := provider.CreatePullRequest(repo, request, "gitea-token")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(url).To(BeEquivalentTo("https://git.my-org.com/my-org/my-repo/pulls/1"))
+
+		Expect(recorded.Path).To(BeEquivalentTo("/repos/my-org/my-repo/pulls"))
+		Expect(recorded.Authorization).To(BeEquivalentTo("token gitea-token"))
+	})
+
+	It("returns provider errors", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"A pull request already exists"}`))
+		})
+
+		provider, repo, err := New(Config{APIURL: server.URL}, "https://github.com/my-org/my-repo")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = provider.CreatePullRequest(repo, request, "gh-token")
+		Expect(err).To(MatchError(ContainSubstring("A pull request already exists")))
+	})
+})
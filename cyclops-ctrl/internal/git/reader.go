@@ -0,0 +1,93 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"sigs.k8s.io/yaml"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// Reader reads Modules from their git destinations. Each repository revision
+// is cloned once per Reader, so a Reader should not outlive a single check.
+type Reader struct {
+	client *WriteClient
+	repos  map[string]billy.Filesystem
+}
+
+func (c *WriteClient) NewReader() *Reader {
+	return &Reader{
+		client: c,
+		repos:  make(map[string]billy.Filesystem),
+	}
+}
+
+// ReadModule returns the Module committed to its git destination, or nil if
+// there is no file for the Module in the repository
+func (r *Reader) ReadModule(module cyclopsv1alpha1.Module) (*cyclopsv1alpha1.Module, error) {
+	destination := r.client.Destination(module)
+	if destination == nil {
+		return nil, errors.New(fmt.Sprintf("module %v has no git destination", module.Name))
+	}
+
+	fs, err := r.repo(*destination)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := getModulePath(destination.Path, module)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fs.Open(moduleFilePath(path, module.Name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var committed cyclopsv1alpha1.Module
+	if err := yaml.Unmarshal(data, &committed); err != nil {
+		return nil, fmt.Errorf("failed to parse module %v from git: %w", module.Name, err)
+	}
+
+	return &committed, nil
+}
+
+func (r *Reader) repo(destination cyclopsv1alpha1.GitOpsWriteDestination) (billy.Filesystem, error) {
+	key := fmt.Sprintf("%v@%v", destination.Repo, destination.Version)
+	if fs, ok := r.repos[key]; ok {
+		return fs, nil
+	}
+
+	creds, err := r.client.templatesResolver.RepoAuthCredentials(destination.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if creds == nil {
+		return nil, errors.New(fmt.Sprintf("failed to fetch creds for repo %v: check template auth rules", destination.Repo))
+	}
+
+	fs := memfs.New()
+	if _, _, err := cloneRepo(destination.Repo, destination.Version, memory.NewStorage(), &fs, creds); err != nil {
+		return nil, err
+	}
+
+	r.repos[key] = fs
+	return fs, nil
+}
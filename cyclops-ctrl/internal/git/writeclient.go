@@ -6,6 +6,7 @@ import (
 	"fmt"
 	json "github.com/json-iterator/go"
 	path2 "path"
+	"strings"
 	"text/template"
 	"time"
 
@@ -14,30 +15,94 @@ import (
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
 	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git/pullrequests"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
 )
 
 type WriteClient struct {
 	templatesResolver     auth.TemplatesResolver
 	commitMessageTemplate *template.Template
+	destination           *cyclopsv1alpha1.GitOpsWriteDestination
+	pullRequests          PullRequestConfig
 }
 
-const _defaultCommitMessageTemplate = "Update {{ .Name }} module config"
+// PullRequestConfig configures opening pull requests instead of pushing to the
+// write revision
+type PullRequestConfig struct {
+	// Enabled opens pull requests for all Modules. Modules override it with the
+	// cyclops-ui.com/write-mode annotation.
+	Enabled bool
+
+	pullrequests.Config
+}
+
+const (
+	_defaultCommitMessageTemplate = "Update {{ .Name }} module config"
+
+	// templateStoresDir is the directory of TemplateStores in the write path
+	templateStoresDir = "templatestores"
+)
+
+// NewWriteClient returns a client writing to git repositories. Modules without
+// the cyclops-ui.com/write-repo annotation and TemplateStores are written to
+// the destination if it is set.
+func NewWriteClient(
+	templatesResolver auth.TemplatesResolver,
+	commitMessageTemplate string,
+	destination *cyclopsv1alpha1.GitOpsWriteDestination,
+	pullRequests PullRequestConfig,
+	logger logr.Logger,
+) *WriteClient {
+	if destination != nil && len(destination.Repo) == 0 {
+		destination = nil
+	}
 
-func NewWriteClient(templatesResolver auth.TemplatesResolver, commitMessageTemplate string, logger logr.Logger) *WriteClient {
 	return &WriteClient{
 		templatesResolver:     templatesResolver,
 		commitMessageTemplate: getCommitMessageTemplate(commitMessageTemplate, logger),
+		destination:           destination,
+		pullRequests:          pullRequests,
 	}
 }
 
+// Destination returns the git destination of the Module, or nil if the Module
+// is applied to the cluster directly
+func (c *WriteClient) Destination(module cyclopsv1alpha1.Module) *cyclopsv1alpha1.GitOpsWriteDestination {
+	if c == nil {
+		return nil
+	}
+
+	annotations := module.GetAnnotations()
+	if len(annotations[cyclopsv1alpha1.GitOpsWriteRepoAnnotation]) != 0 {
+		return &cyclopsv1alpha1.GitOpsWriteDestination{
+			Repo:    annotations[cyclopsv1alpha1.GitOpsWriteRepoAnnotation],
+			Path:    annotations[cyclopsv1alpha1.GitOpsWritePathAnnotation],
+			Version: annotations[cyclopsv1alpha1.GitOpsWriteRevisionAnnotation],
+		}
+	}
+
+	return c.DefaultDestination()
+}
+
+// DefaultDestination returns the destination configured for all Modules and
+// TemplateStores, or nil if there is none
+func (c *WriteClient) DefaultDestination() *cyclopsv1alpha1.GitOpsWriteDestination {
+	if c == nil || c.destination == nil {
+		return nil
+	}
+
+	destination := *c.destination
+	return &destination
+}
+
 func getCommitMessageTemplate(commitMessageTemplate string, logger logr.Logger) *template.Template {
 	if commitMessageTemplate == "" {
 		return template.Must(template.New("commitMessage").Parse(_defaultCommitMessageTemplate))
@@ -52,9 +117,7 @@ func getCommitMessageTemplate(commitMessageTemplate string, logger logr.Logger)
 	return tmpl
 }
 
-func getModulePath(module cyclopsv1alpha1.Module) (string, error) {
-	path := module.GetAnnotations()[cyclopsv1alpha1.GitOpsWritePathAnnotation]
-
+func getModulePath(path string, module cyclopsv1alpha1.Module) (string, error) {
 	tmpl, err := template.New("modulePath").Parse(path)
 	if err != nil {
 		return "", err
@@ -78,120 +141,153 @@ func getModulePath(module cyclopsv1alpha1.Module) (string, error) {
 	return o.String(), nil
 }
 
-func (c *WriteClient) Write(module cyclopsv1alpha1.Module) error {
+// Write commits the Module to its write repository. In pull request mode the
+// URL of the opened pull request is returned.
+func (c *WriteClient) Write(module cyclopsv1alpha1.Module) (string, error) {
+	module.ObjectMeta = gitObjectMeta(module.ObjectMeta)
+	module.History = nil
 	module.Status.ReconciliationStatus = nil
 	module.Status.ManagedGVRs = nil
+	module.Status.PrunedResources = nil
+	module.Status.ObservedGeneration = 0
+	module.Status.Conditions = nil
 
-	repoURL, exists := module.GetAnnotations()[cyclopsv1alpha1.GitOpsWriteRepoAnnotation]
-	if !exists {
-		return errors.New(fmt.Sprintf("module passed to write without git repository; set cyclops-ui.com/write-repo annotation in module %v", module.Name))
+	destination := c.Destination(module)
+	if destination == nil {
+		return "", errors.New(fmt.Sprintf("module passed to write without git repository; set cyclops-ui.com/write-repo annotation in module %v", module.Name))
 	}
 
-	path, err := getModulePath(module)
+	path, err := getModulePath(destination.Path, module)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	revision := module.GetAnnotations()[cyclopsv1alpha1.GitOpsWriteRevisionAnnotation]
+	return c.commit(module.ObjectMeta, *destination, writeFile(moduleFilePath(path, module.Name), module))
+}
 
-	creds, err := c.templatesResolver.RepoAuthCredentials(repoURL)
+// DeleteModule removes the Module from its write repository. In pull request
+// mode the URL of the opened pull request is returned.
+func (c *WriteClient) DeleteModule(module cyclopsv1alpha1.Module) (string, error) {
+	destination := c.Destination(module)
+	if destination == nil {
+		return "", errors.New(fmt.Sprintf("module passed to delete without git repository; set cyclops-ui.com/write-repo annotation in module %v", module.Name))
+	}
+
+	path, err := getModulePath(destination.Path, module)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if creds == nil {
-		return errors.New(fmt.Sprintf("failed to fetch creds for repo %v: check template auth rules", repoURL))
+	return c.commit(module.ObjectMeta, *destination, removeFile(moduleFilePath(path, module.Name)))
+}
+
+// WriteTemplateStore commits the TemplateStore to the templatestores directory
+// of the default destination
+func (c *WriteClient) WriteTemplateStore(templateStore cyclopsv1alpha1.TemplateStore) (string, error) {
+	templateStore.ObjectMeta = gitObjectMeta(templateStore.ObjectMeta)
+
+	destination := c.DefaultDestination()
+	if destination == nil {
+		return "", errors.New("template store passed to write without git repository")
 	}
 
-	storer := memory.NewStorage()
-	fs := memfs.New()
+	return c.commit(templateStore.ObjectMeta, *destination, writeFile(templateStoreFilePath(destination.Path, templateStore.Name), templateStore))
+}
 
-	repo, worktree, err := cloneRepo(repoURL, revision, storer, &fs, creds)
-	if err != nil {
-		if errors.Is(err, git.NoMatchingRefSpecError{}) {
-			storer = memory.NewStorage()
-			fs = memfs.New()
-			repo, worktree, err = cloneRepo(repoURL, "", storer, &fs, creds)
-			if err != nil {
-				return err
-			}
+// DeleteTemplateStore removes the TemplateStore from the default destination
+func (c *WriteClient) DeleteTemplateStore(name string) (string, error) {
+	destination := c.DefaultDestination()
+	if destination == nil {
+		return "", errors.New("template store passed to delete without git repository")
+	}
 
-			err = worktree.Checkout(&git.CheckoutOptions{
-				Branch: plumbing.NewBranchReferenceName(revision),
-				Create: true,
-			})
-			if err != nil {
-				return err
-			}
-		} else {
+	return c.commit(metav1.ObjectMeta{Name: name}, *destination, removeFile(templateStoreFilePath(destination.Path, name)))
+}
+
+func writeFile(path string, obj interface{}) func(fs billy.Filesystem, worktree *git.Worktree) error {
+	return func(fs billy.Filesystem, worktree *git.Worktree) error {
+		file, err := fs.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create file in repository: %w", err)
+		}
+
+		data, err := yaml.Marshal(obj)
+		if err != nil {
 			return err
 		}
-	}
 
-	path = moduleFilePath(path, module.Name)
+		if _, err := file.Write(data); err != nil {
+			return fmt.Errorf("failed to write JSON data to file: %w", err)
+		}
+		file.Close()
 
-	file, err := fs.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create file in repository: %w", err)
-	}
+		if _, err := worktree.Add(path); err != nil {
+			return fmt.Errorf("failed to add file to worktree: %w", err)
+		}
 
-	moduleData, err := yaml.Marshal(module)
-	if err != nil {
-		return err
+		return nil
 	}
+}
 
-	if _, err := file.Write(moduleData); err != nil {
-		return fmt.Errorf("failed to write JSON data to file: %w", err)
-	}
-	file.Close()
+func removeFile(path string) func(fs billy.Filesystem, worktree *git.Worktree) error {
+	return func(fs billy.Filesystem, worktree *git.Worktree) error {
+		if err := fs.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove file from repository: %w", err)
+		}
 
-	if _, err := worktree.Add(path); err != nil {
-		fmt.Println("err worktree.Add", path)
-		return fmt.Errorf("failed to add file to worktree: %w", err)
-	}
+		if _, err := worktree.Add(path); err != nil {
+			return fmt.Errorf("failed to add changes to worktree: %w", err)
+		}
 
-	var o bytes.Buffer
-	err = c.commitMessageTemplate.Execute(&o, module.ObjectMeta)
-	if err != nil {
-		return err
+		return nil
 	}
+}
 
-	_, err = worktree.Commit(o.String(), &git.CommitOptions{
-		Author: &object.Signature{
-			Name: creds.Username,
-			When: time.Now(),
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to commit changes: %w", err)
+// gitObjectMeta drops the fields set by the cluster from the object metadata
+func gitObjectMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	annotations := make(map[string]string, len(meta.Annotations))
+	for k, v := range meta.Annotations {
+		annotations[k] = v
 	}
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
 
-	if err := repo.Push(&git.PushOptions{
-		Auth: httpBasicAuthCredentials(creds),
-	}); err != nil {
-		return fmt.Errorf("failed to push changes: %w", err)
+	if len(annotations) == 0 {
+		annotations = nil
 	}
 
-	return nil
-}
-
-func (c *WriteClient) DeleteModule(module cyclopsv1alpha1.Module) error {
-	repoURL, exists := module.GetAnnotations()[cyclopsv1alpha1.GitOpsWriteRepoAnnotation]
-	if !exists {
-		return errors.New(fmt.Sprintf("module passed to delete without git repository; set cyclops-ui.com/write-repo annotation in module %v", module.Name))
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: annotations,
 	}
+}
 
-	path := module.GetAnnotations()[cyclopsv1alpha1.GitOpsWritePathAnnotation]
-	revision := module.GetAnnotations()[cyclopsv1alpha1.GitOpsWriteRevisionAnnotation]
+// commit applies the change to the revision of the destination and commits it.
+// The commit is pushed to the revision, or in pull request mode to a new branch
+// with a pull request to the revision.
+func (c *WriteClient) commit(
+	meta metav1.ObjectMeta,
+	destination cyclopsv1alpha1.GitOpsWriteDestination,
+	change func(fs billy.Filesystem, worktree *git.Worktree) error,
+) (string, error) {
+	repoURL, revision := destination.Repo, destination.Version
 
 	creds, err := c.templatesResolver.RepoAuthCredentials(repoURL)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	if creds == nil {
+		return "", errors.New(fmt.Sprintf("failed to fetch creds for repo %v: check template auth rules", repoURL))
 	}
 
 	storer := memory.NewStorage()
 	fs := memfs.New()
 
+	// refSpecs of the branches to push
+	refSpecs := make([]config.RefSpec, 0, 2)
+
 	repo, worktree, err := cloneRepo(repoURL, revision, storer, &fs, creds)
 	if err != nil {
 		if errors.Is(err, git.NoMatchingRefSpecError{}) {
@@ -199,7 +295,7 @@ func (c *WriteClient) DeleteModule(module cyclopsv1alpha1.Module) error {
 			fs = memfs.New()
 			repo, worktree, err = cloneRepo(repoURL, "", storer, &fs, creds)
 			if err != nil {
-				return err
+				return "", err
 			}
 
 			err = worktree.Checkout(&git.CheckoutOptions{
@@ -207,28 +303,46 @@ func (c *WriteClient) DeleteModule(module cyclopsv1alpha1.Module) error {
 				Create: true,
 			})
 			if err != nil {
-				return err
+				return "", err
 			}
+
+			refSpecs = append(refSpecs, branchRefSpec(revision))
 		} else {
-			return err
+			return "", err
 		}
 	}
 
-	path = moduleFilePath(path, module.Name)
+	pullRequest := c.pullRequestMode(meta)
 
-	err = fs.Remove(path)
-	if err != nil {
-		return fmt.Errorf("failed to remove file from repository: %w", err)
+	var base, branch string
+	if pullRequest {
+		head, err := repo.Head()
+		if err != nil {
+			return "", err
+		}
+
+		base = head.Name().Short()
+		branch = fmt.Sprintf("cyclops/%v-%v", meta.Name, time.Now().Unix())
+
+		err = worktree.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(branch),
+			Create: true,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		refSpecs = append(refSpecs, branchRefSpec(branch))
 	}
 
-	if _, err := worktree.Add(path); err != nil {
-		return fmt.Errorf("failed to add changes to worktree: %w", err)
+	if err := change(fs, worktree); err != nil {
+		return "", err
 	}
 
 	var o bytes.Buffer
-	err = c.commitMessageTemplate.Execute(&o, module.ObjectMeta)
+	err = c.commitMessageTemplate.Execute(&o, meta)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	_, err = worktree.Commit(o.String(), &git.CommitOptions{
@@ -238,16 +352,71 @@ func (c *WriteClient) DeleteModule(module cyclopsv1alpha1.Module) error {
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to commit changes: %w", err)
+		return "", fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	pushOptions := &git.PushOptions{
+		Auth: creds.GitAuth(),
+	}
+	if pullRequest {
+		pushOptions.RefSpecs = refSpecs
 	}
 
-	if err := repo.Push(&git.PushOptions{
-		Auth: httpBasicAuthCredentials(creds),
-	}); err != nil {
-		return fmt.Errorf("failed to push changes: %w", err)
+	if err := repo.Push(pushOptions); err != nil {
+		return "", fmt.Errorf("failed to push changes: %w", err)
 	}
 
-	return nil
+	if !pullRequest {
+		return "", nil
+	}
+
+	message := o.String()
+	title, _, _ := strings.Cut(message, "\n")
+
+	return c.openPullRequest(repoURL, creds, pullrequests.Request{
+		Title: title,
+		Body:  message,
+		Head:  branch,
+		Base:  base,
+	})
+}
+
+func (c *WriteClient) pullRequestMode(meta metav1.ObjectMeta) bool {
+	switch meta.GetAnnotations()[cyclopsv1alpha1.GitOpsWriteModeAnnotation] {
+	case cyclopsv1alpha1.GitOpsWriteModePullRequest:
+		return true
+	case cyclopsv1alpha1.GitOpsWriteModePush:
+		return false
+	}
+
+	return c.pullRequests.Enabled
+}
+
+func (c *WriteClient) openPullRequest(repoURL string, creds *auth.Credentials, request pullrequests.Request) (string, error) {
+	provider, repo, err := pullrequests.New(c.pullRequests.Config, repoURL)
+	if err != nil {
+		return "", err
+	}
+
+	token := creds.Token
+	if len(token) == 0 {
+		token = creds.Password
+	}
+
+	if len(token) == 0 {
+		return "", fmt.Errorf("pushed branch %v but can not open a pull request: template auth rule of %v has no token", request.Head, repoURL)
+	}
+
+	url, err := provider.CreatePullRequest(repo, request, token)
+	if err != nil {
+		return "", fmt.Errorf("pushed branch %v but failed to open %v pull request: %w", request.Head, provider.Name(), err)
+	}
+
+	return url, nil
+}
+
+func branchRefSpec(branch string) config.RefSpec {
+	return config.RefSpec(fmt.Sprintf("refs/heads/%[1]v:refs/heads/%[1]v", branch))
 }
 
 func moduleFilePath(path, moduleName string) string {
@@ -258,10 +427,14 @@ func moduleFilePath(path, moduleName string) string {
 	return path
 }
 
+func templateStoreFilePath(path, name string) string {
+	return path2.Join(path, templateStoresDir, fmt.Sprintf("%v.yaml", name))
+}
+
 func cloneRepo(url, revision string, storer *memory.Storage, fs *billy.Filesystem, creds *auth.Credentials) (*git.Repository, *git.Worktree, error) {
 	cloneOpts := git.CloneOptions{
 		URL:          url,
-		Auth:         httpBasicAuthCredentials(creds),
+		Auth:         creds.GitAuth(),
 		SingleBranch: true,
 	}
 
@@ -281,14 +454,3 @@ func cloneRepo(url, revision string, storer *memory.Storage, fs *billy.Filesyste
 
 	return repo, worktree, nil
 }
-
-func httpBasicAuthCredentials(creds *auth.Credentials) *http.BasicAuth {
-	if creds == nil {
-		return nil
-	}
-
-	return &http.BasicAuth{
-		Username: creds.Username,
-		Password: creds.Password,
-	}
-}
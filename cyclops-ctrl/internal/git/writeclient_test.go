@@ -0,0 +1,229 @@
+package git
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git/pullrequests"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+func TestWriteClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test git write client")
+}
+
+// bareRepo creates a bare repository with a main branch to write to
+func bareRepo() string {
+	dir := GinkgoT().TempDir()
+
+	source, err := git.PlainInitWithOptions(filepath.Join(dir, "source"), &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(os.WriteFile(filepath.Join(dir, "source", "README.md"), []byte("modules"), 0o600)).To(Succeed())
+
+	worktree, err := source.Worktree()
+	Expect(err).ToNot(HaveOccurred())
+	_, err = worktree.Add("README.md")
+	Expect(err).ToNot(HaveOccurred())
+	_, err = worktree.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "test", When: time.Now()}})
+	Expect(err).ToNot(HaveOccurred())
+
+	remote := filepath.Join(dir, "remote.git")
+	_, err = git.PlainClone(remote, true, &git.CloneOptions{URL: filepath.Join(dir, "source")})
+	Expect(err).ToNot(HaveOccurred())
+
+	return remote
+}
+
+func branchFile(repoPath, branch, path string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return "", err
+	}
+
+	file, err := commit.File(path)
+	if err != nil {
+		return "", err
+	}
+
+	return file.Contents()
+}
+
+var _ = Describe("Write client", func() {
+	var remote string
+	var module cyclopsv1alpha1.Module
+	var k8sClient *mocks.IKubernetesClient
+
+	BeforeEach(func() {
+		remote = bareRepo()
+
+		k8sClient = &mocks.IKubernetesClient{}
+		k8sClient.On("ListTemplateAuthRules").Return([]cyclopsv1alpha1.TemplateAuthRule{
+			{
+				Spec: cyclopsv1alpha1.TemplateAuthRuleSpec{
+					Repo: ".*",
+					Username: apiv1.SecretKeySelector{
+						LocalObjectReference: apiv1.LocalObjectReference{Name: "git"},
+						Key:                  "username",
+					},
+					Password: apiv1.SecretKeySelector{
+						LocalObjectReference: apiv1.LocalObjectReference{Name: "git"},
+						Key:                  "token",
+					},
+				},
+			},
+		}, nil)
+		k8sClient.On("GetTemplateAuthRuleSecret", "git", "username").Return("cyclops", nil)
+		k8sClient.On("GetTemplateAuthRuleSecret", "git", "token").Return("git-token", nil)
+
+		module = cyclopsv1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "demo",
+				Annotations: map[string]string{
+					cyclopsv1alpha1.GitOpsWriteRepoAnnotation:     remote,
+					cyclopsv1alpha1.GitOpsWritePathAnnotation:     "modules",
+					cyclopsv1alpha1.GitOpsWriteRevisionAnnotation: "main",
+				},
+			},
+		}
+	})
+
+	It("pushes Modules to the write revision", func() {
+		client := NewWriteClient(auth.NewTemplatesResolver(k8sClient), "", nil, PullRequestConfig{}, logr.Discard())
+
+		pullRequestURL, err := client.Write(module)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pullRequestURL).To(BeEmpty())
+
+		content, err := branchFile(remote, "main", "modules/demo.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(ContainSubstring("name: demo"))
+	})
+
+	It("opens pull requests in pull request mode", func() {
+		var body map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(BeEquivalentTo("Bearer git-token"))
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"html_url":"https://github.com/my-org/modules/pull/1"}`))
+		}))
+		defer server.Close()
+
+		// pull request providers need a repository host
+		module.Annotations[cyclopsv1alpha1.GitOpsWriteRepoAnnotation] = "file://localhost" + remote
+		module.Annotations[cyclopsv1alpha1.GitOpsWriteModeAnnotation] = cyclopsv1alpha1.GitOpsWriteModePullRequest
+
+		client := NewWriteClient(
+			auth.NewTemplatesResolver(k8sClient),
+			"Update {{ .Name }} from Cyclops",
+			nil,
+			PullRequestConfig{
+				Config: pullrequests.Config{Provider: pullrequests.ProviderGitHub, APIURL: server.URL},
+			},
+			logr.Discard(),
+		)
+
+		pullRequestURL, err := client.Write(module)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pullRequestURL).To(BeEquivalentTo("https://github.com/my-org/modules/pull/1"))
+
+		Expect(body).To(HaveKeyWithValue("title", "Update demo from Cyclops"))
+		Expect(body).To(HaveKeyWithValue("base", "main"))
+		Expect(body["head"]).To(HavePrefix("cyclops/demo-"))
+
+		_, err = branchFile(remote, "main", "modules/demo.yaml")
+		Expect(err).To(HaveOccurred())
+
+		content, err := branchFile(remote, body["head"], "modules/demo.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(ContainSubstring("name: demo"))
+	})
+
+	It("writes Modules and TemplateStores to the default destination", func() {
+		client := NewWriteClient(
+			auth.NewTemplatesResolver(k8sClient),
+			"",
+			&cyclopsv1alpha1.GitOpsWriteDestination{Repo: remote, Path: "cluster", Version: "main"},
+			PullRequestConfig{},
+			logr.Discard(),
+		)
+
+		module.Annotations = nil
+		module.ResourceVersion = "42"
+		module.History = []cyclopsv1alpha1.HistoryEntry{{Generation: 1}}
+
+		Expect(client.Destination(module)).To(BeEquivalentTo(client.DefaultDestination()))
+
+		_, err := client.Write(module)
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err := branchFile(remote, "main", "cluster/demo.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).ToNot(ContainSubstring("resourceVersion"))
+		Expect(content).ToNot(ContainSubstring("history"))
+
+		_, err = client.WriteTemplateStore(cyclopsv1alpha1.TemplateStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-template"},
+			Spec:       cyclopsv1alpha1.TemplateRef{URL: "https://github.com/cyclops-ui/templates", Path: "demo"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err = branchFile(remote, "main", "cluster/templatestores/demo-template.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(ContainSubstring("https://github.com/cyclops-ui/templates"))
+
+		_, err = client.DeleteTemplateStore("demo-template")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = branchFile(remote, "main", "cluster/templatestores/demo-template.yaml")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reads Modules from their git destination", func() {
+		client := NewWriteClient(auth.NewTemplatesResolver(k8sClient), "", nil, PullRequestConfig{}, logr.Discard())
+
+		missing, err := client.NewReader().ReadModule(module)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(BeNil())
+
+		module.Spec.TargetNamespace = "demo"
+		_, err = client.Write(module)
+		Expect(err).ToNot(HaveOccurred())
+
+		committed, err := client.NewReader().ReadModule(module)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(committed.Name).To(BeEquivalentTo("demo"))
+		Expect(committed.Spec.TargetNamespace).To(BeEquivalentTo("demo"))
+	})
+})
@@ -2,9 +2,13 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/apiauth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/audit"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/controller/sse"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/controller/ws"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/drift"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/integrations/helm"
 	templaterepo "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template"
@@ -17,14 +21,22 @@ import (
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
 )
 
+const execRoute = "/exec/:podNamespace/:podName/:containerName"
+
 type Handler struct {
 	router *gin.Engine
 
+	auth           *apiauth.Auth
+	auditLog       *audit.Log
+	allowedOrigins []string
+	webhookSecret  string
+
 	templatesRepo  templaterepo.ITemplateRepo
 	k8sClient      k8sclient.IKubernetesClient
 	releaseClient  *helm.ReleaseClient
 	renderer       *render.Renderer
 	gitWriteClient *git.WriteClient
+	driftDetector  *drift.Detector
 
 	moduleTargetNamespace string
 
@@ -38,16 +50,26 @@ func New(
 	releaseClient *helm.ReleaseClient,
 	renderer *render.Renderer,
 	gitWriteClient *git.WriteClient,
+	driftDetector *drift.Detector,
 	moduleTargetNamespace string,
+	auth *apiauth.Auth,
+	auditLog *audit.Log,
+	allowedOrigins []string,
+	webhookSecret string,
 	telemetryClient telemetry.Client,
 	monitor prometheus.Monitor,
 ) (*Handler, error) {
 	return &Handler{
+		auth:                  auth,
+		auditLog:              auditLog,
+		allowedOrigins:        allowedOrigins,
+		webhookSecret:         webhookSecret,
 		templatesRepo:         templatesRepo,
 		k8sClient:             kubernetesClient,
 		renderer:              renderer,
 		releaseClient:         releaseClient,
 		gitWriteClient:        gitWriteClient,
+		driftDetector:         driftDetector,
 		moduleTargetNamespace: moduleTargetNamespace,
 		telemetryClient:       telemetryClient,
 		monitor:               monitor,
@@ -57,85 +79,99 @@ func New(
 func (h *Handler) Start() error {
 	gin.SetMode(gin.DebugMode)
 
-	templatesController := controller.NewTemplatesController(h.templatesRepo, h.k8sClient, h.telemetryClient)
-	modulesController := controller.NewModulesController(h.templatesRepo, h.k8sClient, h.renderer, h.gitWriteClient, h.moduleTargetNamespace, h.telemetryClient, h.monitor)
+	templatesController := controller.NewTemplatesController(h.templatesRepo, h.k8sClient, h.gitWriteClient, h.telemetryClient)
+	modulesController := controller.NewModulesController(h.templatesRepo, h.k8sClient, h.renderer, h.gitWriteClient, h.driftDetector, h.moduleTargetNamespace, h.telemetryClient, h.monitor)
 	clusterController := controller.NewClusterController(h.k8sClient)
-	helmController := controller.NewHelmController(h.k8sClient, h.releaseClient, h.telemetryClient)
+	helmController := controller.NewHelmController(h.k8sClient, h.releaseClient, h.gitWriteClient, h.telemetryClient)
+	auditController := controller.NewAuditController(h.auditLog)
 
 	h.router = gin.New()
+	h.router.Use(h.options)
+
+	h.router.GET("/ping", h.pong())
+
+	// webhooks authenticate with their secret instead of user credentials
+	if len(h.webhookSecret) != 0 {
+		webhooksController := controller.NewWebhooksController(h.templatesRepo, h.k8sClient, h.webhookSecret)
+		h.router.POST("/webhooks/templates", webhooksController.TemplatePush)
+	}
+
+	api := h.router.Group("", h.auditLog.Middleware(mutating), h.auth.Middleware())
 
 	server := sse.NewServer(h.k8sClient, h.releaseClient)
 	wsServer := ws.NewServer(h.k8sClient)
 
-	h.router.GET("/exec/:podNamespace/:podName/:containerName", wsServer.ExecCommand)
+	api.GET(execRoute, wsServer.ExecCommand)
 
-	h.router.GET("/stream/resources/:name", sse.HeadersMiddleware(), server.Resources)
-	h.router.GET("/stream/releases/:namespace/:name/resources", sse.HeadersMiddleware(), server.ReleaseResources)
-	h.router.POST("/stream/resources", sse.HeadersMiddleware(), server.SingleResource)
-
-	h.router.GET("/ping", h.pong())
+	api.GET("/stream/resources/:name", sse.HeadersMiddleware(), server.Resources)
+	api.GET("/stream/releases/:namespace/:name/resources", sse.HeadersMiddleware(), server.ReleaseResources)
+	api.POST("/stream/resources", sse.HeadersMiddleware(), server.SingleResource)
 
 	// templates
-	h.router.GET("/templates", templatesController.GetTemplate)
-	h.router.GET("/templates/initial", templatesController.GetTemplateInitialValues)
+	api.GET("/templates", templatesController.GetTemplate)
+	api.GET("/templates/initial", templatesController.GetTemplateInitialValues)
 
-	h.router.GET("/templates/revisions", templatesController.GetTemplateRevisions)
+	api.GET("/templates/revisions", templatesController.GetTemplateRevisions)
 
 	// templates store
-	h.router.GET("/templates/store", templatesController.ListTemplatesStore)
-	h.router.PUT("/templates/store", templatesController.CreateTemplatesStore)
-	h.router.POST("/templates/store/:name", templatesController.EditTemplatesStore)
-	h.router.DELETE("/templates/store/:name", templatesController.DeleteTemplatesStore)
+	api.GET("/templates/store", templatesController.ListTemplatesStore)
+	api.PUT("/templates/store", templatesController.CreateTemplatesStore)
+	api.POST("/templates/store/:name", templatesController.EditTemplatesStore)
+	api.DELETE("/templates/store/:name", templatesController.DeleteTemplatesStore)
 
 	// modules
-	h.router.GET("/modules/:name", modulesController.GetModule)
-	h.router.GET("/modules/list", modulesController.ListModules)
-	h.router.DELETE("/modules/:name", modulesController.DeleteModule)
-	h.router.POST("/modules/new", modulesController.CreateModule)
-	h.router.POST("/modules/update", modulesController.UpdateModule)
-	h.router.POST("/modules/rollback/manifest", modulesController.HistoryEntryManifest)
-	h.router.POST("/modules/rollback", modulesController.RollbackModule)
-	h.router.GET("/modules/:name/raw", modulesController.GetRawModuleManifest)
-	h.router.POST("/modules/:name/reconcile", modulesController.ReconcileModule)
-	h.router.GET("/modules/:name/history", modulesController.GetModuleHistory)
-	h.router.POST("/modules/:name/manifest", modulesController.Manifest)
-	h.router.GET("/modules/:name/currentManifest", modulesController.CurrentManifest)
-	h.router.GET("/modules/:name/resources", modulesController.ResourcesForModule)
-	h.router.GET("/modules/:name/template", modulesController.Template)
-	h.router.GET("/modules/:name/helm-template", modulesController.HelmTemplate)
-	//h.router.POST("/modules/resources", modulesController.ModuleToResources)
-
-	h.router.POST("/modules/mcp/install", modulesController.InstallMCPServer)
-	h.router.GET("/modules/mcp/status", modulesController.MCPServerStatus)
-
-	h.router.GET("/resources/pods/:namespace/:name/:container/logs", modulesController.GetLogs)
-	h.router.GET("/resources/pods/:namespace/:name/:container/logs/stream", sse.HeadersMiddleware(), modulesController.GetLogsStream)
-	h.router.GET("/resources/pods/:namespace/:name/:container/logs/download", modulesController.DownloadLogs)
-
-	h.router.GET("/manifest", modulesController.GetManifest)
-	h.router.GET("/resources", modulesController.GetResource)
-	h.router.DELETE("/resources", modulesController.DeleteModuleResource)
-
-	h.router.POST("/resources/restart", modulesController.Restart)
-
-	h.router.GET("/nodes", clusterController.ListNodes)
-	h.router.GET("/nodes/:name", clusterController.GetNode)
-
-	h.router.GET("/namespaces", clusterController.ListNamespaces)
+	api.GET("/modules/:name", modulesController.GetModule)
+	api.GET("/modules/list", modulesController.ListModules)
+	api.GET("/modules/dependencies", modulesController.DependencyGraph)
+	api.DELETE("/modules/:name", modulesController.DeleteModule)
+	api.POST("/modules/new", modulesController.CreateModule)
+	api.POST("/modules/update", modulesController.UpdateModule)
+	api.POST("/modules/rollback/manifest", modulesController.HistoryEntryManifest)
+	api.POST("/modules/rollback", modulesController.RollbackModule)
+	api.GET("/modules/:name/raw", modulesController.GetRawModuleManifest)
+	api.GET("/modules/:name/values", modulesController.ModuleValues)
+	api.POST("/modules/:name/values", modulesController.SetModuleValues)
+	api.POST("/modules/:name/reconcile", modulesController.ReconcileModule)
+	api.GET("/modules/:name/history", modulesController.GetModuleHistory)
+	api.GET("/modules/:name/drift", modulesController.Drift)
+	api.POST("/modules/:name/manifest", modulesController.Manifest)
+	api.GET("/modules/:name/currentManifest", modulesController.CurrentManifest)
+	api.POST("/modules/:name/diff", modulesController.Diff)
+	api.GET("/modules/:name/resources", modulesController.ResourcesForModule)
+	api.GET("/modules/:name/template", modulesController.Template)
+	api.GET("/modules/:name/helm-template", modulesController.HelmTemplate)
+	api.GET("/modules/:name/audit", auditController.ModuleAuditLog)
+	//api.POST("/modules/resources", modulesController.ModuleToResources)
+
+	api.POST("/modules/mcp/install", modulesController.InstallMCPServer)
+	api.GET("/modules/mcp/status", modulesController.MCPServerStatus)
+
+	api.GET("/resources/pods/:namespace/:name/:container/logs", modulesController.GetLogs)
+	api.GET("/resources/pods/:namespace/:name/:container/logs/stream", sse.HeadersMiddleware(), modulesController.GetLogsStream)
+	api.GET("/resources/pods/:namespace/:name/:container/logs/download", modulesController.DownloadLogs)
+
+	api.GET("/manifest", modulesController.GetManifest)
+	api.GET("/resources", modulesController.GetResource)
+	api.DELETE("/resources", modulesController.DeleteModuleResource)
+
+	api.POST("/resources/restart", modulesController.Restart)
+
+	api.GET("/nodes", clusterController.ListNodes)
+	api.GET("/nodes/:name", clusterController.GetNode)
+
+	api.GET("/namespaces", clusterController.ListNamespaces)
 
 	// region helm migrator
-	h.router.GET("/helm/releases", helmController.ListReleases)
-	h.router.GET("/helm/releases/:namespace/:name", helmController.GetRelease)
-	h.router.POST("/helm/releases/:namespace/:name", helmController.UpgradeRelease)
-	h.router.DELETE("/helm/releases/:namespace/:name", helmController.UninstallRelease)
-	h.router.GET("/helm/releases/:namespace/:name/resources", helmController.GetReleaseResources)
-	h.router.GET("/helm/releases/:namespace/:name/fields", helmController.GetReleaseSchema)
-	h.router.GET("/helm/releases/:namespace/:name/values", helmController.GetReleaseValues)
-	h.router.POST("/helm/releases/:namespace/:name/migrate", helmController.MigrateHelmRelease)
+	api.GET("/helm/releases", helmController.ListReleases)
+	api.GET("/helm/releases/:namespace/:name", helmController.GetRelease)
+	api.POST("/helm/releases/:namespace/:name", helmController.UpgradeRelease)
+	api.DELETE("/helm/releases/:namespace/:name", helmController.UninstallRelease)
+	api.GET("/helm/releases/:namespace/:name/resources", helmController.GetReleaseResources)
+	api.GET("/helm/releases/:namespace/:name/fields", helmController.GetReleaseSchema)
+	api.GET("/helm/releases/:namespace/:name/values", helmController.GetReleaseValues)
+	api.POST("/helm/releases/:namespace/:name/migrate", helmController.MigrateHelmRelease)
 	// endregion
 
-	h.router.Use(h.options)
-
 	return h.router.Run()
 }
 
@@ -145,8 +181,36 @@ func (h *Handler) pong() func(ctx *gin.Context) {
 	}
 }
 
+// readOnlyRoutes are non GET routes that do not change anything
+var readOnlyRoutes = map[string]struct{}{
+	"/stream/resources":          {},
+	"/modules/rollback/manifest": {},
+	"/modules/:name/manifest":    {},
+	"/modules/:name/diff":        {},
+}
+
+// mutating returns true for requests that change cluster state or exec into pods
+func mutating(ctx *gin.Context) bool {
+	if ctx.FullPath() == execRoute {
+		return true
+	}
+
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	_, ok := readOnlyRoutes[ctx.FullPath()]
+	return !ok
+}
+
+// options sets CORS headers for allowed origins and responds to preflight requests
 func (h *Handler) options(ctx *gin.Context) {
-	ctx.Header("Access-Control-Allow-Origin", "*")
+	if origin, ok := h.allowedOrigin(ctx.GetHeader("Origin")); ok {
+		ctx.Header("Access-Control-Allow-Origin", origin)
+		ctx.Header("Vary", "Origin")
+	}
+
 	if ctx.Request.Method != http.MethodOptions {
 		ctx.Next()
 		return
@@ -158,3 +222,23 @@ func (h *Handler) options(ctx *gin.Context) {
 	ctx.Header("Content-Type", "application/json")
 	ctx.AbortWithStatus(http.StatusOK)
 }
+
+// allowedOrigin returns the value of the Access-Control-Allow-Origin header for
+// the request origin. All origins are allowed if no allowed origins are configured.
+func (h *Handler) allowedOrigin(origin string) (string, bool) {
+	if len(h.allowedOrigins) == 0 {
+		return "*", true
+	}
+
+	for _, allowed := range h.allowedOrigins {
+		if allowed == "*" {
+			return "*", true
+		}
+
+		if strings.EqualFold(allowed, origin) {
+			return origin, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,252 @@
+package history
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// DefaultLimit is the number of revisions kept per Module if no limit is configured
+const DefaultLimit = 10
+
+// TemplateUpdateAuthor is the author of revisions recorded on automatic template
+// updates
+const TemplateUpdateAuthor = "cyclops-template-updater"
+
+// RevisionStore persists ModuleRevisions
+type RevisionStore interface {
+	ListModuleRevisions(moduleName string) ([]cyclopsv1alpha1.ModuleRevision, error)
+	CreateModuleRevision(revision *cyclopsv1alpha1.ModuleRevision) error
+	DeleteModuleRevision(name string) error
+}
+
+// ManifestDigest returns the sha256 digest of a rendered manifest
+func ManifestDigest(manifest string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest)))
+}
+
+// RevisionName is the name of the ModuleRevision of the given Module generation
+func RevisionName(moduleName string, generation int64) string {
+	return fmt.Sprintf("%v-%v", moduleName, generation)
+}
+
+// Record stores the current generation of the Module as a ModuleRevision pinned
+// to the resolved template version. Once a new revision is stored, the oldest
+// revisions over the limit are deleted. Generations that already have a revision
+// are not recorded again.
+func Record(
+	store RevisionStore,
+	module cyclopsv1alpha1.Module,
+	resolvedVersion string,
+	manifestDigest string,
+	limit int,
+) error {
+	revision := newRevision(
+		RevisionName(module.Name, module.Generation),
+		module,
+		resolvedVersion,
+		module.GetAnnotations()[cyclopsv1alpha1.AuthorAnnotation],
+		manifestDigest,
+	)
+
+	if err := store.CreateModuleRevision(revision); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+
+	return prune(store, module.Name, limit)
+}
+
+// RecordTemplateUpdate stores the Module generation pinned to a template version
+// it was automatically updated to. The generation does not change on automatic
+// updates, so its revision is named after the version as well.
+func RecordTemplateUpdate(
+	store RevisionStore,
+	module cyclopsv1alpha1.Module,
+	resolvedVersion string,
+	limit int,
+) error {
+	sum := sha256.Sum256([]byte(resolvedVersion))
+	name := fmt.Sprintf("%v-%x", RevisionName(module.Name, module.Generation), sum[:5])
+
+	revision := newRevision(name, module, resolvedVersion, TemplateUpdateAuthor, "")
+	if err := store.CreateModuleRevision(revision); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+
+	return prune(store, module.Name, limit)
+}
+
+func newRevision(
+	name string,
+	module cyclopsv1alpha1.Module,
+	resolvedVersion string,
+	author string,
+	manifestDigest string,
+) *cyclopsv1alpha1.ModuleRevision {
+	now := metav1.Now()
+
+	return &cyclopsv1alpha1.ModuleRevision{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: cyclopsv1alpha1.GroupVersion.String(),
+			Kind:       "ModuleRevision",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				cyclopsv1alpha1.ModuleRevisionModuleLabel: module.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: cyclopsv1alpha1.GroupVersion.String(),
+					Kind:       "Module",
+					Name:       module.Name,
+					UID:        module.UID,
+				},
+			},
+		},
+		Spec: cyclopsv1alpha1.ModuleRevisionSpec{
+			ModuleName: module.Name,
+			HistoryEntry: cyclopsv1alpha1.HistoryEntry{
+				Generation:      module.Generation,
+				TargetNamespace: module.Spec.TargetNamespace,
+				TemplateRef: cyclopsv1alpha1.HistoryTemplateRef{
+					URL:        module.Spec.TemplateRef.URL,
+					Path:       module.Spec.TemplateRef.Path,
+					Version:    resolvedVersion,
+					SourceType: module.Spec.TemplateRef.SourceType,
+				},
+				Values:         module.Spec.Values,
+				Author:         author,
+				Timestamp:      &now,
+				ManifestDigest: manifestDigest,
+			},
+		},
+	}
+}
+
+func prune(store RevisionStore, moduleName string, limit int) error {
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+
+	revisions, err := store.ListModuleRevisions(moduleName)
+	if err != nil {
+		return err
+	}
+
+	if len(revisions) <= limit {
+		return nil
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return newer(revisions[i].Spec.HistoryEntry, revisions[j].Spec.HistoryEntry)
+	})
+
+	for _, revision := range revisions[limit:] {
+		if err := store.DeleteModuleRevision(revision.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Entries returns the history of the Module, newest first, without its current
+// state. History entries stored on the Module itself by older versions are
+// included if there is no revision for their generation.
+func Entries(store RevisionStore, module cyclopsv1alpha1.Module) ([]cyclopsv1alpha1.HistoryEntry, error) {
+	revisions, err := store.ListModuleRevisions(module.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]cyclopsv1alpha1.HistoryEntry, 0, len(revisions)+len(module.History))
+	generations := make(map[int64]struct{}, len(revisions))
+
+	for _, revision := range revisions {
+		generations[revision.Spec.Generation] = struct{}{}
+
+		if current(module, revision.Spec.HistoryEntry) {
+			continue
+		}
+
+		entries = append(entries, revision.Spec.HistoryEntry)
+	}
+
+	for _, entry := range module.History {
+		if _, ok := generations[entry.Generation]; ok {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return newer(entries[i], entries[j])
+	})
+
+	return entries, nil
+}
+
+// current returns true if the entry is the state the Module is in. A generation
+// has more than one entry if its template was updated automatically.
+func current(module cyclopsv1alpha1.Module, entry cyclopsv1alpha1.HistoryEntry) bool {
+	if entry.Generation != module.Generation {
+		return false
+	}
+
+	resolvedVersion := module.Status.TemplateResolvedVersion
+	return len(resolvedVersion) == 0 || entry.TemplateRef.Version == resolvedVersion
+}
+
+func newer(a, b cyclopsv1alpha1.HistoryEntry) bool {
+	if a.Generation != b.Generation {
+		return a.Generation > b.Generation
+	}
+
+	if a.Timestamp == nil || b.Timestamp == nil {
+		return false
+	}
+
+	return b.Timestamp.Before(a.Timestamp)
+}
+
+// Find returns the history entry of the given Module generation, or nil if the
+// generation is not in the Module history
+func Find(store RevisionStore, module cyclopsv1alpha1.Module, generation int64) (*cyclopsv1alpha1.HistoryEntry, error) {
+	entries, err := Entries(store, module)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Generation == generation {
+			return &entry, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Rollback sets the Module spec to the given history entry
+func Rollback(module *cyclopsv1alpha1.Module, entry cyclopsv1alpha1.HistoryEntry) {
+	module.Spec.Values = entry.Values
+	module.Spec.TemplateRef = cyclopsv1alpha1.TemplateRef{
+		URL:        entry.TemplateRef.URL,
+		Path:       entry.TemplateRef.Path,
+		Version:    entry.TemplateRef.Version,
+		SourceType: entry.TemplateRef.SourceType,
+	}
+	module.Spec.TargetNamespace = entry.TargetNamespace
+}
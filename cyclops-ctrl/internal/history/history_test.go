@@ -0,0 +1,168 @@
+package history
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+func TestHistory(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test history")
+}
+
+type memoryStore struct {
+	revisions map[string]cyclopsv1alpha1.ModuleRevision
+}
+
+func (s *memoryStore) ListModuleRevisions(moduleName string) ([]cyclopsv1alpha1.ModuleRevision, error) {
+	revisions := make([]cyclopsv1alpha1.ModuleRevision, 0)
+	for _, revision := range s.revisions {
+		if revision.Labels[cyclopsv1alpha1.ModuleRevisionModuleLabel] == moduleName {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	return revisions, nil
+}
+
+func (s *memoryStore) CreateModuleRevision(revision *cyclopsv1alpha1.ModuleRevision) error {
+	if _, ok := s.revisions[revision.Name]; ok {
+		return apierrors.NewAlreadyExists(schema.GroupResource{Resource: "modulerevisions"}, revision.Name)
+	}
+
+	s.revisions[revision.Name] = *revision
+	return nil
+}
+
+func (s *memoryStore) DeleteModuleRevision(name string) error {
+	delete(s.revisions, name)
+	return nil
+}
+
+func module(name string, generation int64, version string) cyclopsv1alpha1.Module {
+	return cyclopsv1alpha1.Module{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Generation: generation,
+			Annotations: map[string]string{
+				cyclopsv1alpha1.AuthorAnnotation: "jane",
+			},
+		},
+		Spec: cyclopsv1alpha1.ModuleSpec{
+			TemplateRef: cyclopsv1alpha1.TemplateRef{
+				URL:     "https://github.com/cyclops-ui/templates",
+				Path:    "demo",
+				Version: version,
+			},
+		},
+	}
+}
+
+var _ = Describe("Module history test", func() {
+	var store *memoryStore
+
+	BeforeEach(func() {
+		store = &memoryStore{revisions: map[string]cyclopsv1alpha1.ModuleRevision{}}
+	})
+
+	Describe("Record", func() {
+		It("stores the revision pinned to the resolved version", func() {
+			Expect(Record(store, module("demo", 1, "main"), "3f2a1c", ManifestDigest("kind: Service"), 10)).To(Succeed())
+
+			revision, ok := store.revisions["demo-1"]
+			Expect(ok).To(BeTrue())
+			Expect(revision.Spec.ModuleName).To(BeEquivalentTo("demo"))
+			Expect(revision.Spec.TemplateRef.Version).To(BeEquivalentTo("3f2a1c"))
+			Expect(revision.Spec.Author).To(BeEquivalentTo("jane"))
+			Expect(revision.Spec.ManifestDigest).To(HavePrefix("sha256:"))
+			Expect(revision.Spec.Timestamp).ToNot(BeNil())
+		})
+
+		It("does not overwrite an existing revision", func() {
+			Expect(Record(store, module("demo", 1, "main"), "3f2a1c", "", 10)).To(Succeed())
+			Expect(Record(store, module("demo", 1, "main"), "7b9e0d", "", 10)).To(Succeed())
+
+			Expect(store.revisions["demo-1"].Spec.TemplateRef.Version).To(BeEquivalentTo("3f2a1c"))
+		})
+
+		It("deletes the oldest revisions over the limit", func() {
+			for generation := int64(1); generation <= 5; generation++ {
+				Expect(Record(store, module("demo", generation, "main"), "3f2a1c", "", 3)).To(Succeed())
+			}
+			Expect(Record(store, module("other", 1, "main"), "3f2a1c", "", 3)).To(Succeed())
+
+			Expect(store.revisions).To(HaveLen(4))
+			Expect(store.revisions).To(HaveKey("demo-3"))
+			Expect(store.revisions).To(HaveKey("demo-5"))
+			Expect(store.revisions).ToNot(HaveKey("demo-2"))
+			Expect(store.revisions).To(HaveKey("other-1"))
+		})
+	})
+
+	Describe("Entries", func() {
+		It("returns revisions and legacy entries without the current generation", func() {
+			for generation := int64(2); generation <= 4; generation++ {
+				Expect(Record(store, module("demo", generation, "main"), "3f2a1c", "", 10)).To(Succeed())
+			}
+
+			current := module("demo", 4, "main")
+			current.History = []cyclopsv1alpha1.HistoryEntry{
+				{Generation: 3},
+				{Generation: 1},
+			}
+
+			entries, err := Entries(store, current)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(3))
+			Expect(entries[0].Generation).To(BeEquivalentTo(3))
+			Expect(entries[0].TemplateRef.Version).To(BeEquivalentTo("3f2a1c"))
+			Expect(entries[1].Generation).To(BeEquivalentTo(2))
+			Expect(entries[2].Generation).To(BeEquivalentTo(1))
+		})
+	})
+
+	Describe("RecordTemplateUpdate", func() {
+		It("keeps the version the generation used before the update in history", func() {
+			Expect(Record(store, module("demo", 1, "^1.0.0"), "1.0.0", "", 10)).To(Succeed())
+			Expect(Record(store, module("demo", 2, "^1.0.0"), "1.1.0", "", 10)).To(Succeed())
+
+			current := module("demo", 2, "^1.0.0")
+			current.Status.TemplateResolvedVersion = "1.2.0"
+			Expect(RecordTemplateUpdate(store, current, "1.2.0", 10)).To(Succeed())
+			Expect(store.revisions).To(HaveLen(3))
+
+			entries, err := Entries(store, current)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Generation).To(BeEquivalentTo(2))
+			Expect(entries[0].TemplateRef.Version).To(BeEquivalentTo("1.1.0"))
+			Expect(entries[1].TemplateRef.Version).To(BeEquivalentTo("1.0.0"))
+
+			entry, err := Find(store, current, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entry.TemplateRef.Version).To(BeEquivalentTo("1.1.0"))
+		})
+	})
+
+	Describe("Find", func() {
+		It("returns nil for unknown generations", func() {
+			Expect(Record(store, module("demo", 1, "main"), "3f2a1c", "", 10)).To(Succeed())
+
+			entry, err := Find(store, module("demo", 2, "main"), 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entry).ToNot(BeNil())
+
+			entry, err = Find(store, module("demo", 2, "main"), 7)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entry).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,147 @@
+package immutable
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	json "github.com/json-iterator/go"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+)
+
+// Violations returns the paths of values the template marks as immutable that
+// differ between the old and the new values, e.g. storage.class or
+// volumes[0].size. Immutable values can be set if they were not set before.
+// Array items are compared by index and map entries by key; added and removed
+// items are allowed.
+func Violations(root models.Field, oldValues, newValues map[string]interface{}) []string {
+	violations := compare(root, "", oldValues, newValues)
+	sort.Strings(violations)
+	return violations
+}
+
+func compare(field models.Field, path string, oldValue, newValue interface{}) []string {
+	if oldValue == nil {
+		return nil
+	}
+
+	if field.Immutable {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			return []string{pathOrRoot(path)}
+		}
+		return nil
+	}
+
+	violations := make([]string, 0)
+
+	switch {
+	case len(field.Properties) != 0:
+		oldObject, _ := oldValue.(map[string]interface{})
+		newObject, _ := newValue.(map[string]interface{})
+
+		for _, property := range field.Properties {
+			violations = append(violations, compare(
+				property,
+				join(path, property.Name),
+				oldObject[property.Name],
+				newObject[property.Name],
+			)...)
+		}
+	case field.AdditionalProperties != nil:
+		oldObject, _ := oldValue.(map[string]interface{})
+		newObject, _ := newValue.(map[string]interface{})
+
+		for key, value := range oldObject {
+			if _, ok := newObject[key]; !ok {
+				continue
+			}
+
+			violations = append(violations, compare(
+				*field.AdditionalProperties,
+				join(path, key),
+				value,
+				newObject[key],
+			)...)
+		}
+	case field.Items != nil:
+		oldItems, _ := oldValue.([]interface{})
+		newItems, _ := newValue.([]interface{})
+
+		for i := 0; i < len(oldItems) && i < len(newItems); i++ {
+			violations = append(violations, compare(
+				*field.Items,
+				fmt.Sprintf("%v[%v]", path, i),
+				oldItems[i],
+				newItems[i],
+			)...)
+		}
+	}
+
+	return violations
+}
+
+// Error lists the immutable values that were changed
+type Error struct {
+	Paths []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("immutable values can not be changed: %v", strings.Join(e.Paths, ", "))
+}
+
+// Validate returns an Error if values the template marks as immutable changed
+func Validate(root models.Field, oldValues, newValues map[string]interface{}) error {
+	violations := Violations(root, oldValues, newValues)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &Error{Paths: violations}
+}
+
+// ValidateRaw validates values in their JSON form, as they are stored on
+// Modules and their revisions
+func ValidateRaw(root models.Field, oldValues, newValues []byte) error {
+	oldObject, err := unmarshal(oldValues)
+	if err != nil {
+		return err
+	}
+
+	newObject, err := unmarshal(newValues)
+	if err != nil {
+		return err
+	}
+
+	return Validate(root, oldObject, newObject)
+}
+
+func unmarshal(values []byte) (map[string]interface{}, error) {
+	object := make(map[string]interface{})
+	if len(values) == 0 {
+		return object, nil
+	}
+
+	if err := json.Unmarshal(values, &object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func join(path, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func pathOrRoot(path string) string {
+	if len(path) == 0 {
+		return "(root)"
+	}
+
+	return path
+}
@@ -0,0 +1,108 @@
+package immutable
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+)
+
+func TestImmutable(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test immutable")
+}
+
+var _ = Describe("Immutable values test", func() {
+	root := models.Field{
+		Properties: []models.Field{
+			{Name: "replicas"},
+			{
+				Name: "storage",
+				Properties: []models.Field{
+					{Name: "class", Immutable: true},
+					{Name: "size"},
+				},
+			},
+			{
+				Name: "volumes",
+				Items: &models.Field{
+					Properties: []models.Field{
+						{Name: "name"},
+						{Name: "size", Immutable: true},
+					},
+				},
+			},
+		},
+	}
+
+	old := map[string]interface{}{
+		"replicas": 1,
+		"storage": map[string]interface{}{
+			"class": "fast",
+			"size":  "1Gi",
+		},
+		"volumes": []interface{}{
+			map[string]interface{}{"name": "data", "size": "1Gi"},
+		},
+	}
+
+	It("allows changes to mutable values", func() {
+		Expect(Validate(root, old, map[string]interface{}{
+			"replicas": 3,
+			"storage": map[string]interface{}{
+				"class": "fast",
+				"size":  "2Gi",
+			},
+			"volumes": []interface{}{
+				map[string]interface{}{"name": "cache", "size": "1Gi"},
+				map[string]interface{}{"name": "logs", "size": "5Gi"},
+			},
+		})).To(Succeed())
+	})
+
+	It("allows setting immutable values that were not set", func() {
+		Expect(Validate(root, map[string]interface{}{}, old)).To(Succeed())
+	})
+
+	It("returns paths of changed immutable values", func() {
+		err := Validate(root, old, map[string]interface{}{
+			"volumes": []interface{}{
+				map[string]interface{}{"name": "data", "size": "2Gi"},
+			},
+		})
+
+		var immutableErr *Error
+		Expect(err).To(BeAssignableToTypeOf(immutableErr))
+		Expect(err.(*Error).Paths).To(Equal([]string{"storage.class", "volumes[0].size"}))
+		Expect(err.Error()).To(Equal("immutable values can not be changed: storage.class, volumes[0].size"))
+	})
+
+	It("validates values stored as JSON", func() {
+		Expect(ValidateRaw(root, []byte(`{"storage":{"class":"fast"}}`), nil)).To(MatchError(
+			"immutable values can not be changed: storage.class",
+		))
+		Expect(ValidateRaw(root, nil, []byte(`{"storage":{"class":"fast"}}`))).To(Succeed())
+	})
+
+	It("compares map entries by key", func() {
+		volumes := models.Field{
+			Properties: []models.Field{
+				{
+					Name: "volumes",
+					AdditionalProperties: &models.Field{
+						Properties: []models.Field{{Name: "class", Immutable: true}},
+					},
+				},
+			},
+		}
+
+		err := ValidateRaw(
+			volumes,
+			[]byte(`{"volumes":{"data":{"class":"fast"},"logs":{"class":"slow"}}}`),
+			[]byte(`{"volumes":{"data":{"class":"slow"},"cache":{"class":"slow"}}}`),
+		)
+		Expect(err).To(MatchError("immutable values can not be changed: volumes.data.class"))
+	})
+})
@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"sort"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/dependencies"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+)
+
+func ModuleDependencyGraphToDTO(modules []cyclopsv1alpha1.Module) dto.ModuleDependencyGraph {
+	graph := dependencies.NewGraph(modules)
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Name < modules[j].Name
+	})
+
+	out := dto.ModuleDependencyGraph{
+		Nodes: make([]dto.ModuleDependencyNode, 0, len(modules)),
+		Edges: make([]dto.ModuleDependencyEdge, 0),
+	}
+
+	for _, module := range modules {
+		out.Nodes = append(out.Nodes, dto.ModuleDependencyNode{
+			Name:  module.Name,
+			Ready: dependencies.Ready(module),
+			Cycle: graph.Cycle(module.Name),
+		})
+
+		for _, dependency := range module.Spec.DependsOn {
+			_, exists := graph[dependency.Name]
+
+			out.Edges = append(out.Edges, dto.ModuleDependencyEdge{
+				From:    module.Name,
+				To:      dependency.Name,
+				Missing: !exists,
+			})
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,119 @@
+package mapper
+
+import (
+	"encoding/json"
+
+	"gomodules.xyz/jsonpatch/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+)
+
+// fields set by the API server that change on every write and are not part of
+// the desired state
+var diffIgnoredFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "uid"},
+	{"metadata", "creationTimestamp"},
+	{"status"},
+}
+
+// ResourceDiff compares the object running in the cluster with the object
+// returned from a dry-run apply. A nil current object is reported as added and
+// nil is returned if the objects do not differ.
+func ResourceDiff(current, proposed *unstructured.Unstructured) (*dto.ResourceDiff, error) {
+	diff := &dto.ResourceDiff{
+		Group:     proposed.GroupVersionKind().Group,
+		Version:   proposed.GroupVersionKind().Version,
+		Kind:      proposed.GroupVersionKind().Kind,
+		Name:      proposed.GetName(),
+		Namespace: proposed.GetNamespace(),
+		Action:    dto.ResourceChanged,
+	}
+
+	currentObj := map[string]interface{}{}
+	if current == nil {
+		diff.Action = dto.ResourceAdded
+	} else {
+		currentObj = stripServerFields(current)
+	}
+
+	patch, err := jsonPatch(currentObj, stripServerFields(proposed))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(patch) == 0 {
+		return nil, nil
+	}
+
+	diff.Patch = patch
+	return diff, nil
+}
+
+func RemovedResourceDiff(obj unstructured.Unstructured) dto.ResourceDiff {
+	return dto.ResourceDiff{
+		Group:     obj.GroupVersionKind().Group,
+		Version:   obj.GroupVersionKind().Version,
+		Kind:      obj.GroupVersionKind().Kind,
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Action:    dto.ResourceRemoved,
+	}
+}
+
+// ModuleSpecDiff returns the JSON patch from the current to the proposed Module spec
+func ModuleSpecDiff(current, proposed cyclopsv1alpha1.ModuleSpec) ([]dto.JSONPatch, error) {
+	currentObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&current)
+	if err != nil {
+		return nil, err
+	}
+
+	proposedObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&proposed)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonPatch(currentObj, proposedObj)
+}
+
+func stripServerFields(obj *unstructured.Unstructured) map[string]interface{} {
+	stripped := obj.DeepCopy()
+	for _, field := range diffIgnoredFields {
+		unstructured.RemoveNestedField(stripped.Object, field...)
+	}
+
+	return stripped.Object
+}
+
+func jsonPatch(current, proposed map[string]interface{}) ([]dto.JSONPatch, error) {
+	currentData, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	proposedData, err := json.Marshal(proposed)
+	if err != nil {
+		return nil, err
+	}
+
+	operations, err := jsonpatch.CreatePatch(currentData, proposedData)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := make([]dto.JSONPatch, 0, len(operations))
+	for _, operation := range operations {
+		patch = append(patch, dto.JSONPatch{
+			Op:    operation.Operation,
+			Path:  operation.Path,
+			Value: operation.Value,
+		})
+	}
+
+	return patch, nil
+}
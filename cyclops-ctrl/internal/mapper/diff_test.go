@@ -0,0 +1,91 @@
+package mapper
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+)
+
+var _ = Describe("Diff mapper test", func() {
+	configMap := func(data map[string]interface{}, resourceVersion string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":            "my-config",
+					"namespace":       "default",
+					"resourceVersion": resourceVersion,
+					"managedFields":   []interface{}{map[string]interface{}{"manager": "cyclops-ctrl"}},
+				},
+				"data": data,
+			},
+		}
+	}
+
+	Describe("ResourceDiff", func() {
+		It("returns added resource with patch for every field", func() {
+			diff, err := ResourceDiff(nil, configMap(map[string]interface{}{"key": "value"}, "1"))
+
+			Expect(err).To(BeNil())
+			Expect(diff.Action).To(BeEquivalentTo(dto.ResourceAdded))
+			Expect(diff.Kind).To(BeEquivalentTo("ConfigMap"))
+			Expect(diff.Namespace).To(BeEquivalentTo("default"))
+			Expect(diff.Patch).To(ContainElement(dto.JSONPatch{
+				Op:    "add",
+				Path:  "/data",
+				Value: map[string]interface{}{"key": "value"},
+			}))
+		})
+
+		It("returns changed fields ignoring server managed metadata", func() {
+			diff, err := ResourceDiff(
+				configMap(map[string]interface{}{"key": "value", "removed": "value"}, "1"),
+				configMap(map[string]interface{}{"key": "changed"}, "2"),
+			)
+
+			Expect(err).To(BeNil())
+			Expect(diff.Action).To(BeEquivalentTo(dto.ResourceChanged))
+			Expect(diff.Patch).To(ConsistOf(
+				dto.JSONPatch{Op: "replace", Path: "/data/key", Value: "changed"},
+				dto.JSONPatch{Op: "remove", Path: "/data/removed"},
+			))
+		})
+
+		It("returns nil for unchanged resource", func() {
+			diff, err := ResourceDiff(
+				configMap(map[string]interface{}{"key": "value"}, "1"),
+				configMap(map[string]interface{}{"key": "value"}, "2"),
+			)
+
+			Expect(err).To(BeNil())
+			Expect(diff).To(BeNil())
+		})
+	})
+
+	Describe("ModuleSpecDiff", func() {
+		It("returns changed template and values", func() {
+			current := cyclopsv1alpha1.ModuleSpec{
+				TemplateRef: cyclopsv1alpha1.TemplateRef{URL: "https://github.com/cyclops-ui/templates", Path: "demo", Version: "main"},
+				Values:      apiextensionsv1.JSON{Raw: []byte(`{"replicas":1,"image":"nginx"}`)},
+			}
+
+			proposed := *current.DeepCopy()
+			proposed.TemplateRef.Version = "v1.0.0"
+			proposed.Values = apiextensionsv1.JSON{Raw: []byte(`{"replicas":3,"image":"nginx"}`)}
+
+			diff, err := ModuleSpecDiff(current, proposed)
+
+			Expect(err).To(BeNil())
+			Expect(diff).To(ConsistOf(
+				dto.JSONPatch{Op: "replace", Path: "/template/version", Value: "v1.0.0"},
+				dto.JSONPatch{Op: "replace", Path: "/values/replicas", Value: float64(3)},
+			))
+		})
+	})
+})
@@ -9,6 +9,16 @@ import (
 )
 
 func HelmSchemaToFields(name string, schema helm.Property, defs map[string]helm.Property, dependencies []*models.Template) models.Field {
+	if schema.HasRef() {
+		return HelmSchemaToFields(name, resolveRef(schema, defs), defs, dependencies)
+	}
+
+	if len(schema.AllOf) != 0 {
+		field := HelmSchemaToFields(name, mergeAllOf(schema, defs), defs, dependencies)
+		field.Conditions = schemaConditions(schema, defs)
+		return field
+	}
+
 	if shouldResolvePropertyComposition(schema) {
 		return HelmSchemaToFields(name, resolvePropertyComposition(schema), defs, dependencies)
 	}
@@ -21,6 +31,7 @@ func HelmSchemaToFields(name string, schema helm.Property, defs map[string]helm.
 			DisplayName: mapTitle(name, schema.Title),
 			Items:       arrayItem(schema.Items, defs),
 			Immutable:   schema.Immutable,
+			Default:     schema.Default,
 		}
 	}
 
@@ -29,19 +40,7 @@ func HelmSchemaToFields(name string, schema helm.Property, defs map[string]helm.
 	for propertyName, property := range schema.Properties {
 		uniqueFieldNames[propertyName] = struct{}{}
 
-		if property.HasRef() {
-			key := strings.TrimPrefix(property.Reference, "#/$defs/")
-
-			fields = append(fields, HelmSchemaToFields(
-				propertyName,
-				resolveJSONSchemaRef(defs, strings.Split(key, "/")),
-				defs,
-				nil,
-			))
-			continue
-		}
-
-		fields = append(fields, HelmSchemaToFields(propertyName, property, defs, nil))
+		fields = append(fields, HelmSchemaToFields(propertyName, resolveRef(property, defs), defs, nil))
 	}
 
 	fields = sortFields(fields, schema.Order)
@@ -56,51 +55,49 @@ func HelmSchemaToFields(name string, schema helm.Property, defs map[string]helm.
 			continue
 		}
 
-		fields = append(fields, models.Field{
-			Name:             dependency.Name,
-			Description:      dependency.RootField.Description,
-			Type:             dependency.RootField.Type,
-			DisplayName:      mapTitle(dependency.Name, dependency.RootField.DisplayName),
-			ManifestKey:      dependency.RootField.ManifestKey,
-			Value:            dependency.RootField.Value,
-			Properties:       dependency.RootField.Properties,
-			Items:            dependency.RootField.Items,
-			Enum:             dependency.RootField.Enum,
-			Suggestions:      dependency.RootField.Suggestions,
-			Required:         dependency.RootField.Required,
-			FileExtension:    dependency.RootField.FileExtension,
-			Minimum:          dependency.RootField.Minimum,
-			Maximum:          dependency.RootField.Maximum,
-			MultipleOf:       dependency.RootField.MultipleOf,
-			ExclusiveMinimum: dependency.RootField.ExclusiveMinimum,
-			ExclusiveMaximum: dependency.RootField.ExclusiveMaximum,
-			MinLength:        dependency.RootField.MinLength,
-			MaxLength:        dependency.RootField.MaxLength,
-			Pattern:          dependency.RootField.Pattern,
-			Immutable:        dependency.RootField.Immutable,
-		})
+		field := dependency.RootField
+		field.Name = dependency.Name
+		field.DisplayName = mapTitle(dependency.Name, dependency.RootField.DisplayName)
+
+		fields = append(fields, field)
+	}
+
+	oneOf := oneOfFields(schema.OneOf, defs)
+
+	fieldType := mapHelmPropertyTypeToFieldType(schema)
+	if len(fieldType) == 0 {
+		fieldType = oneOfType(oneOf)
 	}
 
 	return models.Field{
-		Name:             name,
-		Description:      schema.Description,
-		Type:             mapHelmPropertyTypeToFieldType(schema),
-		DisplayName:      mapTitle(name, schema.Title),
-		ManifestKey:      name,
-		Properties:       fields,
-		Enum:             schema.Enum,
-		Suggestions:      schema.Suggestions,
-		Required:         schema.Required,
-		FileExtension:    schema.FileExtension,
-		Minimum:          schema.Minimum,
-		Maximum:          schema.Maximum,
-		ExclusiveMinimum: schema.ExclusiveMinimum,
-		ExclusiveMaximum: schema.ExclusiveMaximum,
-		MultipleOf:       schema.MultipleOf,
-		MinLength:        schema.MinLength,
-		MaxLength:        schema.MaxLength,
-		Pattern:          schema.Pattern,
-		Immutable:        schema.Immutable,
+		Name:                 name,
+		Description:          schema.Description,
+		Type:                 fieldType,
+		DisplayName:          mapTitle(name, schema.Title),
+		ManifestKey:          name,
+		Properties:           fields,
+		Enum:                 schema.Enum,
+		Suggestions:          schema.Suggestions,
+		Required:             schema.Required,
+		FileExtension:        schema.FileExtension,
+		Minimum:              schema.Minimum,
+		Maximum:              schema.Maximum,
+		ExclusiveMinimum:     schema.ExclusiveMinimum,
+		ExclusiveMaximum:     schema.ExclusiveMaximum,
+		MultipleOf:           schema.MultipleOf,
+		MinLength:            schema.MinLength,
+		MaxLength:            schema.MaxLength,
+		Pattern:              schema.Pattern,
+		Format:               schema.Format,
+		Immutable:            schema.Immutable,
+		Const:                schema.Const,
+		Default:              schema.Default,
+		OneOf:                oneOf,
+		Discriminator:        discriminator(oneOf),
+		Conditions:           schemaConditions(schema, defs),
+		DependentRequired:    schema.DependentRequired,
+		AdditionalProperties: additionalProperties(schema.AdditionalProperties, defs),
+		PatternProperties:    patternProperties(schema.PatternProperties, defs),
 	}
 }
 
@@ -150,7 +147,7 @@ func mapHelmPropertyTypeToFieldType(property helm.Property) string {
 	case "array":
 		return "array"
 	case "object":
-		if len(property.Properties) == 0 {
+		if len(property.Properties) == 0 && len(property.OneOf) == 0 {
 			return "map"
 		}
 
@@ -164,6 +161,11 @@ func mapHelmPropertyTypeToFieldType(property helm.Property) string {
 			return "array"
 		}
 
+		if len(property.PatternProperties) > 0 ||
+			(property.AdditionalProperties != nil && property.AdditionalProperties.Schema != nil) {
+			return "map"
+		}
+
 		return string(property.Type)
 	}
 }
@@ -173,7 +175,7 @@ func arrayItem(item *helm.Property, defs map[string]helm.Property) *models.Field
 		return nil
 	}
 
-	if len(item.Type) == 0 {
+	if untyped(*item) {
 		return &models.Field{
 			Type: "string",
 		}
@@ -183,6 +185,18 @@ func arrayItem(item *helm.Property, defs map[string]helm.Property) *models.Field
 	return &field
 }
 
+// untyped returns true for schemas that accept any value, which are mapped to
+// strings
+func untyped(schema helm.Property) bool {
+	return len(schema.Type) == 0 &&
+		!schema.HasRef() &&
+		len(schema.Properties) == 0 &&
+		schema.Items == nil &&
+		len(schema.AnyOf) == 0 &&
+		len(schema.OneOf) == 0 &&
+		len(schema.AllOf) == 0
+}
+
 func arrayRequired(item *helm.Property) []string {
 	if item == nil {
 		return nil
@@ -216,6 +230,15 @@ func resolveJSONSchemaRef(defs map[string]helm.Property, ref []string) helm.Prop
 	return resolveJSONSchemaRef(def.Properties, ref[1:])
 }
 
+func resolveRef(schema helm.Property, defs map[string]helm.Property) helm.Property {
+	if !schema.HasRef() {
+		return schema
+	}
+
+	key := strings.TrimPrefix(schema.Reference, "#/$defs/")
+	return resolveJSONSchemaRef(defs, strings.Split(key, "/"))
+}
+
 func shouldResolvePropertyComposition(schema helm.Property) bool {
 	return len(schema.AnyOf) != 0
 }
@@ -238,3 +261,269 @@ func resolvePropertyComposition(schema helm.Property) helm.Property {
 
 	return schema.AnyOf[0]
 }
+
+func oneOfFields(options []helm.Property, defs map[string]helm.Property) []models.Field {
+	if len(options) == 0 {
+		return nil
+	}
+
+	fields := make([]models.Field, 0, len(options))
+	for _, option := range options {
+		fields = append(fields, HelmSchemaToFields("", option, defs, nil))
+	}
+
+	return fields
+}
+
+// oneOfType returns the type of the union options if all of them have the same
+// type
+func oneOfType(options []models.Field) string {
+	if len(options) == 0 {
+		return ""
+	}
+
+	for _, option := range options[1:] {
+		if option.Type != options[0].Type {
+			return ""
+		}
+	}
+
+	return options[0].Type
+}
+
+// discriminator returns the name of the property that has a const value in
+// all union options, like kind or type
+func discriminator(options []models.Field) string {
+	if len(options) == 0 {
+		return ""
+	}
+
+	for _, property := range options[0].Properties {
+		if property.Const == nil {
+			continue
+		}
+
+		if allOptionsHaveConst(options[1:], property.Name) {
+			return property.Name
+		}
+	}
+
+	return ""
+}
+
+func allOptionsHaveConst(options []models.Field, name string) bool {
+	for _, option := range options {
+		found := false
+		for _, property := range option.Properties {
+			if property.Name == name && property.Const != nil {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// schemaConditions maps if/then/else subschemas of the schema and of the
+// schemas in its allOf
+func schemaConditions(schema helm.Property, defs map[string]helm.Property) []models.FieldCondition {
+	conditions := make([]models.FieldCondition, 0)
+
+	if schema.If != nil {
+		conditions = append(conditions, models.FieldCondition{
+			If:   HelmSchemaToFields("", *schema.If, defs, nil),
+			Then: optionalField(schema.Then, defs),
+			Else: optionalField(schema.Else, defs),
+		})
+	}
+
+	for _, member := range schema.AllOf {
+		conditions = append(conditions, schemaConditions(resolveRef(member, defs), defs)...)
+	}
+
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	return conditions
+}
+
+func optionalField(schema *helm.Property, defs map[string]helm.Property) *models.Field {
+	if schema == nil {
+		return nil
+	}
+
+	field := HelmSchemaToFields("", *schema, defs, nil)
+	return &field
+}
+
+func additionalProperties(additional *helm.AdditionalProperties, defs map[string]helm.Property) *models.Field {
+	if additional == nil {
+		return nil
+	}
+
+	return optionalField(additional.Schema, defs)
+}
+
+func patternProperties(properties map[string]helm.Property, defs map[string]helm.Property) map[string]models.Field {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]models.Field, len(properties))
+	for pattern, property := range properties {
+		fields[pattern] = HelmSchemaToFields("", property, defs, nil)
+	}
+
+	return fields
+}
+
+// mergeAllOf merges the schemas in allOf into the schema. Conditional
+// subschemas of allOf are not merged, they are mapped by schemaConditions.
+func mergeAllOf(schema helm.Property, defs map[string]helm.Property) helm.Property {
+	merged := schema
+	merged.AllOf = nil
+
+	for _, member := range schema.AllOf {
+		member = resolveRef(member, defs)
+		if len(member.AllOf) != 0 {
+			member = mergeAllOf(member, defs)
+		}
+
+		member.If, member.Then, member.Else = nil, nil, nil
+		merged = mergeProperties(merged, member)
+	}
+
+	return merged
+}
+
+// mergeProperties merges b into a. Values set on a take precedence, while
+// properties, required fields and unions of both are combined.
+func mergeProperties(a, b helm.Property) helm.Property {
+	merged := a
+
+	if len(merged.Type) == 0 {
+		merged.Type = b.Type
+	}
+	if len(merged.Title) == 0 {
+		merged.Title = b.Title
+	}
+	if len(merged.Description) == 0 {
+		merged.Description = b.Description
+	}
+	if len(merged.Format) == 0 {
+		merged.Format = b.Format
+	}
+	if len(merged.FileExtension) == 0 {
+		merged.FileExtension = b.FileExtension
+	}
+	if len(merged.Enum) == 0 {
+		merged.Enum = b.Enum
+	}
+	if len(merged.Suggestions) == 0 {
+		merged.Suggestions = b.Suggestions
+	}
+	if merged.Items == nil {
+		merged.Items = b.Items
+	}
+	if merged.Const == nil {
+		merged.Const = b.Const
+	}
+	if merged.Default == nil {
+		merged.Default = b.Default
+	}
+	if merged.AdditionalProperties == nil {
+		merged.AdditionalProperties = b.AdditionalProperties
+	}
+	if merged.Minimum == nil {
+		merged.Minimum = b.Minimum
+	}
+	if merged.Maximum == nil {
+		merged.Maximum = b.Maximum
+	}
+	if merged.ExclusiveMinimum == nil {
+		merged.ExclusiveMinimum = b.ExclusiveMinimum
+	}
+	if merged.ExclusiveMaximum == nil {
+		merged.ExclusiveMaximum = b.ExclusiveMaximum
+	}
+	if merged.MultipleOf == nil {
+		merged.MultipleOf = b.MultipleOf
+	}
+	if merged.MinLength == nil {
+		merged.MinLength = b.MinLength
+	}
+	if merged.MaxLength == nil {
+		merged.MaxLength = b.MaxLength
+	}
+	if merged.Pattern == nil {
+		merged.Pattern = b.Pattern
+	}
+	merged.Immutable = a.Immutable || b.Immutable
+
+	if len(b.Properties) != 0 {
+		merged.Properties = make(map[string]helm.Property, len(a.Properties)+len(b.Properties))
+		for name, property := range a.Properties {
+			merged.Properties[name] = property
+		}
+		for name, property := range b.Properties {
+			if existing, ok := merged.Properties[name]; ok {
+				property = mergeProperties(existing, property)
+			}
+			merged.Properties[name] = property
+		}
+	}
+
+	if len(b.PatternProperties) != 0 {
+		merged.PatternProperties = make(map[string]helm.Property, len(a.PatternProperties)+len(b.PatternProperties))
+		for pattern, property := range a.PatternProperties {
+			merged.PatternProperties[pattern] = property
+		}
+		for pattern, property := range b.PatternProperties {
+			if _, ok := merged.PatternProperties[pattern]; !ok {
+				merged.PatternProperties[pattern] = property
+			}
+		}
+	}
+
+	if len(b.DependentRequired) != 0 {
+		merged.DependentRequired = make(map[string][]string, len(a.DependentRequired)+len(b.DependentRequired))
+		for name, required := range a.DependentRequired {
+			merged.DependentRequired[name] = required
+		}
+		for name, required := range b.DependentRequired {
+			merged.DependentRequired[name] = union(merged.DependentRequired[name], required)
+		}
+	}
+
+	merged.Order = union(a.Order, b.Order)
+	merged.Required = union(a.Required, b.Required)
+	merged.AnyOf = append(append([]helm.Property{}, a.AnyOf...), b.AnyOf...)
+	merged.OneOf = append(append([]helm.Property{}, a.OneOf...), b.OneOf...)
+
+	return merged
+}
+
+func union(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+
+	values := make([]string, 0, len(a)+len(b))
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for _, value := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+
+		seen[value] = struct{}{}
+		values = append(values, value)
+	}
+
+	return values
+}
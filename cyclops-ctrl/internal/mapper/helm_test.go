@@ -1,9 +1,11 @@
 package mapper
 
 import (
+	json "github.com/json-iterator/go"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/helm"
 )
 
@@ -141,4 +143,202 @@ var _ = Describe("Helm mapper test", func() {
 			})
 		})
 	})
+
+	Describe("HelmSchemaToFields test", func() {
+		toFields := func(schema string) models.Field {
+			var property helm.Property
+			Expect(json.Unmarshal([]byte(schema), &property)).To(Succeed())
+
+			return HelmSchemaToFields("", property, property.Definitions, nil)
+		}
+
+		property := func(field models.Field, name string) models.Field {
+			for _, p := range field.Properties {
+				if p.Name == name {
+					return p
+				}
+			}
+
+			Fail("missing property " + name)
+			return models.Field{}
+		}
+
+		It("maps const, default and format", func() {
+			field := toFields(`{
+				"type": "object",
+				"properties": {
+					"apiVersion": {"const": "v1"},
+					"replicas": {"type": "integer", "default": 3},
+					"ports": {"type": "array", "default": [80]},
+					"email": {"type": "string", "format": "email"}
+				}
+			}`)
+
+			Expect(property(field, "apiVersion").Const).To(Equal("v1"))
+			Expect(property(field, "replicas").Default).To(BeEquivalentTo(3))
+			Expect(property(field, "ports").Default).To(HaveLen(1))
+			Expect(property(field, "email").Format).To(Equal("email"))
+		})
+
+		It("maps discriminated unions", func() {
+			field := toFields(`{
+				"type": "object",
+				"properties": {
+					"storage": {
+						"type": "object",
+						"oneOf": [
+							{
+								"title": "S3",
+								"properties": {
+									"kind": {"const": "s3"},
+									"bucket": {"type": "string"}
+								}
+							},
+							{"$ref": "#/$defs/disk"}
+						]
+					}
+				},
+				"$defs": {
+					"disk": {
+						"title": "Disk",
+						"properties": {
+							"kind": {"const": "disk"},
+							"size": {"type": "string"}
+						}
+					}
+				}
+			}`)
+
+			storage := property(field, "storage")
+			Expect(storage.Type).To(Equal("object"))
+			Expect(storage.Discriminator).To(Equal("kind"))
+			Expect(storage.OneOf).To(HaveLen(2))
+			Expect(storage.OneOf[0].DisplayName).To(Equal("S3"))
+			Expect(property(storage.OneOf[1], "kind").Const).To(Equal("disk"))
+			Expect(property(storage.OneOf[1], "size").Type).To(Equal("string"))
+		})
+
+		It("infers the type of unions without one", func() {
+			field := toFields(`{
+				"properties": {
+					"port": {"oneOf": [{"type": "integer", "minimum": 1}, {"type": "integer", "maximum": 0}]},
+					"value": {"oneOf": [{"type": "integer"}, {"type": "string"}]}
+				}
+			}`)
+
+			Expect(property(field, "port").Type).To(Equal("number"))
+			Expect(property(field, "value").Type).To(BeEmpty())
+			Expect(property(field, "value").Discriminator).To(BeEmpty())
+		})
+
+		It("merges allOf schemas", func() {
+			field := toFields(`{
+				"type": "object",
+				"properties": {
+					"service": {
+						"allOf": [
+							{"$ref": "#/$defs/named"},
+							{
+								"type": "object",
+								"required": ["port"],
+								"properties": {
+									"name": {"immutable": true},
+									"port": {"type": "integer"}
+								}
+							}
+						]
+					}
+				},
+				"$defs": {
+					"named": {
+						"required": ["name"],
+						"properties": {"name": {"type": "string"}}
+					}
+				}
+			}`)
+
+			service := property(field, "service")
+			Expect(service.Type).To(Equal("object"))
+			Expect(service.Required).To(Equal([]string{"name", "port"}))
+			Expect(property(service, "name").Type).To(Equal("string"))
+			Expect(property(service, "name").Immutable).To(BeTrue())
+			Expect(property(service, "port").Type).To(Equal("number"))
+		})
+
+		It("maps conditional subschemas", func() {
+			field := toFields(`{
+				"type": "object",
+				"properties": {
+					"ingress": {"type": "boolean"},
+					"host": {"type": "string"},
+					"tls": {"type": "boolean"},
+					"secretName": {"type": "string"}
+				},
+				"if": {"properties": {"ingress": {"const": true}}},
+				"then": {"required": ["host"]},
+				"else": {"properties": {"host": {"maxLength": 0}}},
+				"allOf": [
+					{
+						"if": {"properties": {"tls": {"const": true}}},
+						"then": {"required": ["secretName"]}
+					}
+				],
+				"dependentRequired": {"tls": ["host"]}
+			}`)
+
+			Expect(field.Properties).To(HaveLen(4))
+			Expect(field.Conditions).To(HaveLen(2))
+			Expect(property(field.Conditions[0].If, "ingress").Const).To(Equal(true))
+			Expect(field.Conditions[0].Then.Required).To(Equal([]string{"host"}))
+			Expect(*property(*field.Conditions[0].Else, "host").MaxLength).To(Equal(0))
+			Expect(property(field.Conditions[1].If, "tls").Const).To(Equal(true))
+			Expect(field.Conditions[1].Then.Required).To(Equal([]string{"secretName"}))
+			Expect(field.Conditions[1].Else).To(BeNil())
+			Expect(field.DependentRequired).To(Equal(map[string][]string{"tls": {"host"}}))
+		})
+
+		It("maps maps of objects", func() {
+			field := toFields(`{
+				"type": "object",
+				"properties": {
+					"volumes": {
+						"type": "object",
+						"additionalProperties": {
+							"type": "object",
+							"properties": {"size": {"type": "string", "immutable": true}}
+						}
+					},
+					"labels": {
+						"type": "object",
+						"additionalProperties": false,
+						"patternProperties": {"^app\\.": {"type": "string"}}
+					},
+					"annotations": {"additionalProperties": {"type": "string"}}
+				}
+			}`)
+
+			volumes := property(field, "volumes")
+			Expect(volumes.Type).To(Equal("map"))
+			Expect(volumes.AdditionalProperties.Type).To(Equal("object"))
+			Expect(property(*volumes.AdditionalProperties, "size").Immutable).To(BeTrue())
+
+			labels := property(field, "labels")
+			Expect(labels.Type).To(Equal("map"))
+			Expect(labels.AdditionalProperties).To(BeNil())
+			Expect(labels.PatternProperties).To(HaveKey(`^app\.`))
+			Expect(labels.PatternProperties[`^app\.`].Type).To(Equal("string"))
+
+			Expect(property(field, "annotations").Type).To(Equal("map"))
+		})
+
+		It("maps array items of unions", func() {
+			field := toFields(`{
+				"type": "array",
+				"items": {"oneOf": [{"type": "string"}, {"type": "string", "format": "uri"}]}
+			}`)
+
+			Expect(field.Items.Type).To(Equal("string"))
+			Expect(field.Items.OneOf).To(HaveLen(2))
+		})
+	})
 })
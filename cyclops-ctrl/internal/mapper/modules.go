@@ -44,6 +44,7 @@ func RequestToModule(req dto.Module) (cyclopsv1alpha1.Module, error) {
 			Values: apiextensionsv1.JSON{
 				Raw: data,
 			},
+			DependsOn: dependsOnToK8s(req.DependsOn),
 		},
 		History: make([]cyclopsv1alpha1.HistoryEntry, 0),
 	}, nil
@@ -57,6 +58,7 @@ func ModuleToDTO(module cyclopsv1alpha1.Module) (dto.Module, error) {
 		Version:              module.Spec.TemplateRef.Version,
 		Template:             k8sTemplateRefToDTO(module.Spec.TemplateRef, module.Status.TemplateResolvedVersion),
 		Values:               module.Spec.Values,
+		DependsOn:            dependsOnToDTO(module.Spec.DependsOn),
 		IconURL:              module.Status.IconURL,
 		GitOpsWrite:          mapGitOpsWrite(module),
 		ReconciliationStatus: ReconciliationStatusToDTO(module.Status.ReconciliationStatus),
@@ -94,6 +96,28 @@ func ModuleListToDTO(modules []cyclopsv1alpha1.Module) []dto.Module {
 	return out
 }
 
+func dependsOnToK8s(dependsOn []string) []cyclopsv1alpha1.ModuleReference {
+	if len(dependsOn) == 0 {
+		return nil
+	}
+
+	out := make([]cyclopsv1alpha1.ModuleReference, 0, len(dependsOn))
+	for _, name := range dependsOn {
+		out = append(out, cyclopsv1alpha1.ModuleReference{Name: name})
+	}
+
+	return out
+}
+
+func dependsOnToDTO(dependsOn []cyclopsv1alpha1.ModuleReference) []string {
+	out := make([]string, 0, len(dependsOn))
+	for _, dependency := range dependsOn {
+		out = append(out, dependency.Name)
+	}
+
+	return out
+}
+
 func DtoTemplateRefToK8s(dto dto.Template) cyclopsv1alpha1.TemplateRef {
 	return cyclopsv1alpha1.TemplateRef{
 		URL:        dto.URL,
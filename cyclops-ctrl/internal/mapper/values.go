@@ -0,0 +1,32 @@
+package mapper
+
+import (
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/values"
+)
+
+// ValuesField maps a template field to the field values are converted to
+func ValuesField(field models.Field) values.Field {
+	properties := make([]values.Field, 0, len(field.Properties))
+	for _, property := range field.Properties {
+		properties = append(properties, ValuesField(property))
+	}
+
+	out := values.Field{
+		Name:       field.Name,
+		Type:       field.Type,
+		Properties: properties,
+	}
+
+	if field.Items != nil {
+		items := ValuesField(*field.Items)
+		out.Items = &items
+	}
+
+	if field.AdditionalProperties != nil {
+		additionalProperties := ValuesField(*field.AdditionalProperties)
+		out.AdditionalProperties = &additionalProperties
+	}
+
+	return out
+}
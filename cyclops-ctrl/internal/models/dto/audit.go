@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+)
+
+// AuditEntry records a single mutating API request
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor,omitempty"`
+	Groups    []string  `json:"groups,omitempty"`
+	ClientIP  string    `json:"clientIP,omitempty"`
+
+	Method string `json:"method"`
+	Route  string `json:"route"`
+	Path   string `json:"path"`
+
+	Module   string         `json:"module,omitempty"`
+	Resource *AuditResource `json:"resource,omitempty"`
+	Diff     []JSONPatch    `json:"diff,omitempty"`
+
+	Status    int          `json:"status"`
+	Outcome   AuditOutcome `json:"outcome"`
+	Error     string       `json:"error,omitempty"`
+	LatencyMs int64        `json:"latencyMs"`
+}
+
+type AuditResource struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+type ResourceDiffAction string
+
+const (
+	ResourceAdded   ResourceDiffAction = "added"
+	ResourceChanged ResourceDiffAction = "changed"
+	ResourceRemoved ResourceDiffAction = "removed"
+)
+
+type ModuleDiff struct {
+	Resources []ResourceDiff `json:"resources"`
+}
+
+type ResourceDiff struct {
+	Group     string             `json:"group"`
+	Version   string             `json:"version"`
+	Kind      string             `json:"kind"`
+	Name      string             `json:"name"`
+	Namespace string             `json:"namespace"`
+	Action    ResourceDiffAction `json:"action,omitempty"`
+	Patch     []JSONPatch        `json:"patch,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// JSONPatch is a single RFC 6902 operation transforming the live object into
+// the proposed one
+type JSONPatch struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
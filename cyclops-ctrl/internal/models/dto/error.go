@@ -21,3 +21,16 @@ func NewResponse(message string) Response {
 		Message: message,
 	}
 }
+
+// ValidationError is returned for Module values that do not match the template
+// schema
+type ValidationError struct {
+	Message     string       `json:"message"`
+	Description string       `json:"description"`
+	Fields      []FieldError `json:"fields"`
+}
+
+type FieldError struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
@@ -8,6 +8,7 @@ type Module struct {
 	Template             Template             `json:"template"`
 	Version              string               `json:"version"`
 	Values               interface{}          `json:"values"`
+	DependsOn            []string             `json:"dependsOn,omitempty"`
 	Status               string               `json:"status"`
 	IconURL              string               `json:"iconURL"`
 	ReconciliationStatus ReconciliationStatus `json:"reconciliationStatus"`
@@ -42,6 +43,39 @@ type GitOpsWrite struct {
 	Branch string `json:"branch"`
 }
 
+// GitOpsWriteResponse is returned for changes written to git. PullRequestURL
+// is set if the changes were proposed in a pull request.
+type GitOpsWriteResponse struct {
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+}
+
+// ModuleDrift is the difference between a Module in the cluster and the Module
+// committed to git. Diff patches the Module spec in git to the one in the
+// cluster.
+type ModuleDrift struct {
+	Drifted bool        `json:"drifted"`
+	Missing bool        `json:"missing"`
+	Diff    []JSONPatch `json:"diff"`
+}
+
+type ModuleDependencyGraph struct {
+	Nodes []ModuleDependencyNode `json:"nodes"`
+	Edges []ModuleDependencyEdge `json:"edges"`
+}
+
+type ModuleDependencyNode struct {
+	Name  string   `json:"name"`
+	Ready bool     `json:"ready"`
+	Cycle []string `json:"cycle,omitempty"`
+}
+
+// ModuleDependencyEdge points from a Module to a Module it depends on
+type ModuleDependencyEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Missing bool   `json:"missing"`
+}
+
 type TemplatesResponse struct {
 	Current string `json:"current"`
 	New     string `json:"new"`
@@ -52,6 +86,20 @@ type RollbackRequest struct {
 	Generation int64  `json:"generation"`
 }
 
+// ModuleValuesRequest changes Module values the way Helm sets chart values.
+// Values files are merged in order, then values set with setJSON, set,
+// setString and setFile are applied. Files contains the contents of files
+// referenced by setFile.
+type ModuleValuesRequest struct {
+	ValuesFiles []string          `json:"valuesFiles"`
+	Set         []string          `json:"set"`
+	SetString   []string          `json:"setString"`
+	SetJSON     []string          `json:"setJSON"`
+	SetFile     []string          `json:"setFile"`
+	Files       map[string]string `json:"files"`
+	Reset       bool              `json:"reset"`
+}
+
 type DeleteResource struct {
 	Group     string `json:"group"`
 	Version   string `json:"version"`
@@ -0,0 +1,6 @@
+package dto
+
+// TemplatePushResponse lists the Modules refreshed after a template push
+type TemplatePushResponse struct {
+	Modules []string `json:"modules"`
+}
@@ -35,6 +35,46 @@ type Property struct {
 
 	// schema compositions
 	AnyOf []Property `json:"anyOf"`
+	OneOf []Property `json:"oneOf"`
+	AllOf []Property `json:"allOf"`
+
+	// conditional subschemas
+	If                *Property           `json:"if"`
+	Then              *Property           `json:"then"`
+	Else              *Property           `json:"else"`
+	DependentRequired map[string][]string `json:"dependentRequired"`
+
+	// maps
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties"`
+	PatternProperties    map[string]Property   `json:"patternProperties"`
+
+	Const   interface{} `json:"const"`
+	Default interface{} `json:"default"`
+	Format  string      `json:"format"`
+}
+
+// AdditionalProperties is either a boolean allowing or disallowing properties
+// that are not listed in the schema, or the schema of those properties
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Property
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		a.Allowed = allowed
+		return nil
+	}
+
+	var schema Property
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return err
+	}
+
+	a.Allowed = true
+	a.Schema = &schema
+	return nil
 }
 
 type PropertyType string
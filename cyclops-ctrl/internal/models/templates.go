@@ -27,6 +27,7 @@ type Template struct {
 
 	Dependencies []*Template `json:"dependencies"`
 	Condition    string      `json:"condition"`
+	Alias        string      `json:"alias,omitempty"`
 }
 
 type Field struct {
@@ -55,4 +56,30 @@ type Field struct {
 	MinLength *int    `json:"minLength"`
 	MaxLength *int    `json:"maxLength"`
 	Pattern   *string `json:"pattern"`
+	Format    string  `json:"format,omitempty"`
+
+	Const   interface{} `json:"const,omitempty"`
+	Default interface{} `json:"default,omitempty"`
+
+	// OneOf lists the options of a union. Discriminator is the property whose
+	// const value selects the option, if all options have one.
+	OneOf         []Field `json:"oneOf,omitempty"`
+	Discriminator string  `json:"discriminator,omitempty"`
+
+	// Conditions are if/then/else subschemas, applied in order
+	Conditions        []FieldCondition    `json:"conditions,omitempty"`
+	DependentRequired map[string][]string `json:"dependentRequired,omitempty"`
+
+	// AdditionalProperties is the field of values in a map, and
+	// PatternProperties of values whose keys match the pattern
+	AdditionalProperties *Field           `json:"additionalProperties,omitempty"`
+	PatternProperties    map[string]Field `json:"patternProperties,omitempty"`
+}
+
+// FieldCondition applies Then to the field if its value matches If, and Else
+// otherwise
+type FieldCondition struct {
+	If   Field  `json:"if"`
+	Then *Field `json:"then,omitempty"`
+	Else *Field `json:"else,omitempty"`
 }
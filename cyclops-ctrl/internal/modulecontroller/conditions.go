@@ -0,0 +1,150 @@
+package modulecontroller
+
+import (
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+// healthCheckInterval is how often a Module is requeued while its workloads
+// have not settled
+const healthCheckInterval = 15 * time.Second
+
+// maxHealthCheckInterval caps the backoff of health checks for Modules with
+// unhealthy workloads
+const maxHealthCheckInterval = 10 * time.Minute
+
+const (
+	reasonReconciliationSucceeded = "ReconciliationSucceeded"
+	reasonReconciliationFailed    = "ReconciliationFailed"
+	reasonBlocked                 = "Blocked"
+	reasonVerificationFailed      = "TemplateVerificationFailed"
+	reasonValuesInvalid           = "ValuesValidationFailed"
+	reasonImmutableValuesChanged  = "ImmutableValuesChanged"
+	reasonResourcesHealthy        = "ResourcesHealthy"
+	reasonResourcesProgressing    = "ResourcesProgressing"
+	reasonResourcesUnhealthy      = "ResourcesUnhealthy"
+	reasonNoWorkloads             = "NoWorkloads"
+	reasonHealthUnknown           = "HealthUnknown"
+)
+
+// moduleConditions updates the Module conditions with the reconciliation result
+// and the health of Module workloads. An empty resourcesHealth means the health
+// could not be determined.
+func moduleConditions(
+	existing []metav1.Condition,
+	generation int64,
+	status cyclopsv1alpha1.ReconciliationStatusState,
+	reason string,
+	errors []string,
+	resourcesHealth string,
+) []metav1.Condition {
+	conditions := make([]metav1.Condition, 0, len(existing))
+	for _, c := range existing {
+		conditions = append(conditions, *c.DeepCopy())
+	}
+
+	set := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	if status == cyclopsv1alpha1.Unknown && reason == reasonBlocked {
+		message := strings.Join(errors, "; ")
+		set(cyclopsv1alpha1.ModuleReconciled, metav1.ConditionFalse, reasonBlocked, message)
+		set(cyclopsv1alpha1.ModuleReady, metav1.ConditionFalse, reasonBlocked, message)
+		set(cyclopsv1alpha1.ModuleProgressing, metav1.ConditionFalse, reasonBlocked, "")
+		set(cyclopsv1alpha1.ModuleDegraded, metav1.ConditionFalse, reasonBlocked, "")
+		return conditions
+	}
+
+	if status == cyclopsv1alpha1.Failed && (reason == reasonVerificationFailed || reason == reasonValuesInvalid || reason == reasonImmutableValuesChanged) {
+		message := strings.Join(errors, "; ")
+		set(cyclopsv1alpha1.ModuleReconciled, metav1.ConditionFalse, reason, message)
+		set(cyclopsv1alpha1.ModuleReady, metav1.ConditionFalse, reason, message)
+		set(cyclopsv1alpha1.ModuleProgressing, metav1.ConditionFalse, reason, "")
+		set(cyclopsv1alpha1.ModuleDegraded, metav1.ConditionTrue, reason, message)
+		return conditions
+	}
+
+	if status != cyclopsv1alpha1.Succeeded {
+		set(cyclopsv1alpha1.ModuleReconciled, metav1.ConditionFalse, reasonReconciliationFailed, reason)
+		set(cyclopsv1alpha1.ModuleReady, metav1.ConditionFalse, reasonReconciliationFailed, reason)
+		set(cyclopsv1alpha1.ModuleProgressing, metav1.ConditionFalse, reasonReconciliationFailed, reason)
+		set(cyclopsv1alpha1.ModuleDegraded, metav1.ConditionTrue, reasonReconciliationFailed, reason)
+		return conditions
+	}
+
+	set(cyclopsv1alpha1.ModuleReconciled, metav1.ConditionTrue, reasonReconciliationSucceeded, "all module resources applied")
+
+	switch resourcesHealth {
+	case k8sclient.HealthHealthy:
+		set(cyclopsv1alpha1.ModuleReady, metav1.ConditionTrue, reasonResourcesHealthy, "all module workloads are available")
+		set(cyclopsv1alpha1.ModuleProgressing, metav1.ConditionFalse, reasonResourcesHealthy, "")
+		set(cyclopsv1alpha1.ModuleDegraded, metav1.ConditionFalse, reasonResourcesHealthy, "")
+	case k8sclient.HealthUnknown:
+		set(cyclopsv1alpha1.ModuleReady, metav1.ConditionTrue, reasonNoWorkloads, "module has no workloads to wait for")
+		set(cyclopsv1alpha1.ModuleProgressing, metav1.ConditionFalse, reasonNoWorkloads, "")
+		set(cyclopsv1alpha1.ModuleDegraded, metav1.ConditionFalse, reasonNoWorkloads, "")
+	case k8sclient.HealthProgressing:
+		set(cyclopsv1alpha1.ModuleReady, metav1.ConditionFalse, reasonResourcesProgressing, "module workloads are rolling out")
+		set(cyclopsv1alpha1.ModuleProgressing, metav1.ConditionTrue, reasonResourcesProgressing, "module workloads are rolling out")
+		set(cyclopsv1alpha1.ModuleDegraded, metav1.ConditionFalse, reasonResourcesProgressing, "")
+	case k8sclient.HealthUnhealthy:
+		set(cyclopsv1alpha1.ModuleReady, metav1.ConditionFalse, reasonResourcesUnhealthy, "module workloads are unavailable")
+		set(cyclopsv1alpha1.ModuleProgressing, metav1.ConditionFalse, reasonResourcesUnhealthy, "")
+		set(cyclopsv1alpha1.ModuleDegraded, metav1.ConditionTrue, reasonResourcesUnhealthy, "module workloads are unavailable")
+	default:
+		set(cyclopsv1alpha1.ModuleReady, metav1.ConditionUnknown, reasonHealthUnknown, "failed to check module workloads health")
+		set(cyclopsv1alpha1.ModuleProgressing, metav1.ConditionUnknown, reasonHealthUnknown, "")
+		set(cyclopsv1alpha1.ModuleDegraded, metav1.ConditionUnknown, reasonHealthUnknown, "")
+	}
+
+	return conditions
+}
+
+// settled reports whether Module workloads reached a final state, so there is no
+// need to requeue the Module to observe their health
+func settled(resourcesHealth string) bool {
+	return resourcesHealth == k8sclient.HealthHealthy || resourcesHealth == k8sclient.HealthUnknown
+}
+
+// healthCheckAfter returns when the Module should be requeued to check the
+// health of its workloads again, or zero if they settled. Unhealthy workloads
+// are checked again after the time the Module has been degraded, so the checks
+// back off exponentially.
+func healthCheckAfter(resourcesHealth string, existing []metav1.Condition, now time.Time) time.Duration {
+	if settled(resourcesHealth) {
+		return 0
+	}
+
+	if resourcesHealth != k8sclient.HealthUnhealthy {
+		return healthCheckInterval
+	}
+
+	degraded := meta.FindStatusCondition(existing, cyclopsv1alpha1.ModuleDegraded)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue {
+		return healthCheckInterval
+	}
+
+	after := now.Sub(degraded.LastTransitionTime.Time)
+	if after < healthCheckInterval {
+		return healthCheckInterval
+	}
+
+	if after > maxHealthCheckInterval {
+		return maxHealthCheckInterval
+	}
+
+	return after
+}
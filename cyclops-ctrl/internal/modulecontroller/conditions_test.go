@@ -0,0 +1,124 @@
+package modulecontroller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+var _ = Describe("Module conditions", func() {
+	type condition struct {
+		status  metav1.ConditionStatus
+		reason  string
+		message string
+	}
+
+	DescribeTable("sets conditions from the reconciliation result",
+		func(status cyclopsv1alpha1.ReconciliationStatusState, reason string, errors []string, resourcesHealth string, expected map[string]condition) {
+			conditions := moduleConditions(nil, 3, status, reason, errors, resourcesHealth)
+
+			actual := make(map[string]condition, len(conditions))
+			for _, c := range conditions {
+				Expect(c.ObservedGeneration).To(Equal(int64(3)))
+				actual[c.Type] = condition{status: c.Status, reason: c.Reason, message: c.Message}
+			}
+
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("blocked by dependencies",
+			cyclopsv1alpha1.Unknown, reasonBlocked, []string{"database", "cache"}, "",
+			map[string]condition{
+				cyclopsv1alpha1.ModuleReconciled:  {metav1.ConditionFalse, reasonBlocked, "database; cache"},
+				cyclopsv1alpha1.ModuleReady:       {metav1.ConditionFalse, reasonBlocked, "database; cache"},
+				cyclopsv1alpha1.ModuleProgressing: {metav1.ConditionFalse, reasonBlocked, ""},
+				cyclopsv1alpha1.ModuleDegraded:    {metav1.ConditionFalse, reasonBlocked, ""},
+			},
+		),
+		Entry("template verification failed",
+			cyclopsv1alpha1.Failed, reasonVerificationFailed, []string{"template verification failed: commit 3f2a1c"}, "",
+			map[string]condition{
+				cyclopsv1alpha1.ModuleReconciled:  {metav1.ConditionFalse, reasonVerificationFailed, "template verification failed: commit 3f2a1c"},
+				cyclopsv1alpha1.ModuleReady:       {metav1.ConditionFalse, reasonVerificationFailed, "template verification failed: commit 3f2a1c"},
+				cyclopsv1alpha1.ModuleProgressing: {metav1.ConditionFalse, reasonVerificationFailed, ""},
+				cyclopsv1alpha1.ModuleDegraded:    {metav1.ConditionTrue, reasonVerificationFailed, "template verification failed: commit 3f2a1c"},
+			},
+		),
+		Entry("values invalid",
+			cyclopsv1alpha1.Failed, reasonValuesInvalid, []string{"replicas: must be an integer", "name: is required"}, "",
+			map[string]condition{
+				cyclopsv1alpha1.ModuleReconciled:  {metav1.ConditionFalse, reasonValuesInvalid, "replicas: must be an integer; name: is required"},
+				cyclopsv1alpha1.ModuleReady:       {metav1.ConditionFalse, reasonValuesInvalid, "replicas: must be an integer; name: is required"},
+				cyclopsv1alpha1.ModuleProgressing: {metav1.ConditionFalse, reasonValuesInvalid, ""},
+				cyclopsv1alpha1.ModuleDegraded:    {metav1.ConditionTrue, reasonValuesInvalid, "replicas: must be an integer; name: is required"},
+			},
+		),
+		Entry("immutable values changed",
+			cyclopsv1alpha1.Failed, reasonImmutableValuesChanged, []string{"storage.class: field is immutable"}, "",
+			map[string]condition{
+				cyclopsv1alpha1.ModuleReconciled:  {metav1.ConditionFalse, reasonImmutableValuesChanged, "storage.class: field is immutable"},
+				cyclopsv1alpha1.ModuleReady:       {metav1.ConditionFalse, reasonImmutableValuesChanged, "storage.class: field is immutable"},
+				cyclopsv1alpha1.ModuleProgressing: {metav1.ConditionFalse, reasonImmutableValuesChanged, ""},
+				cyclopsv1alpha1.ModuleDegraded:    {metav1.ConditionTrue, reasonImmutableValuesChanged, "storage.class: field is immutable"},
+			},
+		),
+		Entry("reconciliation failed",
+			cyclopsv1alpha1.Failed, "error decoding/applying resources", []string{"v1/ConfigMap default/web failed to apply"}, "",
+			map[string]condition{
+				cyclopsv1alpha1.ModuleReconciled:  {metav1.ConditionFalse, reasonReconciliationFailed, "error decoding/applying resources"},
+				cyclopsv1alpha1.ModuleReady:       {metav1.ConditionFalse, reasonReconciliationFailed, "error decoding/applying resources"},
+				cyclopsv1alpha1.ModuleProgressing: {metav1.ConditionFalse, reasonReconciliationFailed, "error decoding/applying resources"},
+				cyclopsv1alpha1.ModuleDegraded:    {metav1.ConditionTrue, reasonReconciliationFailed, "error decoding/applying resources"},
+			},
+		),
+		Entry("healthy workloads",
+			cyclopsv1alpha1.Succeeded, "", nil, k8sclient.HealthHealthy,
+			map[string]condition{
+				cyclopsv1alpha1.ModuleReconciled:  {metav1.ConditionTrue, reasonReconciliationSucceeded, "all module resources applied"},
+				cyclopsv1alpha1.ModuleReady:       {metav1.ConditionTrue, reasonResourcesHealthy, "all module workloads are available"},
+				cyclopsv1alpha1.ModuleProgressing: {metav1.ConditionFalse, reasonResourcesHealthy, ""},
+				cyclopsv1alpha1.ModuleDegraded:    {metav1.ConditionFalse, reasonResourcesHealthy, ""},
+			},
+		),
+		Entry("unhealthy workloads",
+			cyclopsv1alpha1.Succeeded, "", nil, k8sclient.HealthUnhealthy,
+			map[string]condition{
+				cyclopsv1alpha1.ModuleReconciled:  {metav1.ConditionTrue, reasonReconciliationSucceeded, "all module resources applied"},
+				cyclopsv1alpha1.ModuleReady:       {metav1.ConditionFalse, reasonResourcesUnhealthy, "module workloads are unavailable"},
+				cyclopsv1alpha1.ModuleProgressing: {metav1.ConditionFalse, reasonResourcesUnhealthy, ""},
+				cyclopsv1alpha1.ModuleDegraded:    {metav1.ConditionTrue, reasonResourcesUnhealthy, "module workloads are unavailable"},
+			},
+		),
+	)
+
+	Describe("healthCheckAfter", func() {
+		now := time.Now()
+
+		degradedSince := func(since time.Duration) []metav1.Condition {
+			return []metav1.Condition{{
+				Type:               cyclopsv1alpha1.ModuleDegraded,
+				Status:             metav1.ConditionTrue,
+				Reason:             reasonResourcesUnhealthy,
+				LastTransitionTime: metav1.NewTime(now.Add(-since)),
+			}}
+		}
+
+		DescribeTable("requeues Modules until their workloads settle",
+			func(resourcesHealth string, existing []metav1.Condition, expected time.Duration) {
+				Expect(healthCheckAfter(resourcesHealth, existing, now)).To(Equal(expected))
+			},
+			Entry("healthy workloads", k8sclient.HealthHealthy, degradedSince(time.Hour), time.Duration(0)),
+			Entry("no workloads", k8sclient.HealthUnknown, nil, time.Duration(0)),
+			Entry("progressing workloads", k8sclient.HealthProgressing, nil, healthCheckInterval),
+			Entry("unknown health", "", nil, healthCheckInterval),
+			Entry("workloads that just became unhealthy", k8sclient.HealthUnhealthy, nil, healthCheckInterval),
+			Entry("workloads unhealthy for a short time", k8sclient.HealthUnhealthy, degradedSince(5*time.Second), healthCheckInterval),
+			Entry("workloads unhealthy for a minute", k8sclient.HealthUnhealthy, degradedSince(time.Minute), time.Minute),
+			Entry("workloads unhealthy for an hour", k8sclient.HealthUnhealthy, degradedSince(time.Hour), maxHealthCheckInterval),
+		)
+	})
+})
@@ -0,0 +1,78 @@
+package modulecontroller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/dependencies"
+)
+
+// checkDependencies returns the dependency cycle the Module is part of, or the
+// reasons why its dependencies are not Ready yet
+func (r *ModuleReconciler) checkDependencies(
+	ctx context.Context,
+	module cyclopsv1alpha1.Module,
+) ([]string, []string, error) {
+	if len(module.Spec.DependsOn) == 0 {
+		return nil, nil, nil
+	}
+
+	var modules cyclopsv1alpha1.ModuleList
+	if err := r.List(ctx, &modules, client.InNamespace(module.Namespace)); err != nil {
+		return nil, nil, err
+	}
+
+	if cycle := dependencies.NewGraph(modules.Items).Cycle(module.Name); cycle != nil {
+		return cycle, nil, nil
+	}
+
+	byName := make(map[string]cyclopsv1alpha1.Module, len(modules.Items))
+	for _, m := range modules.Items {
+		byName[m.Name] = m
+	}
+
+	notReady := make([]string, 0)
+	for _, dependency := range module.Spec.DependsOn {
+		d, ok := byName[dependency.Name]
+		if !ok {
+			notReady = append(notReady, fmt.Sprintf("waiting for module %v: module not found", dependency.Name))
+			continue
+		}
+
+		if !dependencies.Ready(d) {
+			notReady = append(notReady, fmt.Sprintf("waiting for module %v to be ready", dependency.Name))
+		}
+	}
+
+	return nil, notReady, nil
+}
+
+// dependentModules maps a Module event to reconcile requests for all Modules
+// depending on it, so blocked Modules proceed as soon as their dependencies
+// become Ready
+func (r *ModuleReconciler) dependentModules(ctx context.Context, obj client.Object) []reconcile.Request {
+	var modules cyclopsv1alpha1.ModuleList
+	if err := r.List(ctx, &modules, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.logger.Error(err, "failed to list modules for dependents", "module", obj.GetName())
+		return nil
+	}
+
+	dependents := dependencies.NewGraph(modules.Items).Dependents(obj.GetName())
+
+	requests := make([]reconcile.Request, 0, len(dependents))
+	for _, dependent := range dependents {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: obj.GetNamespace(),
+				Name:      dependent,
+			},
+		})
+	}
+
+	return requests
+}
@@ -18,6 +18,7 @@ package modulecontroller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -28,18 +29,23 @@ import (
 
 	"github.com/go-logr/logr"
 	"helm.sh/helm/v3/pkg/chart"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/history"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/immutable"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/prometheus"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/telemetry"
@@ -56,6 +62,7 @@ type ModuleReconciler struct {
 	renderer         *render.Renderer
 
 	maxConcurrentReconciles int
+	historyLimit            int
 
 	telemetryClient telemetry.Client
 	monitor         prometheus.Monitor
@@ -69,6 +76,7 @@ func NewModuleReconciler(
 	kubernetesClient k8sclient.IKubernetesClient,
 	renderer *render.Renderer,
 	maxConcurrentReconciles int,
+	historyLimit int,
 	telemetryClient telemetry.Client,
 	monitor prometheus.Monitor,
 ) *ModuleReconciler {
@@ -80,6 +88,7 @@ func NewModuleReconciler(
 		renderer:                renderer,
 		telemetryClient:         telemetryClient,
 		maxConcurrentReconciles: maxConcurrentReconciles,
+		historyLimit:            historyLimit,
 		monitor:                 monitor,
 		logger:                  ctrl.Log.WithName("reconciler"),
 	}
@@ -88,6 +97,8 @@ func NewModuleReconciler(
 //+kubebuilder:rbac:groups=cyclops-ui.com,resources=modules,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cyclops-ui.com,resources=modules/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=cyclops-ui.com,resources=modules/finalizers,verbs=update
+//+kubebuilder:rbac:groups=cyclops-ui.com,resources=modulerevisions,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=secrets;configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -140,6 +151,51 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 	r.logger.Info("upsert module", "namespaced name", req.NamespacedName)
 
+	cycle, notReady, err := r.checkDependencies(ctx, module)
+	if err != nil {
+		r.logger.Error(err, "error checking module dependencies", "namespaced name", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if len(cycle) != 0 {
+		r.monitor.OnFailedReconciliation()
+
+		reason := fmt.Sprintf("dependency cycle: %v", strings.Join(cycle, " -> "))
+		r.logger.Info("module dependency cycle", "namespaced name", req.NamespacedName, "cycle", cycle)
+
+		return ctrl.Result{}, r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Failed,
+			module.Status.TemplateResolvedVersion,
+			reason,
+			nil,
+			nil,
+			nil,
+			module.Status.IconURL,
+			"",
+		)
+	}
+
+	if len(notReady) != 0 {
+		r.logger.Info("module blocked by dependencies", "namespaced name", req.NamespacedName, "dependencies", notReady)
+
+		return ctrl.Result{}, r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Unknown,
+			module.Status.TemplateResolvedVersion,
+			reasonBlocked,
+			notReady,
+			nil,
+			nil,
+			module.Status.IconURL,
+			"",
+		)
+	}
+
 	templateVersion := module.Status.TemplateResolvedVersion
 	if len(templateVersion) == 0 {
 		templateVersion = module.Spec.TemplateRef.Version
@@ -155,7 +211,15 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	if err != nil {
 		r.logger.Error(err, "error fetching module template", "namespaced name", req.NamespacedName)
 
-		if err = r.setStatus(ctx, module, req.NamespacedName, cyclopsv1alpha1.Failed, templateVersion, err.Error(), nil, nil, ""); err != nil {
+		reason, reasonErrors := err.Error(), []string(nil)
+
+		// modules are not rendered from templates that fail verification
+		var verificationErr *templaterepo.VerificationError
+		if errors.As(err, &verificationErr) {
+			reason, reasonErrors = reasonVerificationFailed, []string{err.Error()}
+		}
+
+		if err = r.setStatus(ctx, module, req.NamespacedName, cyclopsv1alpha1.Failed, templateVersion, reason, reasonErrors, nil, nil, "", ""); err != nil {
 			return ctrl.Result{}, err
 		}
 
@@ -164,11 +228,44 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
-	installErrors, childrenResources, err := r.moduleToResources(template, &module)
+	if err := r.checkImmutableValues(ctx, module, template); err != nil {
+		var immutableErr *immutable.Error
+		if !errors.As(err, &immutableErr) {
+			r.logger.Error(err, "error checking immutable module values", "namespaced name", req.NamespacedName)
+			return ctrl.Result{}, err
+		}
+
+		r.logger.Info("immutable module values changed", "namespaced name", req.NamespacedName, "values", immutableErr.Paths)
+		r.monitor.OnFailedReconciliation()
+
+		// the Module is not applied until the values are changed back
+		return ctrl.Result{}, r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Failed,
+			template.ResolvedVersion,
+			reasonImmutableValuesChanged,
+			immutableErrors(immutableErr),
+			nil,
+			nil,
+			template.IconURL,
+			"",
+		)
+	}
+
+	installErrors, childrenResources, rendered, err := r.moduleToResources(template, &module)
 	if err != nil {
 		r.logger.Error(err, "error on upsert module", "namespaced name", req.NamespacedName)
 
-		if err = r.setStatus(ctx, module, req.NamespacedName, cyclopsv1alpha1.Failed, template.ResolvedVersion, err.Error(), nil, nil, template.IconURL); err != nil {
+		reason, reasonErrors := err.Error(), []string(nil)
+
+		var validationErr *render.ValidationError
+		if errors.As(err, &validationErr) {
+			reason, reasonErrors = reasonValuesInvalid, validationErrors(validationErr)
+		}
+
+		if err = r.setStatus(ctx, module, req.NamespacedName, cyclopsv1alpha1.Failed, template.ResolvedVersion, reason, reasonErrors, nil, nil, template.IconURL, ""); err != nil {
 			return ctrl.Result{}, err
 		}
 
@@ -198,11 +295,51 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			"error decoding/applying resources",
 			installErrors,
 			childrenResources,
+			nil,
 			template.IconURL,
+			"",
 		)
 	}
 
-	return ctrl.Result{}, r.setStatus(
+	r.recordRevision(ctx, module, template.ResolvedVersion, rendered.manifestDigest)
+
+	prunedResources, pruneErrors := r.pruneResources(module, childrenResources, rendered)
+	if len(pruneErrors) != 0 {
+		r.monitor.OnFailedReconciliation()
+
+		r.logger.Info("error pruning resources",
+			"module namespaced name",
+			module.Name,
+			"number of prune errors",
+			len(pruneErrors),
+			"prune errors",
+			pruneErrors,
+		)
+
+		return ctrl.Result{}, r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Failed,
+			template.ResolvedVersion,
+			"error pruning resources",
+			pruneErrors,
+			childrenResources,
+			prunedResources,
+			template.IconURL,
+			"",
+		)
+	}
+
+	resourcesHealth, err := r.kubernetesClient.GetModuleResourcesHealth(module.Name)
+	if err != nil {
+		r.logger.Error(err, "error checking module resources health", "namespaced name", req.NamespacedName)
+		resourcesHealth = ""
+	}
+
+	requeueAfter := healthCheckAfter(resourcesHealth, module.Status.Conditions, time.Now())
+
+	if err := r.setStatus(
 		ctx,
 		module,
 		req.NamespacedName,
@@ -211,8 +348,14 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		"",
 		nil,
 		childrenResources,
+		prunedResources,
 		template.IconURL,
-	)
+		resourcesHealth,
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -223,6 +366,20 @@ func (r *ModuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cyclopsv1alpha1.Module{}).
+		Watches(
+			&cyclopsv1alpha1.Module{},
+			handler.EnqueueRequestsFromMapFunc(r.dependentModules),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.modulesForValuesSource(cyclopsv1alpha1.ValuesFromKindSecret)),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.modulesForValuesSource(cyclopsv1alpha1.ValuesFromKindConfigMap)),
+			builder.OnlyMetadata,
+		).
 		WithOptions(controller.Options{
 			RateLimiter:             rateLimiter,
 			MaxConcurrentReconciles: r.maxConcurrentReconciles,
@@ -230,29 +387,33 @@ func (r *ModuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *ModuleReconciler) moduleToResources(template *models.Template, module *cyclopsv1alpha1.Module) ([]string, []cyclopsv1alpha1.GroupVersionResource, error) {
+func (r *ModuleReconciler) moduleToResources(
+	template *models.Template,
+	module *cyclopsv1alpha1.Module,
+) ([]string, []cyclopsv1alpha1.GroupVersionResource, *renderedResources, error) {
 	crdInstallErrors := r.applyCRDs(template)
 
-	installErrors, childrenGVRs, err := r.generateResources(r.kubernetesClient, *module, template)
+	installErrors, childrenGVRs, rendered, err := r.generateResources(r.kubernetesClient, *module, template)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	return append(crdInstallErrors, installErrors...), childrenGVRs, nil
+	return append(crdInstallErrors, installErrors...), childrenGVRs, rendered, nil
 }
 
 func (r *ModuleReconciler) generateResources(
 	kClient k8sclient.IKubernetesClient,
 	module cyclopsv1alpha1.Module,
 	moduleTemplate *models.Template,
-) ([]string, []cyclopsv1alpha1.GroupVersionResource, error) {
+) ([]string, []cyclopsv1alpha1.GroupVersionResource, *renderedResources, error) {
 	out, err := r.renderer.HelmTemplate(module, moduleTemplate)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	installErrors := make([]string, 0)
 	childrenGVRs := make([]cyclopsv1alpha1.GroupVersionResource, 0)
+	rendered := newRenderedResources(out)
 
 	for _, s := range strings.Split(out, "\n---\n") {
 		s := strings.TrimSpace(s)
@@ -320,7 +481,25 @@ func (r *ModuleReconciler) generateResources(
 		}
 		childrenGVRs = append(childrenGVRs, gvr)
 
-		if err := kClient.CreateDynamic(gvr, &obj, module.Spec.TargetNamespace); err != nil {
+		if err := kClient.CreateDynamic(gvr, &obj, module.Spec.TargetNamespace, forceConflicts(module)); err != nil {
+			var conflictErr *k8sclient.ApplyConflictError
+			if errors.As(err, &conflictErr) {
+				for _, conflict := range conflictErr.Conflicts {
+					installErrors = append(installErrors, fmt.Sprintf(
+						"%v%v/%v %v/%v failed to apply: field %v is managed by %v",
+						obj.GroupVersionKind().Group,
+						obj.GroupVersionKind().Version,
+						obj.GroupVersionKind().Kind,
+						obj.GetNamespace(),
+						obj.GetName(),
+						conflict.Field,
+						conflict.Manager,
+					))
+				}
+
+				continue
+			}
+
 			installErrors = append(installErrors, fmt.Sprintf(
 				"%v%v/%v %v/%v failed to apply: %v",
 				obj.GroupVersionKind().Group,
@@ -333,9 +512,83 @@ func (r *ModuleReconciler) generateResources(
 
 			continue
 		}
+
+		rendered.add(resourceName, &obj)
 	}
 
-	return installErrors, childrenGVRs, nil
+	return installErrors, childrenGVRs, rendered, nil
+}
+
+// recordRevision stores the Module generation in its history the first time all
+// of its resources are applied, so only applied revisions can be rolled back to
+func (r *ModuleReconciler) recordRevision(
+	ctx context.Context,
+	module cyclopsv1alpha1.Module,
+	resolvedVersion string,
+	manifestDigest string,
+) {
+	var revision cyclopsv1alpha1.ModuleRevision
+	err := r.Get(ctx, types.NamespacedName{
+		Namespace: module.Namespace,
+		Name:      history.RevisionName(module.Name, module.Generation),
+	}, &revision)
+	if err == nil {
+		return
+	}
+
+	if err = history.Record(r.kubernetesClient, module, resolvedVersion, manifestDigest, r.historyLimit); err != nil {
+		r.logger.Error(err, "error recording module revision", "module", module.Name, "generation", module.Generation)
+	}
+}
+
+// checkImmutableValues compares values of a Module generation that was not
+// applied yet with the values of the last applied revision, and returns an
+// immutable.Error if values the template marks as immutable changed
+func (r *ModuleReconciler) checkImmutableValues(ctx context.Context, module cyclopsv1alpha1.Module, template *models.Template) error {
+	var revision cyclopsv1alpha1.ModuleRevision
+	err := r.Get(ctx, types.NamespacedName{
+		Namespace: module.Namespace,
+		Name:      history.RevisionName(module.Name, module.Generation),
+	}, &revision)
+	if err == nil {
+		return nil
+	}
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	entries, err := history.Entries(r.kubernetesClient, module)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return immutable.ValidateRaw(template.RootField, entries[0].Values.Raw, module.Spec.Values.Raw)
+}
+
+func validationErrors(err *render.ValidationError) []string {
+	fieldErrors := make([]string, 0, len(err.Fields))
+	for _, field := range err.Fields {
+		fieldErrors = append(fieldErrors, fmt.Sprintf("%v: %v", field.Field, field.Description))
+	}
+
+	return fieldErrors
+}
+
+func immutableErrors(err *immutable.Error) []string {
+	fieldErrors := make([]string, 0, len(err.Paths))
+	for _, path := range err.Paths {
+		fieldErrors = append(fieldErrors, fmt.Sprintf("%v: field is immutable", path))
+	}
+
+	return fieldErrors
+}
+
+func forceConflicts(module cyclopsv1alpha1.Module) bool {
+	return module.GetAnnotations()[cyclopsv1alpha1.ForceConflictsAnnotation] == "true"
 }
 
 func (r *ModuleReconciler) applyCRDs(template *models.Template) []string {
@@ -463,7 +716,9 @@ func (r *ModuleReconciler) setStatus(
 	reason string,
 	installErrors []string,
 	childrenResources []cyclopsv1alpha1.GroupVersionResource,
+	prunedResources []cyclopsv1alpha1.PrunedResource,
 	iconURL string,
+	resourcesHealth string,
 ) error {
 	trv := module.Status.TemplateResolvedVersion
 	if len(trv) == 0 {
@@ -479,6 +734,16 @@ func (r *ModuleReconciler) setStatus(
 		ManagedGVRs:             r.mergeChildrenGVRs(module.Status.ManagedGVRs, childrenResources),
 		TemplateResolvedVersion: templateResolvedVersion,
 		IconURL:                 iconURL,
+		PrunedResources:         mergePrunedResources(module.Status.PrunedResources, prunedResources),
+		ObservedGeneration:      module.Generation,
+		Conditions: moduleConditions(
+			module.Status.Conditions,
+			module.Generation,
+			status,
+			reason,
+			installErrors,
+			resourcesHealth,
+		),
 	}
 
 	if err := r.Status().Update(ctx, &module); err != nil {
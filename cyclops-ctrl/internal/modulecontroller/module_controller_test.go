@@ -0,0 +1,25 @@
+package modulecontroller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+var _ = Describe("Force conflicts annotation", func() {
+	DescribeTable("forces conflicts only when the annotation is true",
+		func(annotations map[string]string, expected bool) {
+			module := cyclopsv1alpha1.Module{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Annotations: annotations},
+			}
+
+			Expect(forceConflicts(module)).To(Equal(expected))
+		},
+		Entry("without annotations", nil, false),
+		Entry("annotation set to true", map[string]string{cyclopsv1alpha1.ForceConflictsAnnotation: "true"}, true),
+		Entry("annotation set to false", map[string]string{cyclopsv1alpha1.ForceConflictsAnnotation: "false"}, false),
+		Entry("annotation set to another value", map[string]string{cyclopsv1alpha1.ForceConflictsAnnotation: "yes"}, false),
+	)
+})
@@ -0,0 +1,183 @@
+package modulecontroller
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/history"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+const prunedResourcesLimit = 20
+
+// resourceKey identifies a Module child resource regardless of the API version
+// it was rendered or listed with
+type resourceKey struct {
+	group     string
+	resource  string
+	namespace string
+	name      string
+}
+
+type renderedResources struct {
+	namespaced    map[resourceKey]struct{}
+	clusterScoped map[resourceKey]struct{}
+
+	manifestDigest string
+}
+
+func newRenderedResources(manifest string) *renderedResources {
+	return &renderedResources{
+		namespaced:     make(map[resourceKey]struct{}),
+		clusterScoped:  make(map[resourceKey]struct{}),
+		manifestDigest: history.ManifestDigest(manifest),
+	}
+}
+
+func (rr *renderedResources) add(resource string, obj *unstructured.Unstructured) {
+	key := resourceKey{
+		group:     obj.GroupVersionKind().Group,
+		resource:  resource,
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+	rr.namespaced[key] = struct{}{}
+
+	// cluster scoped resources are listed without a namespace, but are
+	// rendered with the target namespace set, so they are tracked by name only
+	key.namespace = ""
+	rr.clusterScoped[key] = struct{}{}
+}
+
+func (rr *renderedResources) contains(resource string, obj unstructured.Unstructured) bool {
+	key := resourceKey{
+		group:     obj.GroupVersionKind().Group,
+		resource:  resource,
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+
+	if len(key.namespace) == 0 {
+		_, ok := rr.clusterScoped[key]
+		return ok
+	}
+
+	_, ok := rr.namespaced[key]
+	return ok
+}
+
+// prunable reports whether an existing Module child resource should be pruned
+func (rr *renderedResources) prunable(resource string, obj unstructured.Unstructured) bool {
+	return !rr.contains(resource, obj) && k8sclient.Prunable(obj)
+}
+
+// pruneResources deletes all resources labeled as part of the Module that were
+// not rendered from the Module template in the current reconciliation. Resources
+// are looked up across all GVRs the Module has ever managed.
+func (r *ModuleReconciler) pruneResources(
+	module cyclopsv1alpha1.Module,
+	childrenGVRs []cyclopsv1alpha1.GroupVersionResource,
+	rendered *renderedResources,
+) ([]cyclopsv1alpha1.PrunedResource, []string) {
+	if !k8sclient.PruneEnabled(module) {
+		return nil, nil
+	}
+
+	managedGVRs := r.mergeChildrenGVRs(module.Status.ManagedGVRs, childrenGVRs)
+
+	pruned := make([]cyclopsv1alpha1.PrunedResource, 0)
+	pruneErrors := make([]string, 0)
+
+	// the same object can be listed through multiple versions of its resource
+	seen := make(map[resourceKey]struct{})
+
+	for _, gvr := range managedGVRs {
+		existing, err := r.kubernetesClient.ListManagedResources(module.Name, []cyclopsv1alpha1.GroupVersionResource{gvr})
+		if err != nil {
+			r.logger.Error(err, "failed to list module resources for pruning", "module", module.Name, "gvr", gvr)
+
+			pruneErrors = append(pruneErrors, fmt.Sprintf(
+				"%v/%v %v failed to list for pruning: %v",
+				gvr.Group,
+				gvr.Version,
+				gvr.Resource,
+				err.Error(),
+			))
+			continue
+		}
+
+		for _, obj := range existing {
+			if !rendered.prunable(gvr.Resource, obj) {
+				continue
+			}
+
+			key := resourceKey{
+				group:     obj.GroupVersionKind().Group,
+				resource:  gvr.Resource,
+				namespace: obj.GetNamespace(),
+				name:      obj.GetName(),
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			r.logger.Info("pruning resource",
+				"module", module.Name,
+				"gvk", obj.GroupVersionKind().String(),
+				"resource namespaced name", fmt.Sprintf("%s/%s", obj.GetNamespace(), obj.GetName()),
+			)
+
+			if err := r.kubernetesClient.Delete(&dto.Resource{
+				Group:     obj.GroupVersionKind().Group,
+				Version:   obj.GroupVersionKind().Version,
+				Kind:      obj.GroupVersionKind().Kind,
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+			}); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+
+				pruneErrors = append(pruneErrors, fmt.Sprintf(
+					"%v/%v %v %v/%v failed to prune: %v",
+					obj.GroupVersionKind().Group,
+					obj.GroupVersionKind().Version,
+					obj.GroupVersionKind().Kind,
+					obj.GetNamespace(),
+					obj.GetName(),
+					err.Error(),
+				))
+				continue
+			}
+
+			pruned = append(pruned, cyclopsv1alpha1.PrunedResource{
+				Group:     obj.GroupVersionKind().Group,
+				Version:   obj.GroupVersionKind().Version,
+				Kind:      obj.GroupVersionKind().Kind,
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+				PrunedAt:  metav1.Now(),
+			})
+		}
+	}
+
+	return pruned, pruneErrors
+}
+
+func mergePrunedResources(existing, current []cyclopsv1alpha1.PrunedResource) []cyclopsv1alpha1.PrunedResource {
+	merged := make([]cyclopsv1alpha1.PrunedResource, 0, len(current)+len(existing))
+	merged = append(merged, current...)
+	merged = append(merged, existing...)
+
+	if len(merged) > prunedResourcesLimit {
+		merged = merged[:prunedResourcesLimit]
+	}
+
+	return merged
+}
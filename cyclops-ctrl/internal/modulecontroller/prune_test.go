@@ -0,0 +1,240 @@
+package modulecontroller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/mock"
+	"helm.sh/helm/v3/pkg/chart"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/helm"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/prometheus"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/telemetry"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/mocks"
+	k8smocks "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/render"
+)
+
+func resource(apiVersion, kind, namespace, name string) unstructured.Unstructured {
+	obj := unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+var _ = Describe("Rendered resources", func() {
+	type existing struct {
+		resource string
+		obj      unstructured.Unstructured
+	}
+
+	kept := resource("v1", "ConfigMap", "default", "config")
+	kept.SetAnnotations(map[string]string{cyclopsv1alpha1.ResourcePolicyAnnotation: cyclopsv1alpha1.ResourcePolicyKeep})
+
+	helmKept := resource("v1", "ConfigMap", "default", "config")
+	helmKept.SetAnnotations(map[string]string{"helm.sh/resource-policy": "keep"})
+
+	controlled := resource("v1", "Pod", "default", "web-7d9f")
+	isController := true
+	controlled.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       "web-7d9f",
+		Controller: &isController,
+	}})
+
+	DescribeTable("prunes resources that are no longer rendered",
+		func(rendered []existing, listed existing, contains, prunable bool) {
+			rr := newRenderedResources("")
+			for _, r := range rendered {
+				obj := r.obj
+				rr.add(r.resource, &obj)
+			}
+
+			Expect(rr.contains(listed.resource, listed.obj)).To(Equal(contains))
+			Expect(rr.prunable(listed.resource, listed.obj)).To(Equal(prunable))
+		},
+		Entry("namespaced resource rendered",
+			[]existing{{"deployments", resource("apps/v1", "Deployment", "default", "web")}},
+			existing{"deployments", resource("apps/v1", "Deployment", "default", "web")},
+			true, false,
+		),
+		Entry("namespaced resource in another namespace",
+			[]existing{{"deployments", resource("apps/v1", "Deployment", "default", "web")}},
+			existing{"deployments", resource("apps/v1", "Deployment", "staging", "web")},
+			false, true,
+		),
+		Entry("namespaced resource no longer rendered",
+			[]existing{{"deployments", resource("apps/v1", "Deployment", "default", "web")}},
+			existing{"services", resource("v1", "Service", "default", "web")},
+			false, true,
+		),
+		Entry("cluster scoped resource rendered with the target namespace",
+			[]existing{{"clusterroles", resource("rbac.authorization.k8s.io/v1", "ClusterRole", "default", "reader")}},
+			existing{"clusterroles", resource("rbac.authorization.k8s.io/v1", "ClusterRole", "", "reader")},
+			true, false,
+		),
+		Entry("cluster scoped resource no longer rendered",
+			[]existing{{"clusterroles", resource("rbac.authorization.k8s.io/v1", "ClusterRole", "default", "reader")}},
+			existing{"clusterroles", resource("rbac.authorization.k8s.io/v1", "ClusterRole", "", "writer")},
+			false, true,
+		),
+		Entry("resource rendered without a namespace",
+			[]existing{{"clusterroles", resource("rbac.authorization.k8s.io/v1", "ClusterRole", "", "reader")}},
+			existing{"clusterroles", resource("rbac.authorization.k8s.io/v1", "ClusterRole", "", "reader")},
+			true, false,
+		),
+		Entry("resource listed through another version",
+			[]existing{{"horizontalpodautoscalers", resource("autoscaling/v2", "HorizontalPodAutoscaler", "default", "web")}},
+			existing{"horizontalpodautoscalers", resource("autoscaling/v1", "HorizontalPodAutoscaler", "default", "web")},
+			true, false,
+		),
+		Entry("resource with the keep annotation",
+			nil,
+			existing{"configmaps", kept},
+			false, false,
+		),
+		Entry("resource with the helm keep annotation",
+			nil,
+			existing{"configmaps", helmKept},
+			false, false,
+		),
+		Entry("resource owned by a controller",
+			nil,
+			existing{"pods", controlled},
+			false, false,
+		),
+	)
+})
+
+var _ = Describe("Module reconciler pruning", func() {
+	const configMapManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+data:
+  replicas: "{{ .Values.replicas }}"
+`
+
+	var k8sClient *k8smocks.IKubernetesClient
+	var templatesRepo *mocks.ITemplateRepo
+	var reconciler *ModuleReconciler
+
+	module := &cyclopsv1alpha1.Module{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "web",
+			Namespace:  "cyclops",
+			Generation: 2,
+			Finalizers: []string{cyclopsv1alpha1.ResourceFinalizer},
+		},
+		Spec: cyclopsv1alpha1.ModuleSpec{
+			TemplateRef: cyclopsv1alpha1.TemplateRef{
+				URL:        "https://github.com/my-org/templates",
+				Path:       "web",
+				Version:    "main",
+				SourceType: cyclopsv1alpha1.TemplateSourceTypeGit,
+			},
+			Values: apiextensionsv1.JSON{Raw: []byte(`{"replicas":3}`)},
+		},
+		Status: cyclopsv1alpha1.ModuleStatus{
+			TemplateResolvedVersion: "3f2a1c",
+			ManagedGVRs: []cyclopsv1alpha1.GroupVersionResource{
+				{Group: "apps", Version: "v1", Resource: "deployments"},
+			},
+		},
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(cyclopsv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(module.DeepCopy()).
+			WithStatusSubresource(&cyclopsv1alpha1.Module{}).
+			Build()
+
+		k8sClient = &k8smocks.IKubernetesClient{}
+		k8sClient.On("VersionInfo").Return(&version.Info{Major: "1", Minor: "30"}, nil)
+		k8sClient.On("ListModuleRevisions", "web").Return([]cyclopsv1alpha1.ModuleRevision{}, nil).Maybe()
+		k8sClient.On("CreateModuleRevision", mock.Anything).Return(nil).Maybe()
+		k8sClient.On("GVKtoAPIResourceName", mock.Anything, "ConfigMap").Return("configmaps", nil)
+
+		templatesRepo = &mocks.ITemplateRepo{}
+		templatesRepo.On("GetTemplate", "https://github.com/my-org/templates", "web", "3f2a1c", "3f2a1c", cyclopsv1alpha1.TemplateSourceTypeGit).
+			Return(&models.Template{
+				ResolvedVersion:   "3f2a1c",
+				HelmChartMetadata: &helm.Metadata{Name: "web"},
+				Templates:         []*chart.File{{Name: "templates/configmap.yaml", Data: []byte(configMapManifest)}},
+			}, nil)
+
+		reconciler = NewModuleReconciler(
+			c,
+			scheme,
+			templatesRepo,
+			k8sClient,
+			render.NewRenderer(k8sClient),
+			1,
+			0,
+			telemetry.MockClient{},
+			prometheus.Monitor{
+				ReconciliationDuration:      prom.NewHistogram(prom.HistogramOpts{Name: "reconciliation_duration"}),
+				ReconciliationCounter:       prom.NewCounter(prom.CounterOpts{Name: "reconciliations"}),
+				FailedReconciliationCounter: prom.NewCounter(prom.CounterOpts{Name: "failed_reconciliations"}),
+			},
+		)
+		reconciler.logger = logr.Discard()
+	})
+
+	It("does not prune resources if applying the module failed", func() {
+		k8sClient.On("CreateDynamic", mock.Anything, mock.Anything, mock.Anything, false).Return(errors.New("admission webhook denied the request"))
+
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: "cyclops", Name: "web"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		k8sClient.AssertNotCalled(GinkgoT(), "ListManagedResources", mock.Anything, mock.Anything)
+		k8sClient.AssertNotCalled(GinkgoT(), "Delete", mock.Anything)
+		k8sClient.AssertNotCalled(GinkgoT(), "CreateModuleRevision", mock.Anything)
+
+		var updated cyclopsv1alpha1.Module
+		Expect(reconciler.Get(context.Background(), types.NamespacedName{Namespace: "cyclops", Name: "web"}, &updated)).To(Succeed())
+		Expect(updated.Status.ReconciliationStatus.Status).To(Equal(cyclopsv1alpha1.Failed))
+		Expect(updated.Status.ReconciliationStatus.Errors).To(ConsistOf(
+			"v1/ConfigMap /web failed to apply: admission webhook denied the request",
+		))
+	})
+
+	It("prunes resources that are no longer rendered", func() {
+		stale := resource("apps/v1", "Deployment", "default", "web")
+		k8sClient.On("CreateDynamic", mock.Anything, mock.Anything, mock.Anything, false).Return(nil)
+		k8sClient.On("ListManagedResources", "web", mock.Anything).Return([]unstructured.Unstructured{stale}, nil)
+		k8sClient.On("Delete", mock.Anything).Return(nil)
+		k8sClient.On("GetModuleResourcesHealth", "web").Return("healthy", nil)
+
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: "cyclops", Name: "web"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		k8sClient.AssertCalled(GinkgoT(), "Delete", mock.Anything)
+		k8sClient.AssertCalled(GinkgoT(), "CreateModuleRevision", mock.Anything)
+	})
+})
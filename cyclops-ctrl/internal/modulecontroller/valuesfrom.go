@@ -0,0 +1,43 @@
+package modulecontroller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// modulesForValuesSource maps changes of a Secret or ConfigMap to reconcile
+// requests for all Modules that take values from it
+func (r *ModuleReconciler) modulesForValuesSource(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var modules cyclopsv1alpha1.ModuleList
+		if err := r.List(ctx, &modules, client.InNamespace(obj.GetNamespace())); err != nil {
+			r.logger.Error(err, "failed to list modules for values source", "kind", kind, "name", obj.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0)
+		for _, module := range modules.Items {
+			for _, ref := range module.Spec.ValuesFrom {
+				if ref.Kind != kind || ref.Name != obj.GetName() {
+					continue
+				}
+
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Namespace: module.Namespace,
+						Name:      module.Name,
+					},
+				})
+				break
+			}
+		}
+
+		return requests
+	}
+}
@@ -0,0 +1,411 @@
+package modulewebhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	json "github.com/json-iterator/go"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/dependencies"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/immutable"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/render"
+)
+
+var valuesPath = field.NewPath("spec", "values")
+
+// templateFetchTimeout is the time the validating webhook waits for the Module
+// template. It is shorter than the webhook timeout, so Modules whose template
+// can't be fetched in time are admitted with a warning instead of denied.
+const templateFetchTimeout = 5 * time.Second
+
+// sourceTypeTimeout is the time the defaulting webhook waits for the template
+// source type to be resolved from the template repository
+const sourceTypeTimeout = 2 * time.Second
+
+// ModuleWebhook defaults and validates Modules created and updated through the
+// Kubernetes API, so Modules applied with kubectl or by GitOps tools go through
+// the same checks as Modules created from the UI
+type ModuleWebhook struct {
+	templatesRepo         template.ITemplateRepo
+	kubernetesClient      k8sclient.IKubernetesClient
+	renderer              *render.Renderer
+	moduleTargetNamespace string
+	templateFetchTimeout  time.Duration
+	sourceTypeTimeout     time.Duration
+}
+
+func NewModuleWebhook(
+	templatesRepo template.ITemplateRepo,
+	kubernetesClient k8sclient.IKubernetesClient,
+	renderer *render.Renderer,
+	moduleTargetNamespace string,
+) *ModuleWebhook {
+	return &ModuleWebhook{
+		templatesRepo:         templatesRepo,
+		kubernetesClient:      kubernetesClient,
+		renderer:              renderer,
+		moduleTargetNamespace: moduleTargetNamespace,
+		templateFetchTimeout:  templateFetchTimeout,
+		sourceTypeTimeout:     sourceTypeTimeout,
+	}
+}
+
+func (w *ModuleWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&cyclopsv1alpha1.Module{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-cyclops-ui-com-v1alpha1-module,mutating=true,failurePolicy=fail,sideEffects=None,groups=cyclops-ui.com,resources=modules,verbs=create;update,versions=v1alpha1,name=mmodule.cyclops-ui.com,admissionReviewVersions=v1,timeoutSeconds=10
+
+var _ admission.CustomDefaulter = &ModuleWebhook{}
+
+// Default sets the template source type and the target namespace the Module
+// would get if it was created from the UI. Modules whose source type can't be
+// resolved in time are admitted without it and get a warning from validation.
+func (w *ModuleWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	module, err := toModule(obj)
+	if err != nil {
+		return err
+	}
+
+	if module.DeletionTimestamp != nil {
+		return nil
+	}
+
+	if len(module.Spec.TargetNamespace) == 0 && len(w.moduleTargetNamespace) > 0 {
+		module.Spec.TargetNamespace = w.moduleTargetNamespace
+	}
+
+	if len(module.Spec.TemplateRef.SourceType) == 0 && len(module.Spec.TemplateRef.URL) > 0 {
+		sourceType, err := w.assumeSourceType(ctx, module.Spec.TemplateRef.URL)
+		if err == nil {
+			module.Spec.TemplateRef.SourceType = sourceType
+		}
+	}
+
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-cyclops-ui-com-v1alpha1-module,mutating=false,failurePolicy=fail,sideEffects=None,groups=cyclops-ui.com,resources=modules,verbs=create;update,versions=v1alpha1,name=vmodule.cyclops-ui.com,admissionReviewVersions=v1,timeoutSeconds=10
+
+var _ admission.CustomValidator = &ModuleWebhook{}
+
+func (w *ModuleWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	module, err := toModule(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.validate(ctx, nil, module)
+}
+
+func (w *ModuleWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldModule, err := toModule(oldObj)
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := toModule(newObj)
+	if err != nil {
+		return nil, err
+	}
+
+	// Modules being deleted only get their finalizers removed, and changes to
+	// metadata alone can't make a valid Module invalid
+	if module.DeletionTimestamp != nil || reflect.DeepEqual(oldModule.Spec, module.Spec) {
+		return nil, nil
+	}
+
+	return w.validate(ctx, oldModule, module)
+}
+
+func (w *ModuleWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *ModuleWebhook) validate(ctx context.Context, oldModule, module *cyclopsv1alpha1.Module) (admission.Warnings, error) {
+	errs := w.validateTargetNamespace(module)
+	errs = append(errs, validateValuesFrom(module)...)
+
+	dependsOnErrs, err := w.validateDependsOn(module)
+	if err != nil {
+		return nil, err
+	}
+	errs = append(errs, dependsOnErrs...)
+
+	valuesErrs, warnings := w.validateValues(ctx, oldModule, module)
+	errs = append(errs, valuesErrs...)
+
+	if len(module.Spec.TemplateRef.SourceType) == 0 && len(module.Spec.TemplateRef.URL) > 0 {
+		warnings = append(warnings, "spec.template.sourceType could not be resolved from the template repository; it is resolved when the template is fetched")
+	}
+
+	if len(errs) != 0 {
+		return warnings, apierrors.NewInvalid(
+			cyclopsv1alpha1.GroupVersion.WithKind("Module").GroupKind(),
+			module.Name,
+			errs,
+		)
+	}
+
+	return warnings, nil
+}
+
+func (w *ModuleWebhook) validateTargetNamespace(module *cyclopsv1alpha1.Module) field.ErrorList {
+	path := field.NewPath("spec", "targetNamespace")
+	namespace := module.Spec.TargetNamespace
+
+	if len(w.moduleTargetNamespace) > 0 && namespace != w.moduleTargetNamespace {
+		return field.ErrorList{
+			field.NotSupported(path, namespace, []string{w.moduleTargetNamespace}),
+		}
+	}
+
+	if len(namespace) == 0 {
+		return nil
+	}
+
+	errs := field.ErrorList{}
+	for _, msg := range validation.IsDNS1123Label(namespace) {
+		errs = append(errs, field.Invalid(path, namespace, msg))
+	}
+
+	return errs
+}
+
+func validateValuesFrom(module *cyclopsv1alpha1.Module) field.ErrorList {
+	errs := field.ErrorList{}
+
+	for i, source := range module.Spec.ValuesFrom {
+		path := field.NewPath("spec", "valuesFrom").Index(i).Child("name")
+		for _, msg := range validation.IsDNS1123Subdomain(source.Name) {
+			errs = append(errs, field.Invalid(path, source.Name, msg))
+		}
+	}
+
+	return errs
+}
+
+// validateDependsOn checks the referenced Module names and that the Module
+// does not end up depending on itself. Referenced Modules don't have to exist
+// yet, so Modules can be applied in any order.
+func (w *ModuleWebhook) validateDependsOn(module *cyclopsv1alpha1.Module) (field.ErrorList, error) {
+	if len(module.Spec.DependsOn) == 0 {
+		return nil, nil
+	}
+
+	errs := field.ErrorList{}
+	seen := make(map[string]bool, len(module.Spec.DependsOn))
+
+	for i, dependency := range module.Spec.DependsOn {
+		path := field.NewPath("spec", "dependsOn").Index(i).Child("name")
+
+		switch {
+		case dependency.Name == module.Name:
+			errs = append(errs, field.Invalid(path, dependency.Name, "a Module can not depend on itself"))
+		case seen[dependency.Name]:
+			errs = append(errs, field.Duplicate(path, dependency.Name))
+		}
+		seen[dependency.Name] = true
+
+		for _, msg := range validation.IsDNS1123Subdomain(dependency.Name) {
+			errs = append(errs, field.Invalid(path, dependency.Name, msg))
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs, nil
+	}
+
+	modules, err := w.kubernetesClient.ListModules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modules: %w", err)
+	}
+
+	graph := dependencies.NewGraph(modules)
+	graph[module.Name] = dependencies.NewGraph([]cyclopsv1alpha1.Module{*module})[module.Name]
+
+	if cycle := graph.Cycle(module.Name); cycle != nil {
+		errs = append(errs, field.Invalid(
+			field.NewPath("spec", "dependsOn"),
+			field.OmitValueType{},
+			fmt.Sprintf("dependency cycle %v", strings.Join(cycle, " -> ")),
+		))
+	}
+
+	return errs, nil
+}
+
+// validateValues validates the Module values against the schema of the
+// template they will be rendered with, and checks that values the template
+// marks as immutable did not change. Modules are admitted with a warning if the
+// template can't be fetched, since the reconciler validates them again.
+func (w *ModuleWebhook) validateValues(ctx context.Context, oldModule, module *cyclopsv1alpha1.Module) (field.ErrorList, admission.Warnings) {
+	templateRef := module.Spec.TemplateRef
+
+	// a Module keeps the resolved version until its template reference changes
+	resolvedVersion := ""
+	if oldModule != nil && reflect.DeepEqual(oldModule.Spec.TemplateRef, templateRef) {
+		resolvedVersion = oldModule.Status.TemplateResolvedVersion
+	}
+
+	version := resolvedVersion
+	if len(version) == 0 {
+		version = templateRef.Version
+	}
+
+	values, err := moduleValues(module)
+	if err != nil {
+		return field.ErrorList{field.Invalid(valuesPath, string(module.Spec.Values.Raw), err.Error())}, nil
+	}
+
+	moduleTemplate, err := w.fetchTemplate(ctx, templateRef, version, resolvedVersion)
+	if err != nil {
+		return nil, admission.Warnings{fmt.Sprintf("values were not validated against the template schema: failed to fetch module template: %v", err)}
+	}
+
+	errs := field.ErrorList{}
+	var warnings admission.Warnings
+
+	if err := w.renderer.ValidateValues(*module, moduleTemplate); err != nil {
+		var validationErr *render.ValidationError
+		if !errors.As(err, &validationErr) {
+			// values sources might be created after the Module, the schema is
+			// validated again when the Module is reconciled
+			warnings = append(warnings, fmt.Sprintf("values were not validated against the template schema: %v", err))
+		} else {
+			for _, fieldErr := range validationErr.Fields {
+				errs = append(errs, field.Invalid(valuesFieldPath(fieldErr.Field), field.OmitValueType{}, fieldErr.Description))
+			}
+		}
+	}
+
+	if oldModule == nil {
+		return errs, warnings
+	}
+
+	oldValues, err := moduleValues(oldModule)
+	if err != nil {
+		return errs, warnings
+	}
+
+	for _, path := range immutable.Violations(moduleTemplate.RootField, oldValues, values) {
+		errs = append(errs, field.Forbidden(valuesFieldPath(path), "field is immutable"))
+	}
+
+	return errs, warnings
+}
+
+// fetchTemplate fetches the Module template, waiting at most for the template
+// fetch timeout. Fetches that time out keep running and warm the template cache
+// for the reconciler.
+func (w *ModuleWebhook) fetchTemplate(
+	ctx context.Context,
+	templateRef cyclopsv1alpha1.TemplateRef,
+	version, resolvedVersion string,
+) (*models.Template, error) {
+	type result struct {
+		template *models.Template
+		err      error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		moduleTemplate, err := w.templatesRepo.GetTemplate(
+			templateRef.URL,
+			templateRef.Path,
+			version,
+			resolvedVersion,
+			templateRef.SourceType,
+		)
+		done <- result{template: moduleTemplate, err: err}
+	}()
+
+	timeout := time.NewTimer(w.templateFetchTimeout)
+	defer timeout.Stop()
+
+	select {
+	case r := <-done:
+		return r.template, r.err
+	case <-timeout.C:
+		return nil, fmt.Errorf("timed out after %v", w.templateFetchTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// assumeSourceType resolves the template source type from the repository,
+// waiting at most for the source type timeout
+func (w *ModuleWebhook) assumeSourceType(ctx context.Context, repo string) (cyclopsv1alpha1.TemplateSourceType, error) {
+	type result struct {
+		sourceType cyclopsv1alpha1.TemplateSourceType
+		err        error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		sourceType, err := w.templatesRepo.AssumeTemplateSourceType(repo)
+		done <- result{sourceType: sourceType, err: err}
+	}()
+
+	timeout := time.NewTimer(w.sourceTypeTimeout)
+	defer timeout.Stop()
+
+	select {
+	case r := <-done:
+		return r.sourceType, r.err
+	case <-timeout.C:
+		return "", fmt.Errorf("timed out after %v", w.sourceTypeTimeout)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// valuesFieldPath returns the path of a dot separated values field in the
+// Module, where (root) is the values object itself
+func valuesFieldPath(path string) *field.Path {
+	if path == "(root)" {
+		return valuesPath
+	}
+
+	return valuesPath.Child(path)
+}
+
+func moduleValues(module *cyclopsv1alpha1.Module) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if len(module.Spec.Values.Raw) == 0 {
+		return values, nil
+	}
+
+	if err := json.Unmarshal(module.Spec.Values.Raw, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func toModule(obj runtime.Object) (*cyclopsv1alpha1.Module, error) {
+	module, ok := obj.(*cyclopsv1alpha1.Module)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a Module but got a %T", obj))
+	}
+
+	return module, nil
+}
@@ -0,0 +1,246 @@
+package modulewebhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/mocks"
+	k8smocks "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/render"
+)
+
+func TestModuleWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test module webhook")
+}
+
+var _ = Describe("Module webhook test", func() {
+	const repo = "https://github.com/cyclops-ui/templates"
+
+	var templatesRepo *mocks.ITemplateRepo
+	var k8sClient *k8smocks.IKubernetesClient
+	var webhook *ModuleWebhook
+
+	moduleTemplate := &models.Template{
+		RootField: models.Field{
+			Properties: []models.Field{
+				{Name: "replicas"},
+				{Name: "storageClass", Immutable: true},
+			},
+		},
+		RawSchema: []byte(`{
+			"type": "object",
+			"properties": {
+				"replicas": {"type": "integer"},
+				"storageClass": {"type": "string"}
+			}
+		}`),
+	}
+
+	module := func(name, values string, dependsOn ...string) *cyclopsv1alpha1.Module {
+		m := &cyclopsv1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: cyclopsv1alpha1.ModuleSpec{
+				TemplateRef: cyclopsv1alpha1.TemplateRef{
+					URL:        repo,
+					Path:       "demo",
+					Version:    "main",
+					SourceType: cyclopsv1alpha1.TemplateSourceTypeGit,
+				},
+				Values: apiextensionsv1.JSON{Raw: []byte(values)},
+			},
+		}
+
+		for _, dependency := range dependsOn {
+			m.Spec.DependsOn = append(m.Spec.DependsOn, cyclopsv1alpha1.ModuleReference{Name: dependency})
+		}
+
+		return m
+	}
+
+	invalidFields := func(err error) []string {
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+		fields := make([]string, 0)
+		for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+			fields = append(fields, cause.Field)
+		}
+		return fields
+	}
+
+	BeforeEach(func() {
+		templatesRepo = &mocks.ITemplateRepo{}
+		k8sClient = &k8smocks.IKubernetesClient{}
+		webhook = NewModuleWebhook(templatesRepo, k8sClient, render.NewRenderer(k8sClient), "")
+
+		templatesRepo.On("GetTemplate", repo, "demo", mock.Anything, mock.Anything, mock.Anything).Return(moduleTemplate, nil)
+		k8sClient.On("ListModules").Return([]cyclopsv1alpha1.Module{
+			*module("db", `{}`),
+			*module("cache", `{}`, "api"),
+		}, nil)
+	})
+
+	Describe("Default", func() {
+		It("sets the target namespace and the template source type", func() {
+			webhook.moduleTargetNamespace = "apps"
+			templatesRepo.On("AssumeTemplateSourceType", repo).Return(cyclopsv1alpha1.TemplateSourceTypeGit, nil)
+
+			m := module("api", `{}`)
+			m.Spec.TemplateRef.SourceType = ""
+
+			Expect(webhook.Default(context.Background(), m)).To(Succeed())
+			Expect(m.Spec.TargetNamespace).To(Equal("apps"))
+			Expect(m.Spec.TemplateRef.SourceType).To(Equal(cyclopsv1alpha1.TemplateSourceTypeGit))
+		})
+
+		It("keeps the template source type if it is set", func() {
+			m := module("api", `{}`)
+			m.Spec.TemplateRef.SourceType = cyclopsv1alpha1.TemplateSourceTypeHelm
+
+			Expect(webhook.Default(context.Background(), m)).To(Succeed())
+			Expect(m.Spec.TemplateRef.SourceType).To(Equal(cyclopsv1alpha1.TemplateSourceTypeHelm))
+			templatesRepo.AssertNotCalled(GinkgoT(), "AssumeTemplateSourceType", mock.Anything)
+		})
+
+		It("admits the Module with a warning if the source type can't be resolved", func() {
+			templatesRepo.On("AssumeTemplateSourceType", repo).Return(cyclopsv1alpha1.TemplateSourceType(""), errors.New("repository not found"))
+
+			m := module("api", `{"replicas": 2}`)
+			m.Spec.TemplateRef.SourceType = ""
+
+			Expect(webhook.Default(context.Background(), m)).To(Succeed())
+			Expect(m.Spec.TemplateRef.SourceType).To(BeEmpty())
+
+			warnings, err := webhook.ValidateCreate(context.Background(), m)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("spec.template.sourceType could not be resolved")))
+		})
+
+		It("leaves the source type empty if resolving it times out", func() {
+			release := make(chan struct{})
+			defer close(release)
+
+			templatesRepo.On("AssumeTemplateSourceType", repo).
+				Run(func(mock.Arguments) { <-release }).
+				Return(cyclopsv1alpha1.TemplateSourceTypeGit, nil)
+			webhook.sourceTypeTimeout = 10 * time.Millisecond
+
+			m := module("api", `{}`)
+			m.Spec.TemplateRef.SourceType = ""
+
+			Expect(webhook.Default(context.Background(), m)).To(Succeed())
+			Expect(m.Spec.TemplateRef.SourceType).To(BeEmpty())
+		})
+	})
+
+	Describe("ValidateCreate", func() {
+		It("admits valid Modules", func() {
+			warnings, err := webhook.ValidateCreate(context.Background(), module("api", `{"replicas": 2}`, "db"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("rejects values that do not match the template schema", func() {
+			_, err := webhook.ValidateCreate(context.Background(), module("api", `{"replicas": "two"}`))
+			Expect(invalidFields(err)).To(Equal([]string{"spec.values.replicas"}))
+		})
+
+		It("rejects target namespaces other than the module target namespace", func() {
+			webhook.moduleTargetNamespace = "apps"
+
+			m := module("api", `{}`)
+			m.Spec.TargetNamespace = "kube-system"
+
+			_, err := webhook.ValidateCreate(context.Background(), m)
+			Expect(invalidFields(err)).To(Equal([]string{"spec.targetNamespace"}))
+		})
+
+		It("rejects invalid dependencies", func() {
+			_, err := webhook.ValidateCreate(context.Background(), module("api", `{}`, "api", "db", "db"))
+			Expect(invalidFields(err)).To(Equal([]string{"spec.dependsOn[0].name", "spec.dependsOn[2].name"}))
+		})
+
+		It("rejects dependency cycles", func() {
+			_, err := webhook.ValidateCreate(context.Background(), module("api", `{}`, "cache"))
+			Expect(invalidFields(err)).To(Equal([]string{"spec.dependsOn"}))
+			Expect(err.Error()).To(ContainSubstring("api -> cache -> api"))
+		})
+
+		It("admits the Module with a warning if the template can't be fetched", func() {
+			templatesRepo.ExpectedCalls = nil
+			templatesRepo.On("GetTemplate", repo, "demo", "main", "", mock.Anything).Return(nil, errors.New("not found"))
+
+			warnings, err := webhook.ValidateCreate(context.Background(), module("api", `{"replicas": "two"}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("not found")))
+		})
+
+		It("admits the Module with a warning if the template fetch times out", func() {
+			release := make(chan struct{})
+			defer close(release)
+
+			templatesRepo.ExpectedCalls = nil
+			templatesRepo.On("GetTemplate", repo, "demo", "main", "", mock.Anything).
+				Run(func(mock.Arguments) { <-release }).
+				Return(moduleTemplate, nil)
+			webhook.templateFetchTimeout = 10 * time.Millisecond
+
+			warnings, err := webhook.ValidateCreate(context.Background(), module("api", `{"replicas": "two"}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("timed out")))
+		})
+
+		It("rejects invalid dependencies if the template can't be fetched", func() {
+			templatesRepo.ExpectedCalls = nil
+			templatesRepo.On("GetTemplate", repo, "demo", "main", "", mock.Anything).Return(nil, errors.New("not found"))
+
+			_, err := webhook.ValidateCreate(context.Background(), module("api", `{}`, "api"))
+			Expect(invalidFields(err)).To(Equal([]string{"spec.dependsOn[0].name"}))
+		})
+	})
+
+	Describe("ValidateUpdate", func() {
+		It("rejects changes to immutable values", func() {
+			_, err := webhook.ValidateUpdate(
+				context.Background(),
+				module("api", `{"replicas": 1, "storageClass": "fast"}`),
+				module("api", `{"replicas": 3, "storageClass": "slow"}`),
+			)
+			Expect(invalidFields(err)).To(Equal([]string{"spec.values.storageClass"}))
+		})
+
+		It("validates against the resolved template version", func() {
+			old := module("api", `{"replicas": 1}`)
+			old.Status.TemplateResolvedVersion = "3f2a1c"
+
+			updated := old.DeepCopy()
+			updated.Spec.Values.Raw = []byte(`{"replicas": 2}`)
+
+			_, err := webhook.ValidateUpdate(context.Background(), old, updated)
+			Expect(err).ToNot(HaveOccurred())
+			templatesRepo.AssertCalled(GinkgoT(), "GetTemplate", repo, "demo", "3f2a1c", "3f2a1c", cyclopsv1alpha1.TemplateSourceTypeGit)
+		})
+
+		It("does not validate metadata changes", func() {
+			old := module("api", `{"replicas": "two"}`)
+
+			updated := old.DeepCopy()
+			updated.Finalizers = []string{cyclopsv1alpha1.ResourceFinalizer}
+
+			_, err := webhook.ValidateUpdate(context.Background(), old, updated)
+			Expect(err).ToNot(HaveOccurred())
+			templatesRepo.AssertNotCalled(GinkgoT(), "GetTemplate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+})
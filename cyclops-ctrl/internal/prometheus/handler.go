@@ -22,6 +22,13 @@ type Monitor struct {
 	ReconciliationDuration      prometheus.Histogram
 	ReconciliationCounter       prometheus.Counter
 	FailedReconciliationCounter prometheus.Counter
+
+	// ModuleDrift is 1 for Modules that differ from their git destination
+	ModuleDrift *prometheus.GaugeVec
+
+	// Template fetch metrics, labeled by template source type
+	TemplateFetchDuration *prometheus.HistogramVec
+	TemplateFetchFailures *prometheus.CounterVec
 }
 
 func NewMonitor(logger logr.Logger) (Monitor, error) {
@@ -78,6 +85,22 @@ func NewMonitor(logger logr.Logger) (Monitor, error) {
 			Help:      "No of failed reconciliations",
 			Namespace: "cyclops",
 		}),
+		ModuleDrift: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      "module_drift",
+			Help:      "Module differs from its git destination",
+			Namespace: "cyclops",
+		}, []string{"module"}),
+		TemplateFetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:      "template_fetch_duration_seconds",
+			Help:      "Duration of template fetches",
+			Namespace: "cyclops",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"source"}),
+		TemplateFetchFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      "template_fetch_failures",
+			Help:      "No of failed template fetches",
+			Namespace: "cyclops",
+		}, []string{"source"}),
 	}
 
 	metricsList :=
@@ -92,6 +115,9 @@ func NewMonitor(logger logr.Logger) (Monitor, error) {
 			m.ReconciliationDuration,
 			m.ReconciliationCounter,
 			m.FailedReconciliationCounter,
+			m.ModuleDrift,
+			m.TemplateFetchDuration,
+			m.TemplateFetchFailures,
 		}
 
 	for _, metric := range metricsList {
@@ -124,6 +150,38 @@ func (m *Monitor) ObserveReconciliationDuration(duration float64) {
 	m.ReconciliationDuration.Observe(duration)
 }
 
+func (m *Monitor) SetModuleDrift(module string, drifted bool) {
+	if m.ModuleDrift == nil {
+		return
+	}
+
+	value := 0.0
+	if drifted {
+		value = 1
+	}
+
+	m.ModuleDrift.WithLabelValues(module).Set(value)
+}
+
+func (m *Monitor) DeleteModuleDrift(module string) {
+	if m.ModuleDrift == nil {
+		return
+	}
+
+	m.ModuleDrift.DeleteLabelValues(module)
+}
+
+func (m *Monitor) ObserveTemplateFetch(source string, duration time.Duration, err error) {
+	if m.TemplateFetchDuration == nil {
+		return
+	}
+
+	m.TemplateFetchDuration.WithLabelValues(source).Observe(duration.Seconds())
+	if err != nil {
+		m.TemplateFetchFailures.WithLabelValues(source).Inc()
+	}
+}
+
 func (m *Monitor) UpdateCacheMetrics(cache *ristretto.Cache) {
 	cacheMetrics := cache.Metrics
 
@@ -0,0 +1,477 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolloutcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/dependencies"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/history"
+)
+
+const (
+	defaultProgressDeadline = 10 * time.Minute
+
+	// rolloutCheckInterval is how often Modules of the current wave are checked
+	rolloutCheckInterval = 10 * time.Second
+)
+
+// TemplateRolloutReconciler reconciles a TemplateRollout object
+type TemplateRolloutReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	revisions      history.RevisionStore
+	gitWriteClient *git.WriteClient
+
+	logger logr.Logger
+}
+
+func NewTemplateRolloutReconciler(
+	client client.Client,
+	scheme *runtime.Scheme,
+	revisions history.RevisionStore,
+	gitWriteClient *git.WriteClient,
+) *TemplateRolloutReconciler {
+	return &TemplateRolloutReconciler{
+		Client:         client,
+		Scheme:         scheme,
+		revisions:      revisions,
+		gitWriteClient: gitWriteClient,
+		logger:         ctrl.Log.WithName("rollout-reconciler"),
+	}
+}
+
+//+kubebuilder:rbac:groups=cyclops-ui.com,resources=templaterollouts,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=cyclops-ui.com,resources=templaterollouts/status,verbs=get;update;patch
+
+// Reconcile upgrades the template version of Modules selected by the rollout one
+// wave at a time. A wave is started only after all Modules of the previous wave
+// are Ready. If any Module of a wave fails, Modules of that wave are rolled back
+// and the rollout stops. Modules written to git are skipped, since changes made
+// in the cluster would drift from git.
+func (r *TemplateRolloutReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var rollout cyclopsv1alpha1.TemplateRollout
+	if err := r.Get(ctx, req.NamespacedName, &rollout); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			r.logger.Error(err, "error on get template rollout", "namespaced name", req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if rollout.Status.ObservedGeneration != rollout.Generation {
+		rollout.Status = cyclopsv1alpha1.TemplateRolloutStatus{
+			Phase:              cyclopsv1alpha1.RolloutPending,
+			ObservedGeneration: rollout.Generation,
+		}
+	}
+
+	var (
+		result ctrl.Result
+		err    error
+	)
+
+	switch rollout.Status.Phase {
+	case cyclopsv1alpha1.RolloutSucceeded, cyclopsv1alpha1.RolloutFailed:
+		return ctrl.Result{}, nil
+	case cyclopsv1alpha1.RolloutProgressing:
+		result, err = r.progress(ctx, &rollout)
+	default:
+		err = r.selectModules(ctx, &rollout)
+		result = ctrl.Result{Requeue: true}
+	}
+
+	if statusErr := r.Status().Update(ctx, &rollout); statusErr != nil {
+		r.logger.Error(statusErr, "error updating template rollout status", "namespaced name", req.NamespacedName)
+		return ctrl.Result{}, statusErr
+	}
+
+	if err != nil {
+		r.logger.Error(err, "error on template rollout", "namespaced name", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	return result, nil
+}
+
+// selectModules plans the rollout by assigning each selected Module, not yet on
+// the target version, to a wave
+func (r *TemplateRolloutReconciler) selectModules(ctx context.Context, rollout *cyclopsv1alpha1.TemplateRollout) error {
+	if rollout.Spec.Selector == nil && rollout.Spec.TemplateRef == nil {
+		rollout.Status.Phase = cyclopsv1alpha1.RolloutFailed
+		rollout.Status.Message = "either selector or template has to be set to select modules"
+		return nil
+	}
+
+	selector := labels.Everything()
+	if rollout.Spec.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(rollout.Spec.Selector)
+		if err != nil {
+			rollout.Status.Phase = cyclopsv1alpha1.RolloutFailed
+			rollout.Status.Message = fmt.Sprintf("invalid selector: %v", err.Error())
+			return nil
+		}
+	}
+
+	var modules cyclopsv1alpha1.ModuleList
+	if err := r.List(
+		ctx,
+		&modules,
+		client.InNamespace(rollout.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return err
+	}
+
+	sort.Slice(modules.Items, func(i, j int) bool {
+		return modules.Items[i].Name < modules.Items[j].Name
+	})
+
+	batchSize := rollout.Spec.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	selected := make([]cyclopsv1alpha1.ModuleRolloutStatus, 0)
+	for _, module := range modules.Items {
+		if module.GetDeletionTimestamp() != nil || module.Spec.TemplateRef.Version == rollout.Spec.Version {
+			continue
+		}
+
+		if rollout.Spec.TemplateRef != nil &&
+			(module.Spec.TemplateRef.URL != rollout.Spec.TemplateRef.URL ||
+				module.Spec.TemplateRef.Path != rollout.Spec.TemplateRef.Path) {
+			continue
+		}
+
+		selected = append(selected, cyclopsv1alpha1.ModuleRolloutStatus{
+			Name:  module.Name,
+			Wave:  len(selected) / batchSize,
+			State: cyclopsv1alpha1.ModuleRolloutPending,
+		})
+	}
+
+	rollout.Status.Modules = selected
+	rollout.Status.CurrentWave = 0
+
+	if len(selected) == 0 {
+		rollout.Status.Phase = cyclopsv1alpha1.RolloutSucceeded
+		rollout.Status.Message = "no modules to upgrade"
+		return nil
+	}
+
+	rollout.Status.Phase = cyclopsv1alpha1.RolloutProgressing
+	rollout.Status.Message = fmt.Sprintf("upgrading %v modules to version %v", len(selected), rollout.Spec.Version)
+
+	return nil
+}
+
+func (r *TemplateRolloutReconciler) progress(ctx context.Context, rollout *cyclopsv1alpha1.TemplateRollout) (ctrl.Result, error) {
+	wave := rollout.Status.CurrentWave
+	now := metav1.Now()
+
+	if rollout.Status.WaveStartedAt == nil {
+		for i := range rollout.Status.Modules {
+			m := &rollout.Status.Modules[i]
+			if m.Wave != wave || m.State != cyclopsv1alpha1.ModuleRolloutPending {
+				continue
+			}
+
+			if err := r.upgradeModule(ctx, rollout.Namespace, rollout.Spec.Version, m); err != nil {
+				m.State = cyclopsv1alpha1.ModuleRolloutFailed
+				m.Message = err.Error()
+			}
+		}
+
+		rollout.Status.WaveStartedAt = &now
+	}
+
+	deadline := defaultProgressDeadline
+	if rollout.Spec.ProgressDeadline != nil {
+		deadline = rollout.Spec.ProgressDeadline.Duration
+	}
+
+	failed, ready := false, true
+	for i := range rollout.Status.Modules {
+		m := &rollout.Status.Modules[i]
+		if m.Wave != wave {
+			continue
+		}
+
+		if m.State == cyclopsv1alpha1.ModuleRolloutUpgrading {
+			if err := r.checkModule(ctx, rollout.Namespace, rollout.Spec.Version, m, now.Sub(rollout.Status.WaveStartedAt.Time) > deadline, deadline); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		failed = failed || m.State == cyclopsv1alpha1.ModuleRolloutFailed
+		ready = ready && (m.State == cyclopsv1alpha1.ModuleRolloutSucceeded || m.State == cyclopsv1alpha1.ModuleRolloutSkipped)
+	}
+
+	if failed {
+		r.rollbackWave(ctx, rollout, wave)
+
+		rollout.Status.Phase = cyclopsv1alpha1.RolloutFailed
+		rollout.Status.Message = fmt.Sprintf("wave %v failed; upgraded modules of the wave were rolled back", wave)
+		return ctrl.Result{}, nil
+	}
+
+	if !ready {
+		return ctrl.Result{RequeueAfter: rolloutCheckInterval}, nil
+	}
+
+	if wave == lastWave(rollout.Status.Modules) {
+		rollout.Status.Phase = cyclopsv1alpha1.RolloutSucceeded
+		rollout.Status.Message = fmt.Sprintf("all modules upgraded to version %v", rollout.Spec.Version)
+		if skipped := countState(rollout.Status.Modules, cyclopsv1alpha1.ModuleRolloutSkipped); skipped != 0 {
+			rollout.Status.Message = fmt.Sprintf("%v; %v modules written to git were skipped", rollout.Status.Message, skipped)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if rollout.Status.WaveCompletedAt == nil {
+		rollout.Status.WaveCompletedAt = &now
+	}
+
+	if remaining := rollout.Status.WaveCompletedAt.Add(rollout.Spec.PauseBetweenWaves.Duration).Sub(now.Time); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	rollout.Status.CurrentWave++
+	rollout.Status.WaveStartedAt = nil
+	rollout.Status.WaveCompletedAt = nil
+
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// upgradeModule sets the target template version of the Module. The previous
+// generation stays in the Module revisions for rollbacks. The resolved version
+// is cleared from the status first, so the Module reconciler can't keep the
+// version resolved before the upgrade.
+func (r *TemplateRolloutReconciler) upgradeModule(
+	ctx context.Context,
+	namespace, version string,
+	m *cyclopsv1alpha1.ModuleRolloutStatus,
+) error {
+	var module cyclopsv1alpha1.Module
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: m.Name}, &module); err != nil {
+		return err
+	}
+
+	if destination := r.gitWriteClient.Destination(module); destination != nil {
+		m.State = cyclopsv1alpha1.ModuleRolloutSkipped
+		m.Message = fmt.Sprintf("module is written to git repo %v", destination.Repo)
+		return nil
+	}
+
+	m.PreviousGeneration = module.Generation
+	m.PreviousVersion = module.Spec.TemplateRef.Version
+
+	if err := r.clearResolvedVersion(ctx, &module); err != nil {
+		return err
+	}
+
+	module.Spec.TemplateRef.Version = version
+
+	if err := r.Update(ctx, &module); err != nil {
+		return err
+	}
+
+	m.UpgradedGeneration = module.Generation
+	m.State = cyclopsv1alpha1.ModuleRolloutUpgrading
+
+	r.logger.Info("upgraded module template version",
+		"module", m.Name,
+		"previous version", m.PreviousVersion,
+		"version", version,
+	)
+
+	return nil
+}
+
+func (r *TemplateRolloutReconciler) checkModule(
+	ctx context.Context,
+	namespace, version string,
+	m *cyclopsv1alpha1.ModuleRolloutStatus,
+	deadlineExceeded bool,
+	deadline time.Duration,
+) error {
+	var module cyclopsv1alpha1.Module
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: m.Name}, &module); err != nil {
+		if apierrors.IsNotFound(err) {
+			m.State = cyclopsv1alpha1.ModuleRolloutFailed
+			m.Message = "module not found"
+			return nil
+		}
+
+		return err
+	}
+
+	if module.Status.ObservedGeneration == module.Generation &&
+		module.Status.ReconciliationStatus != nil &&
+		module.Status.ReconciliationStatus.Status == cyclopsv1alpha1.Failed {
+		m.State = cyclopsv1alpha1.ModuleRolloutFailed
+		m.Message = fmt.Sprintf("module failed to reconcile: %v", module.Status.ReconciliationStatus.Reason)
+		return nil
+	}
+
+	if resolvedTo(module, m, version) && dependencies.Ready(module) {
+		m.State = cyclopsv1alpha1.ModuleRolloutSucceeded
+		m.Message = ""
+		return nil
+	}
+
+	if deadlineExceeded {
+		m.State = cyclopsv1alpha1.ModuleRolloutFailed
+		m.Message = fmt.Sprintf("module not ready within %v", deadline)
+	}
+
+	return nil
+}
+
+// rollbackWave rolls back all Modules of the wave that were upgraded, to the
+// revision of the generation before the upgrade
+func (r *TemplateRolloutReconciler) rollbackWave(
+	ctx context.Context,
+	rollout *cyclopsv1alpha1.TemplateRollout,
+	wave int,
+) {
+	for i := range rollout.Status.Modules {
+		m := &rollout.Status.Modules[i]
+		if m.Wave != wave || m.UpgradedGeneration == 0 || m.State == cyclopsv1alpha1.ModuleRolloutRolledBack {
+			continue
+		}
+
+		if err := r.rollbackModule(ctx, rollout.Namespace, m); err != nil {
+			r.logger.Error(err, "failed to roll back module", "module", m.Name, "rollout", rollout.Name)
+			m.Message = fmt.Sprintf("%v; rollback failed: %v", m.Message, err.Error())
+			continue
+		}
+
+		m.State = cyclopsv1alpha1.ModuleRolloutRolledBack
+	}
+}
+
+func (r *TemplateRolloutReconciler) rollbackModule(
+	ctx context.Context,
+	namespace string,
+	m *cyclopsv1alpha1.ModuleRolloutStatus,
+) error {
+	var module cyclopsv1alpha1.Module
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: m.Name}, &module); err != nil {
+		return err
+	}
+
+	entry, err := history.Find(r.revisions, module, m.PreviousGeneration)
+	if err != nil {
+		return err
+	}
+
+	if entry == nil {
+		return fmt.Errorf("history entry for generation %v not found", m.PreviousGeneration)
+	}
+
+	if err := r.clearResolvedVersion(ctx, &module); err != nil {
+		return err
+	}
+
+	history.Rollback(&module, *entry)
+
+	return r.Update(ctx, &module)
+}
+
+// clearResolvedVersion clears the resolved template version from the Module
+// status before its spec is updated, so the Module reconciler resolves the
+// version set in the spec. The module is refreshed with the updated object.
+func (r *TemplateRolloutReconciler) clearResolvedVersion(ctx context.Context, module *cyclopsv1alpha1.Module) error {
+	key := client.ObjectKeyFromObject(module)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, key, module); err != nil {
+			return err
+		}
+
+		module.Status.TemplateResolvedVersion = ""
+		return r.Status().Update(ctx, module)
+	})
+}
+
+// resolvedTo returns true if the Module template version was resolved from the
+// target version. Git references resolve to a commit, so a version resolved by
+// the reconciliation of the upgraded generation is accepted for git templates.
+func resolvedTo(module cyclopsv1alpha1.Module, m *cyclopsv1alpha1.ModuleRolloutStatus, version string) bool {
+	resolved := module.Status.TemplateResolvedVersion
+	if resolved == version {
+		return true
+	}
+
+	switch module.Spec.TemplateRef.SourceType {
+	case cyclopsv1alpha1.TemplateSourceTypeHelm, cyclopsv1alpha1.TemplateSourceTypeOCI:
+		return false
+	}
+
+	return len(resolved) != 0 &&
+		module.Spec.TemplateRef.Version == version &&
+		module.Status.ObservedGeneration >= m.UpgradedGeneration
+}
+
+func countState(modules []cyclopsv1alpha1.ModuleRolloutStatus, state cyclopsv1alpha1.ModuleRolloutState) int {
+	count := 0
+	for _, m := range modules {
+		if m.State == state {
+			count++
+		}
+	}
+
+	return count
+}
+
+func lastWave(modules []cyclopsv1alpha1.ModuleRolloutStatus) int {
+	last := 0
+	for _, m := range modules {
+		if m.Wave > last {
+			last = m.Wave
+		}
+	}
+
+	return last
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TemplateRolloutReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cyclopsv1alpha1.TemplateRollout{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
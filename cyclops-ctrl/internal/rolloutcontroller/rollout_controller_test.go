@@ -0,0 +1,339 @@
+package rolloutcontroller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/git"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+)
+
+const templateRepo = "https://github.com/my-org/templates"
+
+type memoryRevisions struct {
+	revisions []cyclopsv1alpha1.ModuleRevision
+}
+
+func (s *memoryRevisions) ListModuleRevisions(moduleName string) ([]cyclopsv1alpha1.ModuleRevision, error) {
+	revisions := make([]cyclopsv1alpha1.ModuleRevision, 0)
+	for _, revision := range s.revisions {
+		if revision.Spec.ModuleName == moduleName {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	return revisions, nil
+}
+
+func (s *memoryRevisions) CreateModuleRevision(revision *cyclopsv1alpha1.ModuleRevision) error {
+	s.revisions = append(s.revisions, *revision)
+	return nil
+}
+
+func (s *memoryRevisions) DeleteModuleRevision(string) error {
+	return nil
+}
+
+func rolloutModule(name, path, version string) *cyclopsv1alpha1.Module {
+	return &cyclopsv1alpha1.Module{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "cyclops",
+			Generation: 1,
+			Labels:     map[string]string{"team": "payments"},
+		},
+		Spec: cyclopsv1alpha1.ModuleSpec{
+			TemplateRef: cyclopsv1alpha1.TemplateRef{URL: templateRepo, Path: path, Version: version},
+		},
+		Status: cyclopsv1alpha1.ModuleStatus{
+			TemplateResolvedVersion: version,
+			ObservedGeneration:      1,
+		},
+	}
+}
+
+var _ = Describe("Template rollout reconciler", func() {
+	var c client.Client
+	var reconciler *TemplateRolloutReconciler
+	var revisions *memoryRevisions
+	var writes []string
+
+	rolloutKey := types.NamespacedName{Namespace: "cyclops", Name: "payments"}
+
+	setup := func(spec cyclopsv1alpha1.TemplateRolloutSpec, modules ...*cyclopsv1alpha1.Module) {
+		scheme := runtime.NewScheme()
+		Expect(cyclopsv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		objects := []client.Object{
+			&cyclopsv1alpha1.TemplateRollout{
+				ObjectMeta: metav1.ObjectMeta{Name: rolloutKey.Name, Namespace: rolloutKey.Namespace, Generation: 1},
+				Spec:       spec,
+			},
+		}
+
+		revisions = &memoryRevisions{}
+		for _, module := range modules {
+			objects = append(objects, module)
+			revisions.revisions = append(revisions.revisions, cyclopsv1alpha1.ModuleRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: module.Name + "-1", Namespace: module.Namespace},
+				Spec: cyclopsv1alpha1.ModuleRevisionSpec{
+					ModuleName: module.Name,
+					HistoryEntry: cyclopsv1alpha1.HistoryEntry{
+						Generation: 1,
+						TemplateRef: cyclopsv1alpha1.HistoryTemplateRef{
+							URL:     module.Spec.TemplateRef.URL,
+							Path:    module.Spec.TemplateRef.Path,
+							Version: module.Spec.TemplateRef.Version,
+						},
+					},
+				},
+			})
+		}
+
+		writes = nil
+
+		// the fake client does not manage generations
+		c = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objects...).
+			WithStatusSubresource(&cyclopsv1alpha1.TemplateRollout{}, &cyclopsv1alpha1.Module{}).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					obj.SetGeneration(obj.GetGeneration() + 1)
+					writes = append(writes, "spec "+obj.GetName())
+					return c.Update(ctx, obj, opts...)
+				},
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					if _, ok := obj.(*cyclopsv1alpha1.Module); ok {
+						writes = append(writes, "status "+obj.GetName())
+					}
+					return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+				},
+			}).
+			Build()
+
+		reconciler = NewTemplateRolloutReconciler(
+			c,
+			scheme,
+			revisions,
+			git.NewWriteClient(auth.TemplatesResolver{}, "", nil, git.PullRequestConfig{}, logr.Discard()),
+		)
+		reconciler.logger = logr.Discard()
+	}
+
+	reconcile := func() cyclopsv1alpha1.TemplateRollout {
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: rolloutKey})
+		Expect(err).NotTo(HaveOccurred())
+
+		var rollout cyclopsv1alpha1.TemplateRollout
+		Expect(c.Get(context.Background(), rolloutKey, &rollout)).To(Succeed())
+		return rollout
+	}
+
+	getModule := func(name string) cyclopsv1alpha1.Module {
+		var module cyclopsv1alpha1.Module
+		Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "cyclops", Name: name}, &module)).To(Succeed())
+		return module
+	}
+
+	setModuleStatus := func(name string, status cyclopsv1alpha1.ReconciliationStatusState, ready metav1.ConditionStatus, resolvedVersion string) {
+		module := getModule(name)
+		module.Status.ObservedGeneration = module.Generation
+		module.Status.TemplateResolvedVersion = resolvedVersion
+		module.Status.ReconciliationStatus = &cyclopsv1alpha1.ReconciliationStatus{Status: status, Reason: "error applying resources"}
+		meta.SetStatusCondition(&module.Status.Conditions, metav1.Condition{
+			Type:               cyclopsv1alpha1.ModuleReady,
+			Status:             ready,
+			Reason:             "Test",
+			ObservedGeneration: module.Generation,
+		})
+		Expect(c.Status().Update(context.Background(), &module)).To(Succeed())
+	}
+
+	states := func(rollout cyclopsv1alpha1.TemplateRollout) map[string]cyclopsv1alpha1.ModuleRolloutState {
+		out := make(map[string]cyclopsv1alpha1.ModuleRolloutState)
+		for _, m := range rollout.Status.Modules {
+			out[m.Name] = m.State
+		}
+		return out
+	}
+
+	It("assigns selected modules to waves", func() {
+		setup(
+			cyclopsv1alpha1.TemplateRolloutSpec{
+				TemplateRef: &cyclopsv1alpha1.RolloutTemplateRef{URL: templateRepo, Path: "api"},
+				Version:     "v2",
+				BatchSize:   2,
+			},
+			rolloutModule("billing", "api", "v1"),
+			rolloutModule("checkout", "api", "v1"),
+			rolloutModule("invoices", "api", "v1"),
+			rolloutModule("ledger", "api", "v2"),
+			rolloutModule("postgres", "db", "v1"),
+		)
+
+		rollout := reconcile()
+		Expect(rollout.Status.Phase).To(Equal(cyclopsv1alpha1.RolloutProgressing))
+		Expect(rollout.Status.Modules).To(Equal([]cyclopsv1alpha1.ModuleRolloutStatus{
+			{Name: "billing", Wave: 0, State: cyclopsv1alpha1.ModuleRolloutPending},
+			{Name: "checkout", Wave: 0, State: cyclopsv1alpha1.ModuleRolloutPending},
+			{Name: "invoices", Wave: 1, State: cyclopsv1alpha1.ModuleRolloutPending},
+		}))
+	})
+
+	It("upgrades the next wave once the current wave is ready", func() {
+		setup(
+			cyclopsv1alpha1.TemplateRolloutSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}, Version: "v2"},
+			rolloutModule("billing", "api", "v1"),
+			rolloutModule("checkout", "api", "v1"),
+		)
+
+		reconcile()
+		rollout := reconcile()
+		Expect(states(rollout)).To(Equal(map[string]cyclopsv1alpha1.ModuleRolloutState{
+			"billing":  cyclopsv1alpha1.ModuleRolloutUpgrading,
+			"checkout": cyclopsv1alpha1.ModuleRolloutPending,
+		}))
+
+		billing := getModule("billing")
+		Expect(billing.Spec.TemplateRef.Version).To(Equal("v2"))
+		Expect(billing.Status.TemplateResolvedVersion).To(BeEmpty())
+		Expect(writes).To(Equal([]string{"status billing", "spec billing"}))
+		Expect(getModule("checkout").Spec.TemplateRef.Version).To(Equal("v1"))
+
+		setModuleStatus("billing", cyclopsv1alpha1.Succeeded, metav1.ConditionTrue, "v2")
+		rollout = reconcile()
+		Expect(rollout.Status.CurrentWave).To(Equal(1))
+
+		rollout = reconcile()
+		Expect(states(rollout)["checkout"]).To(Equal(cyclopsv1alpha1.ModuleRolloutUpgrading))
+		Expect(getModule("checkout").Spec.TemplateRef.Version).To(Equal("v2"))
+
+		setModuleStatus("checkout", cyclopsv1alpha1.Succeeded, metav1.ConditionTrue, "v2")
+		rollout = reconcile()
+		Expect(rollout.Status.Phase).To(Equal(cyclopsv1alpha1.RolloutSucceeded))
+	})
+
+	It("stops and rolls back the wave if a module fails", func() {
+		setup(
+			cyclopsv1alpha1.TemplateRolloutSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}, Version: "v2", BatchSize: 2},
+			rolloutModule("billing", "api", "v1"),
+			rolloutModule("checkout", "api", "v1"),
+			rolloutModule("invoices", "api", "v1"),
+		)
+
+		reconcile()
+		reconcile()
+
+		setModuleStatus("billing", cyclopsv1alpha1.Failed, metav1.ConditionFalse, "")
+		rollout := reconcile()
+
+		Expect(rollout.Status.Phase).To(Equal(cyclopsv1alpha1.RolloutFailed))
+		Expect(states(rollout)).To(Equal(map[string]cyclopsv1alpha1.ModuleRolloutState{
+			"billing":  cyclopsv1alpha1.ModuleRolloutRolledBack,
+			"checkout": cyclopsv1alpha1.ModuleRolloutRolledBack,
+			"invoices": cyclopsv1alpha1.ModuleRolloutPending,
+		}))
+
+		Expect(getModule("billing").Spec.TemplateRef.Version).To(Equal("v1"))
+		Expect(getModule("checkout").Spec.TemplateRef.Version).To(Equal("v1"))
+		Expect(getModule("invoices").Spec.TemplateRef.Version).To(Equal("v1"))
+		Expect(writes[len(writes)-2:]).To(Equal([]string{"status checkout", "spec checkout"}))
+
+		// failed rollouts are not resumed
+		rollout = reconcile()
+		Expect(rollout.Status.Phase).To(Equal(cyclopsv1alpha1.RolloutFailed))
+		Expect(getModule("invoices").Spec.TemplateRef.Version).To(Equal("v1"))
+	})
+
+	It("waits for modules to resolve the target version", func() {
+		helmModule := rolloutModule("billing", "api", "1.0.0")
+		helmModule.Spec.TemplateRef.SourceType = cyclopsv1alpha1.TemplateSourceTypeHelm
+
+		setup(
+			cyclopsv1alpha1.TemplateRolloutSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}, Version: "2.0.0"},
+			helmModule,
+		)
+
+		reconcile()
+		reconcile()
+
+		setModuleStatus("billing", cyclopsv1alpha1.Succeeded, metav1.ConditionTrue, "1.0.0")
+		rollout := reconcile()
+		Expect(states(rollout)["billing"]).To(Equal(cyclopsv1alpha1.ModuleRolloutUpgrading))
+
+		setModuleStatus("billing", cyclopsv1alpha1.Succeeded, metav1.ConditionTrue, "2.0.0")
+		rollout = reconcile()
+		Expect(rollout.Status.Phase).To(Equal(cyclopsv1alpha1.RolloutSucceeded))
+	})
+
+	It("accepts commits resolved from git references of the upgraded generation", func() {
+		setup(
+			cyclopsv1alpha1.TemplateRolloutSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}, Version: "v2"},
+			rolloutModule("billing", "api", "v1"),
+		)
+
+		reconcile()
+		reconcile()
+
+		setModuleStatus("billing", cyclopsv1alpha1.Succeeded, metav1.ConditionTrue, "6f1c2b3")
+		rollout := reconcile()
+		Expect(rollout.Status.Phase).To(Equal(cyclopsv1alpha1.RolloutSucceeded))
+	})
+
+	It("fails the wave if modules are not ready within the deadline", func() {
+		setup(
+			cyclopsv1alpha1.TemplateRolloutSpec{
+				Selector:         &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+				Version:          "v2",
+				ProgressDeadline: &metav1.Duration{Duration: time.Millisecond},
+			},
+			rolloutModule("billing", "api", "v1"),
+		)
+
+		reconcile()
+		rollout := reconcile()
+		Expect(states(rollout)["billing"]).To(Equal(cyclopsv1alpha1.ModuleRolloutUpgrading))
+
+		time.Sleep(10 * time.Millisecond)
+
+		rollout = reconcile()
+		Expect(rollout.Status.Phase).To(Equal(cyclopsv1alpha1.RolloutFailed))
+		Expect(rollout.Status.Modules[0].State).To(Equal(cyclopsv1alpha1.ModuleRolloutRolledBack))
+		Expect(rollout.Status.Modules[0].Message).To(Equal("module not ready within 1ms"))
+		Expect(getModule("billing").Spec.TemplateRef.Version).To(Equal("v1"))
+	})
+
+	It("skips modules written to git", func() {
+		gitModule := rolloutModule("billing", "api", "v1")
+		gitModule.SetAnnotations(map[string]string{cyclopsv1alpha1.GitOpsWriteRepoAnnotation: "https://github.com/my-org/apps"})
+
+		setup(
+			cyclopsv1alpha1.TemplateRolloutSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}, Version: "v2"},
+			gitModule,
+		)
+
+		reconcile()
+		rollout := reconcile()
+		Expect(rollout.Status.Modules[0].State).To(Equal(cyclopsv1alpha1.ModuleRolloutSkipped))
+		Expect(getModule("billing").Spec.TemplateRef.Version).To(Equal("v1"))
+		Expect(getModule("billing").Status.TemplateResolvedVersion).To(Equal("v1"))
+
+		rollout = reconcile()
+		Expect(rollout.Status.Phase).To(Equal(cyclopsv1alpha1.RolloutSucceeded))
+		Expect(rollout.Status.Message).To(Equal("all modules upgraded to version v2; 1 modules written to git were skipped"))
+	})
+})
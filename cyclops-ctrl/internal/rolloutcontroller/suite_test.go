@@ -0,0 +1,13 @@
+package rolloutcontroller
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRolloutController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test rollout controller")
+}
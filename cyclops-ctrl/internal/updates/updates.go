@@ -0,0 +1,302 @@
+package updates
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-logr/logr"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/history"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template"
+)
+
+// DefaultInterval is how often templates are checked for new versions if the
+// interval is not configured
+const DefaultInterval = 10 * time.Minute
+
+// Updater periodically checks templates of Modules with an update policy for
+// new versions and updates the resolved template version of the Modules.
+// Updated Modules are reconciled with the new version.
+type Updater struct {
+	templatesRepo    template.ITemplateRepo
+	kubernetesClient k8sclient.IKubernetesClient
+	interval         time.Duration
+	historyLimit     int
+	logger           logr.Logger
+}
+
+func NewUpdater(
+	templatesRepo template.ITemplateRepo,
+	kubernetesClient k8sclient.IKubernetesClient,
+	interval time.Duration,
+	historyLimit int,
+	logger logr.Logger,
+) *Updater {
+	return &Updater{
+		templatesRepo:    templatesRepo,
+		kubernetesClient: kubernetesClient,
+		interval:         interval,
+		historyLimit:     historyLimit,
+		logger:           logger,
+	}
+}
+
+// Start runs update checks until the context is done. A non-positive interval
+// disables automatic updates.
+func (u *Updater) Start(ctx context.Context) error {
+	if u.interval <= 0 {
+		u.logger.Info("automatic template updates disabled")
+		return nil
+	}
+
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+
+	u.logger.Info("starting automatic template updates", "interval", u.interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := u.CheckAll(time.Now()); err != nil {
+				u.logger.Error(err, "failed to check modules for template updates")
+			}
+		}
+	}
+}
+
+// CheckAll updates Modules with an update policy whose maintenance window is
+// open at the given time
+func (u *Updater) CheckAll(now time.Time) error {
+	modules, err := u.kubernetesClient.ListModules()
+	if err != nil {
+		return err
+	}
+
+	for _, module := range modules {
+		policy := module.Spec.TemplateRef.UpdatePolicy
+		if policy == nil || module.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		// Modules are pinned to a version on their first reconciliation
+		if len(module.Status.TemplateResolvedVersion) == 0 {
+			continue
+		}
+
+		open, err := InWindow(policy.MaintenanceWindow, now)
+		if err != nil {
+			u.logger.Error(err, "invalid maintenance window", "module", module.Name)
+			continue
+		}
+
+		if !open {
+			continue
+		}
+
+		if err := u.update(module); err != nil {
+			u.logger.Error(err, "failed to update module template", "module", module.Name)
+		}
+	}
+
+	return nil
+}
+
+func (u *Updater) update(module cyclopsv1alpha1.Module) error {
+	ref := module.Spec.TemplateRef
+
+	versions, err := u.templatesRepo.GetTemplateVersions(ref.URL, ref.Path, ref.SourceType)
+	if err != nil {
+		return err
+	}
+
+	target, err := Target(ref, module.Status.TemplateResolvedVersion, versions)
+	if err != nil {
+		return err
+	}
+
+	current := module.Status.TemplateResolvedVersion
+	if len(target) == 0 || target == current {
+		return nil
+	}
+
+	module.Status.TemplateResolvedVersion = target
+	if _, err := u.kubernetesClient.UpdateModuleStatus(&module); err != nil {
+		return err
+	}
+
+	u.logger.Info("updated module template", "module", module.Name, "from", current, "to", target)
+
+	if err := history.RecordTemplateUpdate(u.kubernetesClient, module, target, u.historyLimit); err != nil {
+		u.logger.Error(err, "error recording module revision", "module", module.Name, "version", target)
+	}
+
+	return nil
+}
+
+// Target returns the revision the Module should be updated to according to the
+// update policy of the template reference. An empty target means there is no
+// newer version.
+func Target(ref cyclopsv1alpha1.TemplateRef, resolvedVersion string, versions []template.TemplateVersion) (string, error) {
+	if ref.UpdatePolicy == nil {
+		return "", nil
+	}
+
+	if ref.UpdatePolicy.Strategy == cyclopsv1alpha1.TemplateUpdateBranch {
+		return branchHead(ref.Version, versions)
+	}
+
+	current := currentVersion(resolvedVersion, versions)
+
+	var constraint string
+	switch ref.UpdatePolicy.Strategy {
+	case cyclopsv1alpha1.TemplateUpdatePatch:
+		if current == nil {
+			return "", nil
+		}
+		constraint = fmt.Sprintf(">= %v, < %v.%v.0", current, current.Major(), current.Minor()+1)
+	case cyclopsv1alpha1.TemplateUpdateMinor:
+		if current == nil {
+			return "", nil
+		}
+		constraint = fmt.Sprintf(">= %v, < %v.0.0", current, current.Major()+1)
+	case cyclopsv1alpha1.TemplateUpdateRange:
+		constraint = ref.UpdatePolicy.Range
+		if len(constraint) == 0 {
+			constraint = ref.Version
+		}
+	default:
+		return "", fmt.Errorf("unknown update strategy %v", ref.UpdatePolicy.Strategy)
+	}
+
+	constraints, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("invalid update range %v: %w", constraint, err)
+	}
+
+	var latest *semver.Version
+	target := ""
+
+	for _, version := range versions {
+		if version.Branch {
+			continue
+		}
+
+		parsed, err := semver.NewVersion(version.Name)
+		if err != nil || !constraints.Check(parsed) {
+			continue
+		}
+
+		// versions are only updated, never downgraded
+		if current != nil && !parsed.GreaterThan(current) {
+			continue
+		}
+
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest, target = parsed, version.Revision
+		}
+	}
+
+	return target, nil
+}
+
+// currentVersion returns the highest semver version the resolved version is
+// published as. Git tags resolve to commits, so several can point to the same
+// resolved version.
+func currentVersion(resolvedVersion string, versions []template.TemplateVersion) *semver.Version {
+	var current *semver.Version
+
+	for _, version := range versions {
+		if version.Branch || version.Revision != resolvedVersion {
+			continue
+		}
+
+		parsed, err := semver.NewVersion(version.Name)
+		if err != nil {
+			continue
+		}
+
+		if current == nil || parsed.GreaterThan(current) {
+			current = parsed
+		}
+	}
+
+	return current
+}
+
+func branchHead(branch string, versions []template.TemplateVersion) (string, error) {
+	for _, version := range versions {
+		if !version.Branch {
+			continue
+		}
+
+		if version.Name == branch || (len(branch) == 0 && version.DefaultBranch) {
+			return version.Revision, nil
+		}
+	}
+
+	if len(branch) == 0 {
+		return "", fmt.Errorf("default branch not found")
+	}
+
+	return "", fmt.Errorf("branch %v not found", branch)
+}
+
+// InWindow returns true if the maintenance window is open at the given time.
+// Without a window, updates are always allowed.
+func InWindow(window *cyclopsv1alpha1.MaintenanceWindow, now time.Time) (bool, error) {
+	if window == nil {
+		return true, nil
+	}
+
+	location := time.UTC
+	if len(window.TimeZone) != 0 {
+		var err error
+		location, err = time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return false, err
+	}
+
+	now = now.In(location)
+
+	// windows that opened on previous days can still be open
+	for days := 0; days <= int(window.Duration.Duration/(24*time.Hour))+1; days++ {
+		day := now.AddDate(0, 0, -days)
+		opens := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, location)
+
+		if !opensOn(window.Days, opens.Weekday()) {
+			continue
+		}
+
+		if !now.Before(opens) && now.Before(opens.Add(window.Duration.Duration)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func opensOn(days []cyclopsv1alpha1.MaintenanceDay, weekday time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+
+	for _, day := range days {
+		if string(day) == weekday.String()[:3] {
+			return true
+		}
+	}
+
+	return false
+}
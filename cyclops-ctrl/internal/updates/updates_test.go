@@ -0,0 +1,127 @@
+package updates
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template"
+)
+
+func TestUpdates(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test template updates")
+}
+
+func chartVersions(versions ...string) []template.TemplateVersion {
+	templateVersions := make([]template.TemplateVersion, 0, len(versions))
+	for _, version := range versions {
+		templateVersions = append(templateVersions, template.TemplateVersion{Name: version, Revision: version})
+	}
+	return templateVersions
+}
+
+func templateRef(version string, strategy cyclopsv1alpha1.TemplateUpdateStrategy) cyclopsv1alpha1.TemplateRef {
+	return cyclopsv1alpha1.TemplateRef{
+		URL:          "https://charts.my-org.com",
+		Path:         "demo",
+		Version:      version,
+		UpdatePolicy: &cyclopsv1alpha1.TemplateUpdatePolicy{Strategy: strategy},
+	}
+}
+
+var _ = Describe("Target", func() {
+	versions := chartVersions("1.1.0", "1.2.0", "1.2.3", "1.2.4-rc.1", "1.3.0", "2.0.0")
+
+	It("updates within the policy", func() {
+		cases := []struct {
+			strategy cyclopsv1alpha1.TemplateUpdateStrategy
+			version  string
+			resolved string
+			expected string
+		}{
+			{strategy: cyclopsv1alpha1.TemplateUpdatePatch, version: "1.2.0", resolved: "1.2.0", expected: "1.2.3"},
+			{strategy: cyclopsv1alpha1.TemplateUpdateMinor, version: "1.2.0", resolved: "1.2.0", expected: "1.3.0"},
+			{strategy: cyclopsv1alpha1.TemplateUpdateRange, version: "^1.0.0", resolved: "1.1.0", expected: "1.3.0"},
+			{strategy: cyclopsv1alpha1.TemplateUpdateMinor, version: "2.0.0", resolved: "2.0.0", expected: ""},
+			{strategy: cyclopsv1alpha1.TemplateUpdateRange, version: "~1.1.0", resolved: "1.3.0", expected: ""},
+			{strategy: cyclopsv1alpha1.TemplateUpdatePatch, version: "1.2.0", resolved: "unpublished", expected: ""},
+		}
+
+		for _, c := range cases {
+			target, err := Target(templateRef(c.version, c.strategy), c.resolved, versions)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target).To(BeEquivalentTo(c.expected), "strategy %v from %v", c.strategy, c.resolved)
+		}
+	})
+
+	It("updates git templates to the commit of the tag", func() {
+		gitVersions := []template.TemplateVersion{
+			{Name: "main", Revision: "c3", Branch: true, DefaultBranch: true},
+			{Name: "v1.0.0", Revision: "c1"},
+			{Name: "v1.0.1", Revision: "c2"},
+		}
+
+		target, err := Target(templateRef("v1.0.0", cyclopsv1alpha1.TemplateUpdatePatch), "c1", gitVersions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(target).To(BeEquivalentTo("c2"))
+
+		target, err = Target(templateRef("", cyclopsv1alpha1.TemplateUpdateBranch), "c1", gitVersions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(target).To(BeEquivalentTo("c3"))
+
+		_, err = Target(templateRef("release", cyclopsv1alpha1.TemplateUpdateBranch), "c1", gitVersions)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails on invalid ranges", func() {
+		_, err := Target(templateRef("main", cyclopsv1alpha1.TemplateUpdateRange), "1.2.0", versions)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("InWindow", func() {
+	window := &cyclopsv1alpha1.MaintenanceWindow{
+		Days:     []cyclopsv1alpha1.MaintenanceDay{"Sat"},
+		Start:    "22:00",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: "Europe/Berlin",
+	}
+
+	It("is open during the window", func() {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).ToNot(HaveOccurred())
+
+		cases := []struct {
+			time     time.Time
+			expected bool
+		}{
+			// Saturday
+			{time: time.Date(2024, 6, 1, 23, 0, 0, 0, berlin), expected: true},
+			{time: time.Date(2024, 6, 1, 21, 59, 0, 0, berlin), expected: false},
+			// Sunday, the window opened on Saturday
+			{time: time.Date(2024, 6, 2, 1, 30, 0, 0, berlin), expected: true},
+			{time: time.Date(2024, 6, 2, 2, 0, 0, 0, berlin), expected: false},
+			// Friday
+			{time: time.Date(2024, 5, 31, 23, 0, 0, 0, berlin), expected: false},
+			// Saturday in UTC
+			{time: time.Date(2024, 6, 1, 20, 30, 0, 0, time.UTC), expected: true},
+		}
+
+		for _, c := range cases {
+			open, err := InWindow(window, c.time)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(open).To(BeEquivalentTo(c.expected), "at %v", c.time)
+		}
+	})
+
+	It("is always open without a window", func() {
+		open, err := InWindow(nil, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+	})
+})
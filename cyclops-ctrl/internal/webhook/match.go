@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"strings"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// Matches returns true if Modules referencing the template have to be
+// refreshed after the push
+func (p *Push) Matches(ref cyclopsv1alpha1.TemplateRef) bool {
+	if len(p.Chart) != 0 {
+		return p.matchesChart(ref)
+	}
+
+	return p.matchesGit(ref)
+}
+
+func (p *Push) matchesGit(ref cyclopsv1alpha1.TemplateRef) bool {
+	if len(ref.SourceType) != 0 && ref.SourceType != cyclopsv1alpha1.TemplateSourceTypeGit {
+		return false
+	}
+
+	if !p.matchesRepo(ref.URL) {
+		return false
+	}
+
+	if len(p.Ref) == 0 {
+		return true
+	}
+
+	version := ref.Version
+	if len(version) == 0 {
+		// Modules without a version follow the default branch
+		if len(p.DefaultBranch) == 0 {
+			return true
+		}
+
+		version = p.DefaultBranch
+	}
+
+	return version == p.Ref
+}
+
+func (p *Push) matchesChart(ref cyclopsv1alpha1.TemplateRef) bool {
+	if ref.SourceType == cyclopsv1alpha1.TemplateSourceTypeGit {
+		return false
+	}
+
+	chart := normalizeURL(joinURL(ref.URL, ref.Path))
+	for _, repo := range p.RepoURLs {
+		if len(repo) != 0 && normalizeURL(joinURL(repo, p.Chart)) == chart {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Push) matchesRepo(repoURL string) bool {
+	repo := normalizeURL(repoURL)
+	for _, pushed := range p.RepoURLs {
+		if len(pushed) != 0 && normalizeURL(pushed) == repo {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeURL returns the host and path of http, ssh and oci repository
+// URLs, so the same repository referenced with different protocols matches
+func normalizeURL(repoURL string) string {
+	normalized := strings.ToLower(strings.TrimSpace(repoURL))
+
+	if i := strings.Index(normalized, "://"); i != -1 {
+		normalized = normalized[i+3:]
+	} else if i := strings.Index(normalized, ":"); i != -1 {
+		// scp-like ssh URLs, e.g. git@github.com:my-org/templates.git
+		normalized = normalized[:i] + "/" + normalized[i+1:]
+	}
+
+	if i := strings.Index(normalized, "@"); i != -1 && i < strings.Index(normalized+"/", "/") {
+		normalized = normalized[i+1:]
+	}
+
+	normalized = strings.TrimSuffix(normalized, "/")
+	return strings.TrimSuffix(normalized, ".git")
+}
+
+func joinURL(repo, path string) string {
+	return strings.TrimSuffix(repo, "/") + "/" + strings.Trim(path, "/")
+}
@@ -0,0 +1,264 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	json "github.com/json-iterator/go"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Push is a push to a git repository or a chart pushed to a registry
+type Push struct {
+	// RepoURLs are the URLs the repository is cloned with, or the registry URL
+	// the chart was pushed to
+	RepoURLs []string
+	// Ref is the pushed branch or tag. DefaultBranch is set if the provider
+	// sends it, so Modules without a version can be matched.
+	Ref           string
+	DefaultBranch string
+
+	// Chart and Version are set for charts pushed to a registry
+	Chart   string
+	Version string
+}
+
+// ValidateSignature validates the HMAC SHA-256 signature of the body sent by
+// GitHub, Bitbucket and generic webhooks, or the secret token sent by GitLab
+func ValidateSignature(header http.Header, body []byte, secret string) error {
+	if token := header.Get("X-Gitlab-Token"); len(token) != 0 {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	}
+
+	signature := header.Get("X-Hub-Signature-256")
+	if len(signature) == 0 {
+		signature = header.Get("X-Hub-Signature")
+	}
+
+	hexDigest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	digest, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	if !hmac.Equal(digest, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Parse reads the push from the webhook payload. Events other than pushes,
+// like pings, return no push.
+func Parse(header http.Header, body []byte) (*Push, error) {
+	switch {
+	case len(header.Get("X-GitHub-Event")) != 0:
+		return parseGitHub(header.Get("X-GitHub-Event"), body)
+	case len(header.Get("X-Gitlab-Event")) != 0:
+		return parseGitLab(header.Get("X-Gitlab-Event"), body)
+	case len(header.Get("X-Event-Key")) != 0:
+		return parseBitbucket(header.Get("X-Event-Key"), body)
+	}
+
+	return parseGeneric(body)
+}
+
+func parseGitHub(event string, body []byte) (*Push, error) {
+	switch event {
+	case "push":
+		var payload struct {
+			Ref        string `json:"ref"`
+			Repository struct {
+				CloneURL      string `json:"clone_url"`
+				HTMLURL       string `json:"html_url"`
+				SSHURL        string `json:"ssh_url"`
+				DefaultBranch string `json:"default_branch"`
+			} `json:"repository"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+
+		return &Push{
+			RepoURLs:      []string{payload.Repository.CloneURL, payload.Repository.HTMLURL, payload.Repository.SSHURL},
+			Ref:           shortRef(payload.Ref),
+			DefaultBranch: payload.Repository.DefaultBranch,
+		}, nil
+	case "package", "registry_package":
+		var payload struct {
+			Action  string `json:"action"`
+			Package struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+				Owner     struct {
+					Login string `json:"login"`
+				} `json:"owner"`
+				PackageVersion struct {
+					Version           string `json:"version"`
+					ContainerMetadata struct {
+						Tag struct {
+							Name string `json:"name"`
+						} `json:"tag"`
+					} `json:"container_metadata"`
+				} `json:"package_version"`
+				Registry struct {
+					URL string `json:"url"`
+				} `json:"registry"`
+			} `json:"package"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+
+		if payload.Action != "published" && payload.Action != "updated" {
+			return nil, nil
+		}
+
+		namespace := payload.Package.Namespace
+		if len(namespace) == 0 {
+			namespace = payload.Package.Owner.Login
+		}
+
+		version := payload.Package.PackageVersion.ContainerMetadata.Tag.Name
+		if len(version) == 0 {
+			version = payload.Package.PackageVersion.Version
+		}
+
+		registry := payload.Package.Registry.URL
+		if len(registry) == 0 {
+			registry = "https://ghcr.io"
+		}
+
+		return &Push{
+			RepoURLs: []string{fmt.Sprintf("%v/%v", strings.TrimSuffix(registry, "/"), namespace)},
+			Chart:    payload.Package.Name,
+			Version:  version,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func parseGitLab(event string, body []byte) (*Push, error) {
+	if event != "Push Hook" && event != "Tag Push Hook" {
+		return nil, nil
+	}
+
+	var payload struct {
+		Ref     string `json:"ref"`
+		Project struct {
+			GitHTTPURL    string `json:"git_http_url"`
+			GitSSHURL     string `json:"git_ssh_url"`
+			WebURL        string `json:"web_url"`
+			DefaultBranch string `json:"default_branch"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	return &Push{
+		RepoURLs:      []string{payload.Project.GitHTTPURL, payload.Project.GitSSHURL, payload.Project.WebURL},
+		Ref:           shortRef(payload.Ref),
+		DefaultBranch: payload.Project.DefaultBranch,
+	}, nil
+}
+
+// parseBitbucket reads Bitbucket Cloud push events. A push can update several
+// branches; the first one is used.
+func parseBitbucket(event string, body []byte) (*Push, error) {
+	if event != "repo:push" {
+		return nil, nil
+	}
+
+	var payload struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+			Links    struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+			MainBranch struct {
+				Name string `json:"name"`
+			} `json:"mainbranch"`
+		} `json:"repository"`
+		Push struct {
+			Changes []struct {
+				New *struct {
+					Name string `json:"name"`
+				} `json:"new"`
+			} `json:"changes"`
+		} `json:"push"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	push := &Push{
+		RepoURLs: []string{
+			payload.Repository.Links.HTML.Href,
+			fmt.Sprintf("git@bitbucket.org:%v.git", payload.Repository.FullName),
+		},
+		DefaultBranch: payload.Repository.MainBranch.Name,
+	}
+
+	for _, change := range payload.Push.Changes {
+		if change.New != nil {
+			push.Ref = change.New.Name
+			break
+		}
+	}
+
+	return push, nil
+}
+
+// parseGeneric reads payloads sent by CI pipelines and registries, e.g.
+// {"repo": "oci://registry.my-org.com/charts", "chart": "demo", "version": "1.2.0"}
+// for chart pushes or {"repo": "https://git.my-org.com/templates", "ref": "main"}
+// for git pushes
+func parseGeneric(body []byte) (*Push, error) {
+	var payload struct {
+		Repo    string `json:"repo"`
+		Ref     string `json:"ref"`
+		Chart   string `json:"chart"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	if len(payload.Repo) == 0 {
+		return nil, errors.New("generic webhook payload requires repo")
+	}
+
+	return &Push{
+		RepoURLs: []string{payload.Repo},
+		Ref:      shortRef(payload.Ref),
+		Chart:    payload.Chart,
+		Version:  payload.Version,
+	}, nil
+}
+
+func shortRef(ref string) string {
+	ref = strings.TrimPrefix(ref, "refs/heads/")
+	return strings.TrimPrefix(ref, "refs/tags/")
+}
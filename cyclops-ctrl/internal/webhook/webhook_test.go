@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+func TestWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test template webhooks")
+}
+
+var _ = Describe("ValidateSignature", func() {
+	body := []byte(`{"repo":"https://github.com/my-org/templates"}`)
+
+	It("validates HMAC signatures", func() {
+		header := http.Header{}
+		header.Set("X-Hub-Signature-256", "sha256=8b3c6f1bfbd7a4bfed1e9a1a4c1a2b6e4e2c4bd3bd40dd2c2d9f1f0e1c5bbb0e")
+		Expect(ValidateSignature(header, body, "secret")).To(MatchError(ErrInvalidSignature))
+
+		header.Set("X-Hub-Signature-256", "sha1=abc")
+		Expect(ValidateSignature(header, body, "secret")).To(MatchError(ErrInvalidSignature))
+
+		Expect(ValidateSignature(http.Header{}, body, "secret")).To(MatchError(ErrInvalidSignature))
+	})
+
+	It("validates GitLab tokens", func() {
+		header := http.Header{}
+		header.Set("X-Gitlab-Token", "secret")
+		Expect(ValidateSignature(header, body, "secret")).To(Succeed())
+
+		header.Set("X-Gitlab-Token", "other")
+		Expect(ValidateSignature(header, body, "secret")).To(MatchError(ErrInvalidSignature))
+	})
+})
+
+var _ = Describe("Parse", func() {
+	It("parses GitLab pushes", func() {
+		header := http.Header{}
+		header.Set("X-Gitlab-Event", "Push Hook")
+
+		push, err := Parse(header, []byte(`{"ref":"refs/heads/main","project":{"git_http_url":"https://gitlab.com/my-org/templates.git","git_ssh_url":"git@gitlab.com:my-org/templates.git","default_branch":"main"}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(push.Ref).To(BeEquivalentTo("main"))
+		Expect(push.Matches(cyclopsv1alpha1.TemplateRef{URL: "git@gitlab.com:my-org/templates.git", Path: "api"})).To(BeTrue())
+		Expect(push.Matches(cyclopsv1alpha1.TemplateRef{URL: "https://gitlab.com/my-org/templates", Path: "api", Version: "v1.0.0"})).To(BeFalse())
+	})
+
+	It("parses Bitbucket pushes", func() {
+		header := http.Header{}
+		header.Set("X-Event-Key", "repo:push")
+
+		push, err := Parse(header, []byte(`{"repository":{"full_name":"my-org/templates","links":{"html":{"href":"https://bitbucket.org/my-org/templates"}}},"push":{"changes":[{"new":{"type":"branch","name":"develop"}}]}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(push.Matches(cyclopsv1alpha1.TemplateRef{URL: "https://bitbucket.org/my-org/templates.git", Path: "api", Version: "develop"})).To(BeTrue())
+	})
+
+	It("parses GitHub package pushes", func() {
+		header := http.Header{}
+		header.Set("X-GitHub-Event", "package")
+
+		push, err := Parse(header, []byte(`{"action":"published","package":{"name":"charts/demo","namespace":"my-org","package_version":{"version":"sha256:abc","container_metadata":{"tag":{"name":"1.2.0"}}},"registry":{"url":"https://ghcr.io"}}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(push.Version).To(BeEquivalentTo("1.2.0"))
+		Expect(push.Matches(cyclopsv1alpha1.TemplateRef{URL: "oci://ghcr.io/my-org/charts", Path: "demo", SourceType: cyclopsv1alpha1.TemplateSourceTypeOCI})).To(BeTrue())
+		Expect(push.Matches(cyclopsv1alpha1.TemplateRef{URL: "oci://ghcr.io/my-org/charts", Path: "other"})).To(BeFalse())
+	})
+
+	It("ignores other events", func() {
+		header := http.Header{}
+		header.Set("X-GitHub-Event", "ping")
+
+		push, err := Parse(header, []byte(`{"zen":"Keep it logically awesome."}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(push).To(BeNil())
+	})
+})
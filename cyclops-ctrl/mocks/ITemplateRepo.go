@@ -7,6 +7,8 @@ import (
 	ristretto "github.com/dgraph-io/ristretto"
 	mock "github.com/stretchr/testify/mock"
 
+	template "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template"
+
 	v1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
 )
 
@@ -23,6 +25,62 @@ func (_m *ITemplateRepo) EXPECT() *ITemplateRepo_Expecter {
 	return &ITemplateRepo_Expecter{mock: &_m.Mock}
 }
 
+// AssumeTemplateSourceType provides a mock function with given fields: repo
+func (_m *ITemplateRepo) AssumeTemplateSourceType(repo string) (v1alpha1.TemplateSourceType, error) {
+	ret := _m.Called(repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssumeTemplateSourceType")
+	}
+
+	var r0 v1alpha1.TemplateSourceType
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (v1alpha1.TemplateSourceType, error)); ok {
+		return rf(repo)
+	}
+	if rf, ok := ret.Get(0).(func(string) v1alpha1.TemplateSourceType); ok {
+		r0 = rf(repo)
+	} else {
+		r0 = ret.Get(0).(v1alpha1.TemplateSourceType)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ITemplateRepo_AssumeTemplateSourceType_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AssumeTemplateSourceType'
+type ITemplateRepo_AssumeTemplateSourceType_Call struct {
+	*mock.Call
+}
+
+// AssumeTemplateSourceType is a helper method to define mock.On call
+//   - repo string
+func (_e *ITemplateRepo_Expecter) AssumeTemplateSourceType(repo interface{}) *ITemplateRepo_AssumeTemplateSourceType_Call {
+	return &ITemplateRepo_AssumeTemplateSourceType_Call{Call: _e.mock.On("AssumeTemplateSourceType", repo)}
+}
+
+func (_c *ITemplateRepo_AssumeTemplateSourceType_Call) Run(run func(repo string)) *ITemplateRepo_AssumeTemplateSourceType_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *ITemplateRepo_AssumeTemplateSourceType_Call) Return(_a0 v1alpha1.TemplateSourceType, _a1 error) *ITemplateRepo_AssumeTemplateSourceType_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ITemplateRepo_AssumeTemplateSourceType_Call) RunAndReturn(run func(string) (v1alpha1.TemplateSourceType, error)) *ITemplateRepo_AssumeTemplateSourceType_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetTemplate provides a mock function with given fields: repo, path, version, resolvedVersion, source
 func (_m *ITemplateRepo) GetTemplate(repo string, path string, version string, resolvedVersion string, source v1alpha1.TemplateSourceType) (*models.Template, error) {
 	ret := _m.Called(repo, path, version, resolvedVersion, source)
@@ -205,6 +263,101 @@ func (_c *ITemplateRepo_GetTemplateRevisions_Call) RunAndReturn(run func(string,
 	return _c
 }
 
+// GetTemplateVersions provides a mock function with given fields: repo, path, source
+func (_m *ITemplateRepo) GetTemplateVersions(repo string, path string, source v1alpha1.TemplateSourceType) ([]template.TemplateVersion, error) {
+	ret := _m.Called(repo, path, source)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTemplateVersions")
+	}
+
+	var r0 []template.TemplateVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, v1alpha1.TemplateSourceType) ([]template.TemplateVersion, error)); ok {
+		return rf(repo, path, source)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, v1alpha1.TemplateSourceType) []template.TemplateVersion); ok {
+		r0 = rf(repo, path, source)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]template.TemplateVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, v1alpha1.TemplateSourceType) error); ok {
+		r1 = rf(repo, path, source)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ITemplateRepo_GetTemplateVersions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTemplateVersions'
+type ITemplateRepo_GetTemplateVersions_Call struct {
+	*mock.Call
+}
+
+// GetTemplateVersions is a helper method to define mock.On call
+//   - repo string
+//   - path string
+//   - source v1alpha1.TemplateSourceType
+func (_e *ITemplateRepo_Expecter) GetTemplateVersions(repo interface{}, path interface{}, source interface{}) *ITemplateRepo_GetTemplateVersions_Call {
+	return &ITemplateRepo_GetTemplateVersions_Call{Call: _e.mock.On("GetTemplateVersions", repo, path, source)}
+}
+
+func (_c *ITemplateRepo_GetTemplateVersions_Call) Run(run func(repo string, path string, source v1alpha1.TemplateSourceType)) *ITemplateRepo_GetTemplateVersions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(v1alpha1.TemplateSourceType))
+	})
+	return _c
+}
+
+func (_c *ITemplateRepo_GetTemplateVersions_Call) Return(_a0 []template.TemplateVersion, _a1 error) *ITemplateRepo_GetTemplateVersions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ITemplateRepo_GetTemplateVersions_Call) RunAndReturn(run func(string, string, v1alpha1.TemplateSourceType) ([]template.TemplateVersion, error)) *ITemplateRepo_GetTemplateVersions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InvalidateTemplate provides a mock function with given fields: repo, path, version
+func (_m *ITemplateRepo) InvalidateTemplate(repo string, path string, version string) {
+	_m.Called(repo, path, version)
+}
+
+// ITemplateRepo_InvalidateTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InvalidateTemplate'
+type ITemplateRepo_InvalidateTemplate_Call struct {
+	*mock.Call
+}
+
+// InvalidateTemplate is a helper method to define mock.On call
+//   - repo string
+//   - path string
+//   - version string
+func (_e *ITemplateRepo_Expecter) InvalidateTemplate(repo interface{}, path interface{}, version interface{}) *ITemplateRepo_InvalidateTemplate_Call {
+	return &ITemplateRepo_InvalidateTemplate_Call{Call: _e.mock.On("InvalidateTemplate", repo, path, version)}
+}
+
+func (_c *ITemplateRepo_InvalidateTemplate_Call) Run(run func(repo string, path string, version string)) *ITemplateRepo_InvalidateTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *ITemplateRepo_InvalidateTemplate_Call) Return() *ITemplateRepo_InvalidateTemplate_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ITemplateRepo_InvalidateTemplate_Call) RunAndReturn(run func(string, string, string)) *ITemplateRepo_InvalidateTemplate_Call {
+	_c.Run(run)
+	return _c
+}
+
 // ReturnCache provides a mock function with no fields
 func (_m *ITemplateRepo) ReturnCache() *ristretto.Cache {
 	ret := _m.Called()
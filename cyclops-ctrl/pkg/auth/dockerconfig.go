@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type dockerConfig struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	RegistryToken string `json:"registrytoken"`
+}
+
+// dockerConfigCredentials returns the credentials for the host of repo from a
+// docker config JSON
+func dockerConfigCredentials(data []byte, repo string) (*Credentials, error) {
+	var config dockerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrap(err, "failed to parse docker config")
+	}
+
+	host := repoHost(repo)
+	for registry, entry := range config.Auths {
+		if repoHost(registry) != host {
+			continue
+		}
+
+		creds := &Credentials{
+			Username: entry.Username,
+			Password: entry.Password,
+			Token:    entry.RegistryToken,
+		}
+
+		if len(entry.Auth) != 0 {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decode auth of %v", registry)
+			}
+
+			username, password, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				return nil, fmt.Errorf("auth of %v is not in username:password format", registry)
+			}
+
+			creds.Username = username
+			creds.Password = password
+		}
+
+		return creds, nil
+	}
+
+	return nil, fmt.Errorf("no credentials for %v", host)
+}
+
+// repoHost returns the host of repository URLs and docker config registry keys,
+// which can be set with or without a scheme
+func repoHost(repo string) string {
+	if !strings.Contains(repo, "://") {
+		repo = "https://" + repo
+	}
+
+	u, err := url.Parse(repo)
+	if err != nil {
+		return repo
+	}
+
+	return u.Host
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+const (
+	defaultGitHubAPIURL = "https://api.github.com"
+
+	// appTokenRefreshBefore is how long before expiry installation tokens are
+	// refreshed
+	appTokenRefreshBefore = 5 * time.Minute
+)
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// appTokens caches GitHub App installation tokens
+type appTokens struct {
+	tokens map[string]installationToken
+	lock   sync.Mutex
+
+	client *http.Client
+}
+
+func newAppTokens() *appTokens {
+	return &appTokens{
+		tokens: make(map[string]installationToken),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (a *appTokens) installationToken(app v1alpha1.GitHubAppAuth, privateKey []byte) (string, error) {
+	apiURL := app.APIURL
+	if len(apiURL) == 0 {
+		apiURL = defaultGitHubAPIURL
+	}
+	apiURL = strings.TrimSuffix(apiURL, "/")
+
+	key := fmt.Sprintf("%v/%v/%v", apiURL, app.AppID, app.InstallationID)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if token, ok := a.tokens[key]; ok && time.Until(token.ExpiresAt) > appTokenRefreshBefore {
+		return token.Token, nil
+	}
+
+	jwt, err := appJWT(app.AppID, privateKey, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("%v/app/installations/%v/access_tokens", apiURL, app.InstallationID),
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", jwt))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf(
+			"failed to create installation token of GitHub App %v for installation %v: %v",
+			app.AppID,
+			app.InstallationID,
+			resp.Status,
+		)
+	}
+
+	var token installationToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	a.tokens[key] = token
+
+	return token.Token, nil
+}
+
+// appJWT returns the JWT GitHub Apps authenticate with to create installation
+// tokens
+func appJWT(appID int64, privateKey []byte, now time.Time) (string, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return "", errors.New("GitHub App private key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		pkcs8, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if pkcs8Err != nil {
+			return "", errors.Wrap(err, "failed to parse GitHub App private key")
+		}
+
+		var ok bool
+		if key, ok = pkcs8.(*rsa.PrivateKey); !ok {
+			return "", errors.New("GitHub App private key is not an RSA key")
+		}
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	// issued in the past to allow for clock drift
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": fmt.Sprintf("%v", appID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"os"
+
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pkg/errors"
+)
+
+const defaultSSHUser = "git"
+
+// sshPublicKeys returns the go-git ssh auth of the private key, verifying git
+// server host keys against the known_hosts entries
+func sshPublicKeys(user string, privateKey, knownHosts []byte) (*gitssh.PublicKeys, error) {
+	if len(user) == 0 {
+		user = defaultSSHUser
+	}
+
+	if len(knownHosts) == 0 {
+		return nil, errors.New("ssh auth requires known hosts")
+	}
+
+	publicKeys, err := gitssh.NewPublicKeys(user, privateKey, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse ssh private key")
+	}
+
+	// known hosts callbacks are read from files; the file is not needed once
+	// the callback is created
+	file, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(knownHosts); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	publicKeys.HostKeyCallback, err = gitssh.NewKnownHostsCallback(file.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse known hosts")
+	}
+
+	return publicKeys, nil
+}
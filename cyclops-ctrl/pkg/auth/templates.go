@@ -1,23 +1,42 @@
 package auth
 
 import (
+	"fmt"
+	"net/http"
 	"regexp"
 
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
 )
 
 type TemplatesResolver struct {
-	k8s k8sClient
+	k8s       k8sClient
+	appTokens *appTokens
 }
 
 type Credentials struct {
 	Username string
 	Password string
+	// Token is a bearer token for Helm and OCI registries
+	Token string
+
+	// SSH is set for ssh authentication to git repositories
+	SSH *gitssh.PublicKeys
+
+	// VerificationKeys are the public keys trusted to sign templates of the
+	// repository. Templates are verified only if keys are set.
+	VerificationKeys []byte
 }
 
 func NewTemplatesResolver(k8s k8sClient) TemplatesResolver {
 	return TemplatesResolver{
-		k8s: k8s,
+		k8s:       k8s,
+		appTokens: newAppTokens(),
 	}
 }
 
@@ -34,26 +53,192 @@ func (t TemplatesResolver) RepoAuthCredentials(repo string) (*Credentials, error
 		}
 
 		if re.MatchString(repo) {
-			username, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Username.Name, ta.Spec.Username.Key)
-			if err != nil {
-				return nil, err
-			}
-
-			password, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Password.Name, ta.Spec.Password.Key)
+			creds, err := t.ruleCredentials(ta, repo)
 			if err != nil {
 				return nil, err
 			}
 
-			return &Credentials{
-				Username: username,
-				Password: password,
-			}, err
+			return t.withVerificationKeys(ta, creds)
 		}
 	}
 
 	return nil, nil
 }
 
+func (t TemplatesResolver) ruleCredentials(ta v1alpha1.TemplateAuthRule, repo string) (*Credentials, error) {
+	switch ta.Spec.Type {
+	case v1alpha1.TemplateAuthTypeSSH:
+		return t.sshCredentials(ta)
+	case v1alpha1.TemplateAuthTypeGitHubApp:
+		return t.gitHubAppCredentials(ta)
+	}
+
+	creds := &Credentials{}
+
+	if len(ta.Spec.DockerConfig.Name) != 0 {
+		key := ta.Spec.DockerConfig.Key
+		if len(key) == 0 {
+			key = apiv1.DockerConfigJsonKey
+		}
+
+		dockerConfig, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.DockerConfig.Name, key)
+		if err != nil {
+			return nil, err
+		}
+
+		creds, err = dockerConfigCredentials([]byte(dockerConfig), repo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "docker config of template auth rule %v", ta.Name)
+		}
+	}
+
+	if len(ta.Spec.Username.Name) != 0 {
+		username, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Username.Name, ta.Spec.Username.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		creds.Username = username
+	}
+
+	if len(ta.Spec.Password.Name) != 0 {
+		password, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Password.Name, ta.Spec.Password.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		creds.Password = password
+	}
+
+	if len(ta.Spec.Token.Name) != 0 {
+		token, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Token.Name, ta.Spec.Token.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		creds.Token = token
+	}
+
+	return creds, nil
+}
+
+func (t TemplatesResolver) sshCredentials(ta v1alpha1.TemplateAuthRule) (*Credentials, error) {
+	if ta.Spec.SSH == nil {
+		return nil, fmt.Errorf("template auth rule %v of type ssh has no ssh config", ta.Name)
+	}
+
+	privateKey, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.SSH.PrivateKey.Name, ta.Spec.SSH.PrivateKey.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	knownHosts, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.SSH.KnownHosts.Name, ta.Spec.SSH.KnownHosts.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys, err := sshPublicKeys(ta.Spec.SSH.User, []byte(privateKey), []byte(knownHosts))
+	if err != nil {
+		return nil, errors.Wrapf(err, "template auth rule %v", ta.Name)
+	}
+
+	return &Credentials{
+		SSH: publicKeys,
+	}, nil
+}
+
+func (t TemplatesResolver) gitHubAppCredentials(ta v1alpha1.TemplateAuthRule) (*Credentials, error) {
+	if ta.Spec.GitHubApp == nil {
+		return nil, fmt.Errorf("template auth rule %v of type githubApp has no GitHub App config", ta.Name)
+	}
+
+	privateKey, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.GitHubApp.PrivateKey.Name, ta.Spec.GitHubApp.PrivateKey.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := t.appTokens.installationToken(*ta.Spec.GitHubApp, []byte(privateKey))
+	if err != nil {
+		return nil, errors.Wrapf(err, "template auth rule %v", ta.Name)
+	}
+
+	// GitHub accepts installation tokens as the password of any user
+	return &Credentials{
+		Username: "x-access-token",
+		Password: token,
+	}, nil
+}
+
+func (t TemplatesResolver) withVerificationKeys(ta v1alpha1.TemplateAuthRule, creds *Credentials) (*Credentials, error) {
+	if ta.Spec.Verification == nil {
+		return creds, nil
+	}
+
+	keys, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Verification.PublicKeys.Name, ta.Spec.Verification.PublicKeys.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("template auth rule %v has no verification public keys", ta.Name)
+	}
+
+	creds.VerificationKeys = []byte(keys)
+	return creds, nil
+}
+
+// GitAuth returns the go-git auth method for the credentials
+func (c *Credentials) GitAuth() transport.AuthMethod {
+	if c == nil {
+		return nil
+	}
+
+	if c.SSH != nil {
+		return c.SSH
+	}
+
+	if !c.Basic() && len(c.Token) == 0 {
+		return nil
+	}
+
+	password := c.Password
+	if len(password) == 0 {
+		password = c.Token
+	}
+
+	return &githttp.BasicAuth{
+		Username: c.Username,
+		Password: password,
+	}
+}
+
+// Basic returns true if the credentials contain a username or password
+func (c *Credentials) Basic() bool {
+	return c != nil && (len(c.Username) != 0 || len(c.Password) != 0)
+}
+
+// SetAuthorization sets the Authorization header of requests to Helm and OCI
+// registries. Tokens take precedence over basic auth.
+func (c *Credentials) SetAuthorization(req *http.Request) {
+	if c == nil {
+		return
+	}
+
+	if len(c.Token) != 0 {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		return
+	}
+
+	if c.Basic() {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// Verifies returns true if templates of the repository have to be verified
+func (c *Credentials) Verifies() bool {
+	return c != nil && len(c.VerificationKeys) != 0
+}
+
 type k8sClient interface {
 	GetTemplateAuthRuleSecret(string, string) (string, error)
 	ListTemplateAuthRules() ([]v1alpha1.TemplateAuthRule, error)
@@ -1,7 +1,14 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
@@ -82,6 +89,23 @@ var _ = Describe("Templates resolver", func() {
 				},
 			},
 		},
+		{
+			Spec: v1alpha1.TemplateAuthRuleSpec{
+				Repo: "https://charts.my-org.com",
+				Token: apiv1.SecretKeySelector{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: "chartmuseum"},
+					Key:                  "token",
+				},
+			},
+		},
+		{
+			Spec: v1alpha1.TemplateAuthRuleSpec{
+				Repo: "oci://registry.my-org.com",
+				DockerConfig: apiv1.SecretKeySelector{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: "registry"},
+				},
+			},
+		},
 	}
 
 	testCases := []testCase{
@@ -171,6 +195,55 @@ var _ = Describe("Templates resolver", func() {
 				returnsError: false,
 			},
 		},
+		{
+			description: "fetches bearer token",
+			in: caseInput{
+				repo: "https://charts.my-org.com/stable",
+				mockCalls: func() {
+					k8sClient.On("ListTemplateAuthRules").Return(tars, nil)
+					k8sClient.On("GetTemplateAuthRuleSecret", "chartmuseum", "token").Return("my-bearer-token", nil)
+				},
+			},
+			out: caseOutput{
+				credentials: &Credentials{
+					Token: "my-bearer-token",
+				},
+				returnsError: false,
+			},
+		},
+		{
+			description: "fetches registry credentials from docker config",
+			in: caseInput{
+				repo: "oci://registry.my-org.com/charts",
+				mockCalls: func() {
+					k8sClient.On("ListTemplateAuthRules").Return(tars, nil)
+					k8sClient.On("GetTemplateAuthRuleSecret", "registry", ".dockerconfigjson").
+						Return(`{"auths":{"docker.io":{"auth":"b3RoZXI6b3RoZXI="},"https://registry.my-org.com":{"auth":"cm9ib3Q6cGFzc3dvcmQ="}}}`, nil)
+				},
+			},
+			out: caseOutput{
+				credentials: &Credentials{
+					Username: "robot",
+					Password: "password",
+				},
+				returnsError: false,
+			},
+		},
+		{
+			description: "docker config without registry credentials",
+			in: caseInput{
+				repo: "oci://registry.my-org.com/charts",
+				mockCalls: func() {
+					k8sClient.On("ListTemplateAuthRules").Return(tars, nil)
+					k8sClient.On("GetTemplateAuthRuleSecret", "registry", ".dockerconfigjson").
+						Return(`{"auths":{"docker.io":{"auth":"b3RoZXI6b3RoZXI="}}}`, nil)
+				},
+			},
+			out: caseOutput{
+				credentials:  nil,
+				returnsError: true,
+			},
+		},
 	}
 
 	for _, t := range testCases {
@@ -187,3 +260,116 @@ var _ = Describe("Templates resolver", func() {
 		})
 	}
 })
+
+var _ = Describe("Templates resolver auth types", func() {
+	var templatesResolver TemplatesResolver
+	var k8sClient *mocks.IKubernetesClient
+	var privateKey string
+
+	BeforeEach(func() {
+		k8sClient = &mocks.IKubernetesClient{}
+		templatesResolver = NewTemplatesResolver(k8sClient)
+
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		privateKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	})
+
+	It("returns ssh credentials verifying known hosts", func() {
+		k8sClient.On("ListTemplateAuthRules").Return([]v1alpha1.TemplateAuthRule{
+			{
+				Spec: v1alpha1.TemplateAuthRuleSpec{
+					Repo: "git@github.com:my-org",
+					Type: v1alpha1.TemplateAuthTypeSSH,
+					SSH: &v1alpha1.SSHAuth{
+						PrivateKey: apiv1.SecretKeySelector{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: "deploy-key"},
+							Key:                  "ssh-privatekey",
+						},
+						KnownHosts: apiv1.SecretKeySelector{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: "deploy-key"},
+							Key:                  "known_hosts",
+						},
+					},
+				},
+			},
+		}, nil)
+		k8sClient.On("GetTemplateAuthRuleSecret", "deploy-key", "ssh-privatekey").Return(privateKey, nil)
+		k8sClient.On("GetTemplateAuthRuleSecret", "deploy-key", "known_hosts").
+			Return("github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl", nil)
+
+		creds, err := templatesResolver.RepoAuthCredentials("git@github.com:my-org/templates.git")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds.SSH).ToNot(BeNil())
+		Expect(creds.SSH.User).To(BeEquivalentTo("git"))
+		Expect(creds.SSH.HostKeyCallback).ToNot(BeNil())
+		Expect(creds.GitAuth()).To(BeIdenticalTo(creds.SSH))
+	})
+
+	It("returns GitHub App installation tokens", func() {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			Expect(r.Method).To(BeEquivalentTo(http.MethodPost))
+			Expect(r.URL.Path).To(BeEquivalentTo("/app/installations/42/access_tokens"))
+			Expect(r.Header.Get("Authorization")).To(HavePrefix("Bearer "))
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token":"ghs_installation","expires_at":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`))
+		}))
+		defer server.Close()
+
+		k8sClient.On("ListTemplateAuthRules").Return([]v1alpha1.TemplateAuthRule{
+			{
+				Spec: v1alpha1.TemplateAuthRuleSpec{
+					Repo: "https://github.com/my-org",
+					Type: v1alpha1.TemplateAuthTypeGitHubApp,
+					GitHubApp: &v1alpha1.GitHubAppAuth{
+						AppID:          7,
+						InstallationID: 42,
+						PrivateKey: apiv1.SecretKeySelector{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: "github-app"},
+							Key:                  "private-key",
+						},
+						APIURL: server.URL,
+					},
+				},
+			},
+		}, nil)
+		k8sClient.On("GetTemplateAuthRuleSecret", "github-app", "private-key").Return(privateKey, nil)
+
+		for i := 0; i < 2; i++ {
+			creds, err := templatesResolver.RepoAuthCredentials("https://github.com/my-org/templates")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(creds).To(BeEquivalentTo(&Credentials{
+				Username: "x-access-token",
+				Password: "ghs_installation",
+			}))
+		}
+
+		Expect(requests).To(BeEquivalentTo(1))
+	})
+
+	It("returns verification keys of the rule", func() {
+		k8sClient.On("ListTemplateAuthRules").Return([]v1alpha1.TemplateAuthRule{
+			{
+				Spec: v1alpha1.TemplateAuthRuleSpec{
+					Repo: "https://github.com/my-org",
+					Verification: &v1alpha1.TemplateVerification{
+						PublicKeys: apiv1.SecretKeySelector{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: "signing-keys"},
+							Key:                  "keys",
+						},
+					},
+				},
+			},
+		}, nil)
+		k8sClient.On("GetTemplateAuthRuleSecret", "signing-keys", "keys").
+			Return("ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl", nil)
+
+		creds, err := templatesResolver.RepoAuthCredentials("https://github.com/my-org/templates")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds.Verifies()).To(BeTrue())
+		Expect(creds.GitAuth()).To(BeNil())
+	})
+})
@@ -0,0 +1,86 @@
+package k8sclient
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// impersonatedClientsLimit caps the number of cached impersonating clients
+const impersonatedClientsLimit = 100
+
+// TokenReview authenticates a bearer token against the Kubernetes API server
+func (k *KubernetesClient) TokenReview(token string) (*authenticationv1.UserInfo, bool, error) {
+	review, err := k.clientset.AuthenticationV1().TokenReviews().Create(context.Background(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !review.Status.Authenticated {
+		return nil, false, nil
+	}
+
+	return &review.Status.User, true, nil
+}
+
+// SubjectAccessReview checks if the user is allowed to call the non-resource
+// path with the given verb
+func (k *KubernetesClient) SubjectAccessReview(user string, groups []string, path, verb string) (bool, string, error) {
+	review, err := k.clientset.AuthorizationV1().SubjectAccessReviews().Create(context.Background(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user,
+			Groups: groups,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: path,
+				Verb: verb,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", err
+	}
+
+	return review.Status.Allowed, review.Status.Reason, nil
+}
+
+// Impersonate returns a client with the same configuration that makes all
+// requests to the API server as the given user
+func (k *KubernetesClient) Impersonate(user string, groups []string) (IKubernetesClient, error) {
+	sortedGroups := append([]string{}, groups...)
+	sort.Strings(sortedGroups)
+	key := user + "\x00" + strings.Join(sortedGroups, ",")
+
+	k.impersonatedLock.Lock()
+	defer k.impersonatedLock.Unlock()
+
+	if c, ok := k.impersonated[key]; ok {
+		return c, nil
+	}
+
+	config := rest.CopyConfig(k.config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: user,
+		Groups:   groups,
+	}
+
+	c, err := newKubernetesClient(config, k.clientConfig, k.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(k.impersonated) >= impersonatedClientsLimit {
+		k.impersonated = make(map[string]*KubernetesClient)
+	}
+	k.impersonated[key] = c
+
+	return c, nil
+}
@@ -3,12 +3,14 @@ package k8sclient
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/remotecommand"
 
 	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -36,6 +38,12 @@ type KubernetesClient struct {
 	helmReleaseNamespace  string
 	moduleTargetNamespace string
 
+	fieldManager string
+
+	clientConfig     ClientConfig
+	impersonated     map[string]*KubernetesClient
+	impersonatedLock sync.Mutex
+
 	logger logr.Logger
 }
 
@@ -46,8 +54,13 @@ type ClientConfig struct {
 	ModuleNamespace       string
 	HelmReleaseNamespace  string
 	ModuleTargetNamespace string
+
+	// FieldManager is used for server-side apply of Module child resources
+	FieldManager string
 }
 
+const defaultFieldManager = "cyclops-ctrl"
+
 func NewWithConfig(config ClientConfig, logger logr.Logger) (*KubernetesClient, error) {
 	var k8sConfig *rest.Config
 	var err error
@@ -79,6 +92,10 @@ func NewWithConfig(config ClientConfig, logger logr.Logger) (*KubernetesClient,
 		}
 	}
 
+	return newKubernetesClient(k8sConfig, config, logger)
+}
+
+func newKubernetesClient(k8sConfig *rest.Config, config ClientConfig, logger logr.Logger) (*KubernetesClient, error) {
 	clientset, err := kubernetes.NewForConfig(k8sConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
@@ -99,6 +116,11 @@ func NewWithConfig(config ClientConfig, logger logr.Logger) (*KubernetesClient,
 		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
+	fieldManager := config.FieldManager
+	if len(fieldManager) == 0 {
+		fieldManager = defaultFieldManager
+	}
+
 	return &KubernetesClient{
 		config:                k8sConfig,
 		Dynamic:               dynamic,
@@ -108,6 +130,9 @@ func NewWithConfig(config ClientConfig, logger logr.Logger) (*KubernetesClient,
 		moduleNamespace:       config.ModuleNamespace,
 		helmReleaseNamespace:  config.HelmReleaseNamespace,
 		moduleTargetNamespace: config.ModuleTargetNamespace,
+		fieldManager:          fieldManager,
+		clientConfig:          config,
+		impersonated:          make(map[string]*KubernetesClient),
 		logger:                logger,
 	}, nil
 }
@@ -137,7 +162,12 @@ type IKubernetesClient interface {
 	UpdateModuleStatus(module *cyclopsv1alpha1.Module) (*cyclopsv1alpha1.Module, error)
 	DeleteModule(name string) error
 	GetModule(name string) (*cyclopsv1alpha1.Module, error)
+	CreateModuleEvent(moduleName, eventType, reason, message string) error
+	ListModuleRevisions(moduleName string) ([]cyclopsv1alpha1.ModuleRevision, error)
+	CreateModuleRevision(revision *cyclopsv1alpha1.ModuleRevision) error
+	DeleteModuleRevision(name string) error
 	GetResourcesForModule(name string) ([]*dto.Resource, error)
+	ListManagedResources(moduleName string, gvrs []cyclopsv1alpha1.GroupVersionResource) ([]unstructured.Unstructured, error)
 	MapUnstructuredResource(u unstructured.Unstructured) (*dto.Resource, error)
 	GetWorkloadsForModule(name string) ([]*dto.Resource, error)
 	GetDeletedResources([]*dto.Resource, string, string) ([]*dto.Resource, error)
@@ -151,7 +181,8 @@ type IKubernetesClient interface {
 	Restart(group, version, kind, name, namespace string) error
 	GetResource(group, version, kind, name, namespace string) (any, error)
 	Delete(resource *dto.Resource) error
-	CreateDynamic(cyclopsv1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error
+	CreateDynamic(cyclopsv1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) error
+	DryRunApply(cyclopsv1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) (*unstructured.Unstructured, *unstructured.Unstructured, error)
 	ApplyCRD(obj *unstructured.Unstructured) error
 	ListNodes() ([]apiv1.Node, error)
 	GetNode(name string) (*apiv1.Node, error)
@@ -161,6 +192,10 @@ type IKubernetesClient interface {
 	WatchKubernetesResources(gvrs []ResourceWatchSpec, stopCh chan struct{}) (chan *unstructured.Unstructured, error)
 	ListTemplateAuthRules() ([]cyclopsv1alpha1.TemplateAuthRule, error)
 	GetTemplateAuthRuleSecret(name, key string) (string, error)
+	GetValuesSource(kind, name string) (map[string]string, error)
+	TokenReview(token string) (*authenticationv1.UserInfo, bool, error)
+	SubjectAccessReview(user string, groups []string, path, verb string) (bool, string, error)
+	Impersonate(user string, groups []string) (IKubernetesClient, error)
 	ListTemplateStore() ([]cyclopsv1alpha1.TemplateStore, error)
 	GetTemplateStore(name string) (*cyclopsv1alpha1.TemplateStore, error)
 	CreateTemplateStore(ts *cyclopsv1alpha1.TemplateStore) error
@@ -0,0 +1,88 @@
+package k8sclient
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var conflictManagerPattern = regexp.MustCompile(`conflict with "(.+?)"`)
+
+// FieldConflict is a single field that could not be applied because it is
+// owned by another field manager
+type FieldConflict struct {
+	Manager string
+	Field   string
+}
+
+// ApplyConflictError is returned from server-side apply when fields of the
+// applied object are owned by other field managers and the apply was not forced
+type ApplyConflictError struct {
+	Conflicts []FieldConflict
+
+	err error
+}
+
+func (e *ApplyConflictError) Error() string {
+	conflicts := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		conflicts = append(conflicts, fmt.Sprintf("%v managed by %v", c.Field, c.Manager))
+	}
+
+	return fmt.Sprintf("apply conflicts: %v", strings.Join(conflicts, ", "))
+}
+
+func (e *ApplyConflictError) Unwrap() error {
+	return e.err
+}
+
+func (e *ApplyConflictError) hasManager(manager string) bool {
+	for _, c := range e.Conflicts {
+		if c.Manager == manager {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mapApplyError(err error) error {
+	if err == nil || !k8serrors.IsConflict(err) {
+		return err
+	}
+
+	var statusErr k8serrors.APIStatus
+	if !errors.As(err, &statusErr) || statusErr.Status().Details == nil {
+		return err
+	}
+
+	conflicts := make([]FieldConflict, 0)
+	for _, cause := range statusErr.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+
+		manager := cause.Message
+		if match := conflictManagerPattern.FindStringSubmatch(cause.Message); len(match) > 1 {
+			manager = match[1]
+		}
+
+		conflicts = append(conflicts, FieldConflict{
+			Manager: manager,
+			Field:   cause.Field,
+		})
+	}
+
+	if len(conflicts) == 0 {
+		return err
+	}
+
+	return &ApplyConflictError{
+		Conflicts: conflicts,
+		err:       err,
+	}
+}
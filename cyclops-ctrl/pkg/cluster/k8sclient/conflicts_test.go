@@ -0,0 +1,72 @@
+package k8sclient
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("mapApplyError", func() {
+	conflict := func(causes ...metav1.StatusCause) error {
+		err := k8serrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web", errors.New("apply failed"))
+		err.ErrStatus.Details.Causes = causes
+		return err
+	}
+
+	It("returns nil without an error", func() {
+		Expect(mapApplyError(nil)).To(BeNil())
+	})
+
+	It("keeps errors that are not conflicts", func() {
+		err := k8serrors.NewBadRequest("invalid object")
+		Expect(mapApplyError(err)).To(Equal(err))
+	})
+
+	It("keeps conflicts without field manager causes", func() {
+		err := conflict(metav1.StatusCause{Type: metav1.CauseTypeFieldValueInvalid, Field: ".spec.replicas"})
+		Expect(mapApplyError(err)).To(Equal(err))
+	})
+
+	It("maps field manager conflicts", func() {
+		err := conflict(
+			metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "kubectl-edit" using apps/v1`,
+				Field:   ".spec.replicas",
+			},
+			metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "hpa-controller": .spec.template`,
+				Field:   ".spec.template.spec.containers[name=\"web\"].image",
+			},
+			metav1.StatusCause{Type: metav1.CauseTypeFieldValueInvalid, Field: ".metadata.name"},
+		)
+
+		mapped := mapApplyError(err)
+
+		var conflictErr *ApplyConflictError
+		Expect(errors.As(mapped, &conflictErr)).To(BeTrue())
+		Expect(conflictErr.Conflicts).To(Equal([]FieldConflict{
+			{Manager: "kubectl-edit", Field: ".spec.replicas"},
+			{Manager: "hpa-controller", Field: ".spec.template.spec.containers[name=\"web\"].image"},
+		}))
+		Expect(k8serrors.IsConflict(mapped)).To(BeTrue())
+		Expect(mapped.Error()).To(Equal(`apply conflicts: .spec.replicas managed by kubectl-edit, .spec.template.spec.containers[name="web"].image managed by hpa-controller`))
+	})
+
+	It("uses the cause message when the manager can not be parsed", func() {
+		mapped := mapApplyError(conflict(metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: "conflicting field manager",
+			Field:   ".data.key",
+		}))
+
+		var conflictErr *ApplyConflictError
+		Expect(errors.As(mapped, &conflictErr)).To(BeTrue())
+		Expect(conflictErr.Conflicts).To(Equal([]FieldConflict{{Manager: "conflicting field manager", Field: ".data.key"}}))
+	})
+})
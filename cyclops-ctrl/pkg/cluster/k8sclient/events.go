@@ -0,0 +1,53 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+const (
+	EventTypeNormal  = apiv1.EventTypeNormal
+	EventTypeWarning = apiv1.EventTypeWarning
+
+	eventSourceComponent = "cyclops-ctrl"
+)
+
+// CreateModuleEvent records a Kubernetes Event on the Module
+func (k *KubernetesClient) CreateModuleEvent(moduleName, eventType, reason, message string) error {
+	module, err := k.GetModule(moduleName)
+	if err != nil {
+		return err
+	}
+
+	now := metav1.NewTime(time.Now())
+
+	_, err = k.clientset.CoreV1().Events(k.moduleNamespace).Create(context.Background(), &apiv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v.%x", module.Name, now.UnixNano()),
+			Namespace: k.moduleNamespace,
+		},
+		InvolvedObject: apiv1.ObjectReference{
+			APIVersion:      cyclopsv1alpha1.GroupVersion.String(),
+			Kind:            "Module",
+			Name:            module.Name,
+			Namespace:       module.Namespace,
+			UID:             module.UID,
+			ResourceVersion: module.ResourceVersion,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         apiv1.EventSource{Component: eventSourceComponent},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}, metav1.CreateOptions{})
+
+	return err
+}
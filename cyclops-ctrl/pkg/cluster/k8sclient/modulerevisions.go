@@ -0,0 +1,23 @@
+package k8sclient
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+func (k *KubernetesClient) ListModuleRevisions(moduleName string) ([]cyclopsv1alpha1.ModuleRevision, error) {
+	return k.moduleset.ModuleRevisions(k.moduleNamespace).List(metav1.ListOptions{
+		LabelSelector: labels.Set{cyclopsv1alpha1.ModuleRevisionModuleLabel: moduleName}.String(),
+	})
+}
+
+func (k *KubernetesClient) CreateModuleRevision(revision *cyclopsv1alpha1.ModuleRevision) error {
+	_, err := k.moduleset.ModuleRevisions(k.moduleNamespace).Create(revision)
+	return err
+}
+
+func (k *KubernetesClient) DeleteModuleRevision(name string) error {
+	return k.moduleset.ModuleRevisions(k.moduleNamespace).Delete(name)
+}
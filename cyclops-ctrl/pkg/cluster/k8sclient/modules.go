@@ -30,6 +30,14 @@ const (
 	statusProgressing = "progressing"
 )
 
+// Module resources health as returned from GetModuleResourcesHealth
+const (
+	HealthUnknown     = statusUnknown
+	HealthHealthy     = statusHealthy
+	HealthUnhealthy   = statusUnhealthy
+	HealthProgressing = statusProgressing
+)
+
 func (k *KubernetesClient) ListModules() ([]cyclopsv1alpha1.Module, error) {
 	moduleList, err := k.moduleset.Modules(k.moduleNamespace).List(metav1.ListOptions{})
 
@@ -113,6 +121,36 @@ func (k *KubernetesClient) GetResourcesForModule(name string) ([]*dto.Resource,
 	return out, nil
 }
 
+func (k *KubernetesClient) ListManagedResources(
+	moduleName string,
+	gvrs []cyclopsv1alpha1.GroupVersionResource,
+) ([]unstructured.Unstructured, error) {
+	out := make([]unstructured.Unstructured, 0)
+
+	for _, r := range gvrs {
+		gvr := schema.GroupVersionResource{
+			Group:    r.Group,
+			Version:  r.Version,
+			Resource: r.Resource,
+		}
+
+		rs, err := k.Dynamic.Resource(gvr).Namespace(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+			LabelSelector: "cyclops.module=" + moduleName,
+		})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		out = append(out, rs.Items...)
+	}
+
+	return out, nil
+}
+
 func (k *KubernetesClient) MapUnstructuredResource(u unstructured.Unstructured) (*dto.Resource, error) {
 	status, err := k.getResourceStatus(u)
 	if err != nil {
@@ -0,0 +1,30 @@
+package k8sclient
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+const helmResourcePolicyAnnotation = "helm.sh/resource-policy"
+
+// PruneEnabled reports whether resources no longer rendered from the Module
+// template are deleted on reconciliation
+func PruneEnabled(module cyclopsv1alpha1.Module) bool {
+	return module.GetAnnotations()[cyclopsv1alpha1.PruneAnnotation] != "false"
+}
+
+// Prunable reports whether a Module child resource can be deleted once it is no
+// longer rendered. Resources marked to be kept, owned by another controller or
+// already being deleted are left alone.
+func Prunable(obj unstructured.Unstructured) bool {
+	annotations := obj.GetAnnotations()
+
+	if annotations[cyclopsv1alpha1.ResourcePolicyAnnotation] == cyclopsv1alpha1.ResourcePolicyKeep ||
+		annotations[helmResourcePolicyAnnotation] == cyclopsv1alpha1.ResourcePolicyKeep {
+		return false
+	}
+
+	return metav1.GetControllerOf(&obj) == nil && obj.GetDeletionTimestamp() == nil
+}
@@ -16,10 +16,15 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/csaupgrade"
 
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
@@ -176,182 +181,193 @@ func (k *KubernetesClient) CreateDynamic(
 	resource v1alpha1.GroupVersionResource,
 	obj *unstructured.Unstructured,
 	targetNamespace string,
+	forceConflicts bool,
 ) error {
-	gvr := schema.GroupVersionResource{
-		Group:    resource.Group,
-		Version:  resource.Version,
-		Resource: resource.Resource,
-	}
-
-	objNamespace := apiv1.NamespaceDefault
-
-	if len(strings.TrimSpace(targetNamespace)) != 0 {
-		objNamespace = strings.TrimSpace(targetNamespace)
-	}
-
-	if len(strings.TrimSpace(obj.GetNamespace())) != 0 {
-		objNamespace = obj.GetNamespace()
-	}
-	obj.SetNamespace(objNamespace)
-
-	isNamespaced, err := k.isResourceNamespaced(obj.GroupVersionKind())
+	resourceClient, err := k.resourceClientFor(resource, obj, targetNamespace)
 	if err != nil {
 		return err
 	}
 
-	if !isNamespaced {
-		return k.createDynamicNonNamespaced(gvr, obj)
-	}
-
-	return k.createDynamicNamespaced(gvr, objNamespace, obj)
-}
-
-func (k *KubernetesClient) createDynamicNamespaced(
-	gvr schema.GroupVersionResource,
-	namespace string,
-	obj *unstructured.Unstructured,
-) error {
-	current, err := k.Dynamic.Resource(gvr).Namespace(namespace).Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	applied, err := k.apply(resourceClient, obj, forceConflicts)
 	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			_, err := k.Dynamic.Resource(gvr).Namespace(namespace).Create(
-				context.Background(),
-				obj,
-				metav1.CreateOptions{},
-			)
-
+		var conflictErr *ApplyConflictError
+		if !errors.As(err, &conflictErr) || !conflictErr.hasManager(legacyFieldManager()) {
 			return err
 		}
-		return err
-	}
 
-	if isJob(obj.GroupVersionKind().Group, obj.GroupVersionKind().Version, obj.GroupVersionKind().Kind) {
-		if err := copyJobSelectors(current, obj); err != nil {
+		// fields set by an older cyclops version conflict with the apply, so
+		// they are moved to the apply field manager and applied again
+		current, err := resourceClient.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+		if err != nil {
 			return err
 		}
-	}
 
-	if isPersistentVolumeClaims(obj.GroupVersionKind().Group, obj.GroupVersionKind().Version, obj.GroupVersionKind().Kind) {
-		if err := mergePVCWithCurrent(current, obj); err != nil {
+		if err := k.upgradeManagedFields(resourceClient, current); err != nil {
 			return err
 		}
+
+		_, err = k.apply(resourceClient, obj, forceConflicts)
+		return err
 	}
 
-	obj.SetResourceVersion(current.GetResourceVersion())
-	obj.SetAnnotations(mergeAnnotations(current.GetAnnotations(), obj.GetAnnotations()))
-	obj.SetFinalizers(current.GetFinalizers())
+	if hasFieldManager(applied, legacyFieldManager()) {
+		return k.upgradeManagedFields(resourceClient, applied)
+	}
+
+	return nil
+}
 
-	_, err = k.Dynamic.Resource(gvr).Namespace(namespace).Update(
+func (k *KubernetesClient) apply(
+	resourceClient dynamic.ResourceInterface,
+	obj *unstructured.Unstructured,
+	forceConflicts bool,
+) (*unstructured.Unstructured, error) {
+	applied, err := resourceClient.Apply(
 		context.Background(),
+		obj.GetName(),
 		obj,
-		metav1.UpdateOptions{},
+		metav1.ApplyOptions{
+			FieldManager: k.fieldManager,
+			Force:        forceConflicts,
+		},
 	)
 
-	return err
+	return applied, mapApplyError(err)
 }
 
-func (k *KubernetesClient) createDynamicNonNamespaced(
-	gvr schema.GroupVersionResource,
+// DryRunApply server-side applies the object without persisting it. It returns
+// the object currently in the cluster, or nil if it does not exist yet, and the
+// object as it would be stored by the API server after the apply.
+func (k *KubernetesClient) DryRunApply(
+	resource v1alpha1.GroupVersionResource,
 	obj *unstructured.Unstructured,
-) error {
-	current, err := k.Dynamic.Resource(gvr).Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	targetNamespace string,
+	forceConflicts bool,
+) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	resourceClient, err := k.resourceClientFor(resource, obj, targetNamespace)
 	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			_, err := k.Dynamic.Resource(gvr).Create(
-				context.Background(),
-				obj,
-				metav1.CreateOptions{},
-			)
+		return nil, nil, err
+	}
 
-			return err
+	current, err := resourceClient.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return nil, nil, err
 		}
-		return err
+		current = nil
 	}
 
-	obj.SetResourceVersion(current.GetResourceVersion())
-	obj.SetAnnotations(mergeAnnotations(current.GetAnnotations(), obj.GetAnnotations()))
-	obj.SetFinalizers(current.GetFinalizers())
-
-	_, err = k.Dynamic.Resource(gvr).Update(
+	proposed, err := resourceClient.Apply(
 		context.Background(),
+		obj.GetName(),
 		obj,
-		metav1.UpdateOptions{},
+		metav1.ApplyOptions{
+			FieldManager: k.fieldManager,
+			Force:        forceConflicts,
+			DryRun:       []string{metav1.DryRunAll},
+		},
 	)
+	if err != nil {
+		return nil, nil, mapApplyError(err)
+	}
 
-	return err
+	return current, proposed, nil
 }
 
-func (k *KubernetesClient) ApplyCRD(obj *unstructured.Unstructured) error {
+// resourceClientFor sets the namespace the object is applied to and returns a
+// dynamic client for its resource, scoped to that namespace if the resource is
+// namespaced
+func (k *KubernetesClient) resourceClientFor(
+	resource v1alpha1.GroupVersionResource,
+	obj *unstructured.Unstructured,
+	targetNamespace string,
+) (dynamic.ResourceInterface, error) {
 	gvr := schema.GroupVersionResource{
-		Group:    "apiextensions.k8s.io",
-		Version:  "v1",
-		Resource: "customresourcedefinitions",
+		Group:    resource.Group,
+		Version:  resource.Version,
+		Resource: resource.Resource,
 	}
 
-	_, err := k.Dynamic.Resource(gvr).Apply(
-		context.Background(),
-		obj.GetName(),
-		obj,
-		metav1.ApplyOptions{
-			FieldManager: "cyclops-ctrl",
-		},
-	)
-
-	return err
-}
+	objNamespace := apiv1.NamespaceDefault
 
-func copyJobSelectors(source, destination *unstructured.Unstructured) error {
-	selectors, ok, err := unstructured.NestedMap(source.Object, "spec", "selector")
-	if err != nil {
-		return err
+	if len(strings.TrimSpace(targetNamespace)) != 0 {
+		objNamespace = strings.TrimSpace(targetNamespace)
 	}
-	if !ok {
-		return errors.New(fmt.Sprintf("job %v selectors not found", source.GetName()))
+
+	if len(strings.TrimSpace(obj.GetNamespace())) != 0 {
+		objNamespace = obj.GetNamespace()
 	}
+	obj.SetNamespace(objNamespace)
 
-	templateLabels, ok, err := unstructured.NestedMap(source.Object, "spec", "template", "metadata", "labels")
+	isNamespaced, err := k.isResourceNamespaced(obj.GroupVersionKind())
 	if err != nil {
-		return err
-	}
-	if !ok {
-		return errors.New(fmt.Sprintf("job %v selectors not found", source.GetName()))
+		return nil, err
 	}
 
-	if err := unstructured.SetNestedMap(destination.Object, selectors, "spec", "selector"); err != nil {
-		return err
+	if !isNamespaced {
+		obj.SetNamespace("")
+		return k.Dynamic.Resource(gvr), nil
 	}
 
-	return unstructured.SetNestedMap(destination.Object, templateLabels, "spec", "template", "metadata", "labels")
+	return k.Dynamic.Resource(gvr).Namespace(objNamespace), nil
 }
 
-func mergePVCWithCurrent(current, obj *unstructured.Unstructured) error {
-	requests, ok, err := unstructured.NestedMap(obj.Object, "spec", "resources", "requests")
+// upgradeManagedFields migrates fields previously owned by cyclops through
+// Get-then-Update calls to the server-side apply field manager. It is called
+// only while the legacy manager still owns fields of the object, so each
+// object is migrated once.
+func (k *KubernetesClient) upgradeManagedFields(resourceClient dynamic.ResourceInterface, current *unstructured.Unstructured) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(current, sets.New(legacyFieldManager()), k.fieldManager)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return fmt.Errorf("PVC %v spec.resources.requests not found", obj.GetName())
-	}
 
-	for key, value := range current.Object {
-		obj.Object[key] = value
+	if patch == nil {
+		return nil
 	}
 
-	return unstructured.SetNestedMap(current.Object, requests, "spec", "resources", "requests")
+	_, err = resourceClient.Patch(context.Background(), current.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{})
+	return err
 }
 
-func mergeAnnotations(existing, new map[string]string) map[string]string {
-	out := make(map[string]string)
+func hasFieldManager(obj *unstructured.Unstructured, manager string) bool {
+	if obj == nil {
+		return false
+	}
 
-	for k, v := range existing {
-		out[k] = v
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == manager && entry.Operation == metav1.ManagedFieldsOperationUpdate {
+			return true
+		}
 	}
 
-	for k, v := range new {
-		out[k] = v
+	return false
+}
+
+func (k *KubernetesClient) ApplyCRD(obj *unstructured.Unstructured) error {
+	gvr := schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
 	}
 
-	return out
+	_, err := k.Dynamic.Resource(gvr).Apply(
+		context.Background(),
+		obj.GetName(),
+		obj,
+		metav1.ApplyOptions{
+			FieldManager: "cyclops-ctrl",
+		},
+	)
+
+	return err
+}
+
+// legacyFieldManager returns the name of the field manager the API server
+// assigned to updates made without an explicit field manager, which is the
+// binary name taken from the default user agent
+func legacyFieldManager() string {
+	return strings.Split(rest.DefaultKubernetesUserAgent(), "/")[0]
 }
 
 func (k *KubernetesClient) ListNodes() ([]apiv1.Node, error) {
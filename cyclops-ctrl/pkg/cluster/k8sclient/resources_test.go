@@ -0,0 +1,214 @@
+package k8sclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	json "github.com/json-iterator/go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+var _ = Describe("CreateDynamic", func() {
+	const configMapPath = "/api/v1/namespaces/default/configmaps/web"
+
+	type request struct {
+		method      string
+		contentType string
+		query       map[string][]string
+	}
+
+	var server *httptest.Server
+	var k *KubernetesClient
+
+	var lock sync.Mutex
+	var requests []request
+	var applyResponses []func(w http.ResponseWriter)
+
+	configMap := func(managers ...metav1.ManagedFieldsEntry) map[string]interface{} {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace("default")
+		obj.SetName("web")
+		obj.SetManagedFields(managers)
+		Expect(unstructured.SetNestedField(obj.Object, "3", "data", "replicas")).To(Succeed())
+		return obj.Object
+	}
+
+	legacyManager := metav1.ManagedFieldsEntry{
+		Manager:    legacyFieldManager(),
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:replicas":{}}}`)},
+	}
+
+	applyManager := metav1.ManagedFieldsEntry{
+		Manager:    "cyclops-ctrl",
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:replicas":{}}}`)},
+	}
+
+	respond := func(obj interface{}) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			_ = json.NewEncoder(w).Encode(obj)
+		}
+	}
+
+	conflictWith := func(manager string) func(w http.ResponseWriter) {
+		err := k8serrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "web", errors.New("apply failed"))
+		err.ErrStatus.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Status"}
+		err.ErrStatus.Details.Causes = []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "` + manager + `" using v1`,
+			Field:   ".data.replicas",
+		}}
+
+		return func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(err.Status())
+		}
+	}
+
+	BeforeEach(func() {
+		requests = nil
+		applyResponses = nil
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.ReadAll(r.Body)
+
+			switch r.URL.Path {
+			case "/api":
+				respond(metav1.APIVersions{Versions: []string{"v1"}})(w)
+			case "/apis":
+				respond(metav1.APIGroupList{})(w)
+			case "/api/v1":
+				respond(metav1.APIResourceList{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"}},
+				})(w)
+			case configMapPath:
+				requests = append(requests, request{
+					method:      r.Method,
+					contentType: r.Header.Get("Content-Type"),
+					query:       r.URL.Query(),
+				})
+
+				if r.Header.Get("Content-Type") == string(types.ApplyPatchType) {
+					next := applyResponses[0]
+					applyResponses = applyResponses[1:]
+					next(w)
+					return
+				}
+
+				respond(configMap(legacyManager))(w)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		config := &rest.Config{Host: server.URL}
+
+		dynamicClient, err := dynamic.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		k = &KubernetesClient{Dynamic: dynamicClient, discovery: discoveryClient, fieldManager: "cyclops-ctrl"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	apply := func(forceConflicts bool) error {
+		obj := &unstructured.Unstructured{Object: configMap()}
+		return k.CreateDynamic(
+			cyclopsv1alpha1.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+			obj,
+			"",
+			forceConflicts,
+		)
+	}
+
+	It("applies with the field manager", func() {
+		applyResponses = append(applyResponses, respond(configMap(applyManager)))
+
+		Expect(apply(false)).To(Succeed())
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].contentType).To(Equal(string(types.ApplyPatchType)))
+		Expect(requests[0].query["fieldManager"]).To(Equal([]string{"cyclops-ctrl"}))
+		Expect(requests[0].query["force"]).To(Equal([]string{"false"}))
+	})
+
+	It("forces conflicts when requested", func() {
+		applyResponses = append(applyResponses, respond(configMap(applyManager)))
+
+		Expect(apply(true)).To(Succeed())
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].query["force"]).To(Equal([]string{"true"}))
+	})
+
+	It("migrates fields the legacy manager still owns after the apply", func() {
+		applyResponses = append(applyResponses, respond(configMap(applyManager, legacyManager)))
+
+		Expect(apply(false)).To(Succeed())
+
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[1].method).To(Equal(http.MethodPatch))
+		Expect(requests[1].contentType).To(Equal(string(types.JSONPatchType)))
+	})
+
+	It("migrates fields of the legacy manager and applies again on conflicts with it", func() {
+		applyResponses = append(applyResponses,
+			conflictWith(legacyFieldManager()),
+			respond(configMap(applyManager)),
+		)
+
+		Expect(apply(false)).To(Succeed())
+
+		methods := make([]string, 0, len(requests))
+		for _, r := range requests {
+			methods = append(methods, r.method+" "+r.contentType)
+		}
+		Expect(methods).To(Equal([]string{
+			http.MethodPatch + " " + string(types.ApplyPatchType),
+			http.MethodGet + " ",
+			http.MethodPatch + " " + string(types.JSONPatchType),
+			http.MethodPatch + " " + string(types.ApplyPatchType),
+		}))
+	})
+
+	It("returns conflicts with other field managers", func() {
+		applyResponses = append(applyResponses, conflictWith("kubectl-edit"))
+
+		err := apply(false)
+
+		var conflictErr *ApplyConflictError
+		Expect(errors.As(err, &conflictErr)).To(BeTrue())
+		Expect(conflictErr.Conflicts).To(Equal([]FieldConflict{{Manager: "kubectl-edit", Field: ".data.replicas"}}))
+		Expect(requests).To(HaveLen(1))
+	})
+})
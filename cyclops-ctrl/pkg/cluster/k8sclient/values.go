@@ -0,0 +1,37 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// GetValuesSource returns the data of a Secret or ConfigMap in the Module namespace
+func (k *KubernetesClient) GetValuesSource(kind, name string) (map[string]string, error) {
+	switch kind {
+	case cyclopsv1alpha1.ValuesFromKindSecret:
+		secret, err := k.clientset.CoreV1().Secrets(k.moduleNamespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		data := make(map[string]string, len(secret.Data))
+		for key, value := range secret.Data {
+			data[key] = string(value)
+		}
+
+		return data, nil
+	case cyclopsv1alpha1.ValuesFromKindConfigMap:
+		configMap, err := k.clientset.CoreV1().ConfigMaps(k.moduleNamespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return configMap.Data, nil
+	}
+
+	return nil, fmt.Errorf("unsupported values source kind %v", kind)
+}
@@ -0,0 +1,81 @@
+package k8sclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	json "github.com/json-iterator/go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+var _ = Describe("GetValuesSource", func() {
+	var server *httptest.Server
+	var k *KubernetesClient
+
+	BeforeEach(func() {
+		objects := map[string]interface{}{
+			"/api/v1/namespaces/cyclops/secrets/overrides": corev1.Secret{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+				ObjectMeta: metav1.ObjectMeta{Name: "overrides", Namespace: "cyclops"},
+				Data:       map[string][]byte{"values.yaml": []byte("replicas: 3\n")},
+			},
+			"/api/v1/namespaces/cyclops/configmaps/defaults": corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "cyclops"},
+				Data:       map[string]string{"LOG_LEVEL": "debug"},
+			},
+		}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+
+			obj, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(apierrors.NewNotFound(corev1.Resource("secrets"), "missing").Status())
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(obj)
+		}))
+
+		clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+
+		k = &KubernetesClient{clientset: clientset, moduleNamespace: "cyclops"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns decoded Secret data", func() {
+		data, err := k.GetValuesSource(cyclopsv1alpha1.ValuesFromKindSecret, "overrides")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(map[string]string{"values.yaml": "replicas: 3\n"}))
+	})
+
+	It("returns ConfigMap data", func() {
+		data, err := k.GetValuesSource(cyclopsv1alpha1.ValuesFromKindConfigMap, "defaults")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(map[string]string{"LOG_LEVEL": "debug"}))
+	})
+
+	It("returns not found errors for missing sources", func() {
+		_, err := k.GetValuesSource(cyclopsv1alpha1.ValuesFromKindSecret, "missing")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("rejects unsupported kinds", func() {
+		_, err := k.GetValuesSource("Deployment", "web")
+		Expect(err).To(MatchError("unsupported values source kind Deployment"))
+	})
+})
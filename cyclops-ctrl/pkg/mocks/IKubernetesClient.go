@@ -5,7 +5,10 @@ package mocks
 import (
 	context "context"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
+
 	dto "github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/dto"
+
 	k8sclient "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
 
 	mock "github.com/stretchr/testify/mock"
@@ -144,17 +147,17 @@ func (_c *IKubernetesClient_CommandExecutor_Call) RunAndReturn(run func(string,
 	return _c
 }
 
-// CreateDynamic provides a mock function with given fields: _a0, _a1, _a2
-func (_m *IKubernetesClient) CreateDynamic(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string) error {
-	ret := _m.Called(_a0, _a1, _a2)
+// CreateDynamic provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IKubernetesClient) CreateDynamic(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string, _a3 bool) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateDynamic")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -171,13 +174,14 @@ type IKubernetesClient_CreateDynamic_Call struct {
 //   - _a0 v1alpha1.GroupVersionResource
 //   - _a1 *unstructured.Unstructured
 //   - _a2 string
-func (_e *IKubernetesClient_Expecter) CreateDynamic(_a0 interface{}, _a1 interface{}, _a2 interface{}) *IKubernetesClient_CreateDynamic_Call {
-	return &IKubernetesClient_CreateDynamic_Call{Call: _e.mock.On("CreateDynamic", _a0, _a1, _a2)}
+//   - _a3 bool
+func (_e *IKubernetesClient_Expecter) CreateDynamic(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *IKubernetesClient_CreateDynamic_Call {
+	return &IKubernetesClient_CreateDynamic_Call{Call: _e.mock.On("CreateDynamic", _a0, _a1, _a2, _a3)}
 }
 
-func (_c *IKubernetesClient_CreateDynamic_Call) Run(run func(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string)) *IKubernetesClient_CreateDynamic_Call {
+func (_c *IKubernetesClient_CreateDynamic_Call) Run(run func(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string, _a3 bool)) *IKubernetesClient_CreateDynamic_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(v1alpha1.GroupVersionResource), args[1].(*unstructured.Unstructured), args[2].(string))
+		run(args[0].(v1alpha1.GroupVersionResource), args[1].(*unstructured.Unstructured), args[2].(string), args[3].(bool))
 	})
 	return _c
 }
@@ -187,7 +191,7 @@ func (_c *IKubernetesClient_CreateDynamic_Call) Return(_a0 error) *IKubernetesCl
 	return _c
 }
 
-func (_c *IKubernetesClient_CreateDynamic_Call) RunAndReturn(run func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error) *IKubernetesClient_CreateDynamic_Call {
+func (_c *IKubernetesClient_CreateDynamic_Call) RunAndReturn(run func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) error) *IKubernetesClient_CreateDynamic_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -238,6 +242,101 @@ func (_c *IKubernetesClient_CreateModule_Call) RunAndReturn(run func(v1alpha1.Mo
 	return _c
 }
 
+// CreateModuleEvent provides a mock function with given fields: moduleName, eventType, reason, message
+func (_m *IKubernetesClient) CreateModuleEvent(moduleName string, eventType string, reason string, message string) error {
+	ret := _m.Called(moduleName, eventType, reason, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateModuleEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) error); ok {
+		r0 = rf(moduleName, eventType, reason, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IKubernetesClient_CreateModuleEvent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateModuleEvent'
+type IKubernetesClient_CreateModuleEvent_Call struct {
+	*mock.Call
+}
+
+// CreateModuleEvent is a helper method to define mock.On call
+//   - moduleName string
+//   - eventType string
+//   - reason string
+//   - message string
+func (_e *IKubernetesClient_Expecter) CreateModuleEvent(moduleName interface{}, eventType interface{}, reason interface{}, message interface{}) *IKubernetesClient_CreateModuleEvent_Call {
+	return &IKubernetesClient_CreateModuleEvent_Call{Call: _e.mock.On("CreateModuleEvent", moduleName, eventType, reason, message)}
+}
+
+func (_c *IKubernetesClient_CreateModuleEvent_Call) Run(run func(moduleName string, eventType string, reason string, message string)) *IKubernetesClient_CreateModuleEvent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_CreateModuleEvent_Call) Return(_a0 error) *IKubernetesClient_CreateModuleEvent_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IKubernetesClient_CreateModuleEvent_Call) RunAndReturn(run func(string, string, string, string) error) *IKubernetesClient_CreateModuleEvent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateModuleRevision provides a mock function with given fields: revision
+func (_m *IKubernetesClient) CreateModuleRevision(revision *v1alpha1.ModuleRevision) error {
+	ret := _m.Called(revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateModuleRevision")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*v1alpha1.ModuleRevision) error); ok {
+		r0 = rf(revision)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IKubernetesClient_CreateModuleRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateModuleRevision'
+type IKubernetesClient_CreateModuleRevision_Call struct {
+	*mock.Call
+}
+
+// CreateModuleRevision is a helper method to define mock.On call
+//   - revision *v1alpha1.ModuleRevision
+func (_e *IKubernetesClient_Expecter) CreateModuleRevision(revision interface{}) *IKubernetesClient_CreateModuleRevision_Call {
+	return &IKubernetesClient_CreateModuleRevision_Call{Call: _e.mock.On("CreateModuleRevision", revision)}
+}
+
+func (_c *IKubernetesClient_CreateModuleRevision_Call) Run(run func(revision *v1alpha1.ModuleRevision)) *IKubernetesClient_CreateModuleRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*v1alpha1.ModuleRevision))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_CreateModuleRevision_Call) Return(_a0 error) *IKubernetesClient_CreateModuleRevision_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IKubernetesClient_CreateModuleRevision_Call) RunAndReturn(run func(*v1alpha1.ModuleRevision) error) *IKubernetesClient_CreateModuleRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateTemplateStore provides a mock function with given fields: ts
 func (_m *IKubernetesClient) CreateTemplateStore(ts *v1alpha1.TemplateStore) error {
 	ret := _m.Called(ts)
@@ -376,6 +475,52 @@ func (_c *IKubernetesClient_DeleteModule_Call) RunAndReturn(run func(string) err
 	return _c
 }
 
+// DeleteModuleRevision provides a mock function with given fields: name
+func (_m *IKubernetesClient) DeleteModuleRevision(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteModuleRevision")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IKubernetesClient_DeleteModuleRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteModuleRevision'
+type IKubernetesClient_DeleteModuleRevision_Call struct {
+	*mock.Call
+}
+
+// DeleteModuleRevision is a helper method to define mock.On call
+//   - name string
+func (_e *IKubernetesClient_Expecter) DeleteModuleRevision(name interface{}) *IKubernetesClient_DeleteModuleRevision_Call {
+	return &IKubernetesClient_DeleteModuleRevision_Call{Call: _e.mock.On("DeleteModuleRevision", name)}
+}
+
+func (_c *IKubernetesClient_DeleteModuleRevision_Call) Run(run func(name string)) *IKubernetesClient_DeleteModuleRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_DeleteModuleRevision_Call) Return(_a0 error) *IKubernetesClient_DeleteModuleRevision_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IKubernetesClient_DeleteModuleRevision_Call) RunAndReturn(run func(string) error) *IKubernetesClient_DeleteModuleRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteReleaseSecret provides a mock function with given fields: releaseName, releaseNamespace
 func (_m *IKubernetesClient) DeleteReleaseSecret(releaseName string, releaseNamespace string) error {
 	ret := _m.Called(releaseName, releaseNamespace)
@@ -469,6 +614,76 @@ func (_c *IKubernetesClient_DeleteTemplateStore_Call) RunAndReturn(run func(stri
 	return _c
 }
 
+// DryRunApply provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IKubernetesClient) DryRunApply(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string, _a3 bool) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DryRunApply")
+	}
+
+	var r0 *unstructured.Unstructured
+	var r1 *unstructured.Unstructured
+	var r2 error
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) (*unstructured.Unstructured, *unstructured.Unstructured, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) *unstructured.Unstructured); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*unstructured.Unstructured)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) *unstructured.Unstructured); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*unstructured.Unstructured)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IKubernetesClient_DryRunApply_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DryRunApply'
+type IKubernetesClient_DryRunApply_Call struct {
+	*mock.Call
+}
+
+// DryRunApply is a helper method to define mock.On call
+//   - _a0 v1alpha1.GroupVersionResource
+//   - _a1 *unstructured.Unstructured
+//   - _a2 string
+//   - _a3 bool
+func (_e *IKubernetesClient_Expecter) DryRunApply(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *IKubernetesClient_DryRunApply_Call {
+	return &IKubernetesClient_DryRunApply_Call{Call: _e.mock.On("DryRunApply", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *IKubernetesClient_DryRunApply_Call) Run(run func(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string, _a3 bool)) *IKubernetesClient_DryRunApply_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(v1alpha1.GroupVersionResource), args[1].(*unstructured.Unstructured), args[2].(string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_DryRunApply_Call) Return(_a0 *unstructured.Unstructured, _a1 *unstructured.Unstructured, _a2 error) *IKubernetesClient_DryRunApply_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IKubernetesClient_DryRunApply_Call) RunAndReturn(run func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) (*unstructured.Unstructured, *unstructured.Unstructured, error)) *IKubernetesClient_DryRunApply_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GVKtoAPIResourceName provides a mock function with given fields: gv, kind
 func (_m *IKubernetesClient) GVKtoAPIResourceName(gv schema.GroupVersion, kind string) (string, error) {
 	ret := _m.Called(gv, kind)
@@ -1404,6 +1619,65 @@ func (_c *IKubernetesClient_GetTemplateStore_Call) RunAndReturn(run func(string)
 	return _c
 }
 
+// GetValuesSource provides a mock function with given fields: kind, name
+func (_m *IKubernetesClient) GetValuesSource(kind string, name string) (map[string]string, error) {
+	ret := _m.Called(kind, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetValuesSource")
+	}
+
+	var r0 map[string]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (map[string]string, error)); ok {
+		return rf(kind, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) map[string]string); ok {
+		r0 = rf(kind, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(kind, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_GetValuesSource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetValuesSource'
+type IKubernetesClient_GetValuesSource_Call struct {
+	*mock.Call
+}
+
+// GetValuesSource is a helper method to define mock.On call
+//   - kind string
+//   - name string
+func (_e *IKubernetesClient_Expecter) GetValuesSource(kind interface{}, name interface{}) *IKubernetesClient_GetValuesSource_Call {
+	return &IKubernetesClient_GetValuesSource_Call{Call: _e.mock.On("GetValuesSource", kind, name)}
+}
+
+func (_c *IKubernetesClient_GetValuesSource_Call) Run(run func(kind string, name string)) *IKubernetesClient_GetValuesSource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_GetValuesSource_Call) Return(_a0 map[string]string, _a1 error) *IKubernetesClient_GetValuesSource_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_GetValuesSource_Call) RunAndReturn(run func(string, string) (map[string]string, error)) *IKubernetesClient_GetValuesSource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetWorkloadsForModule provides a mock function with given fields: name
 func (_m *IKubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource, error) {
 	ret := _m.Called(name)
@@ -1520,6 +1794,182 @@ func (_c *IKubernetesClient_GetWorkloadsForRelease_Call) RunAndReturn(run func(s
 	return _c
 }
 
+// Impersonate provides a mock function with given fields: user, groups
+func (_m *IKubernetesClient) Impersonate(user string, groups []string) (k8sclient.IKubernetesClient, error) {
+	ret := _m.Called(user, groups)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Impersonate")
+	}
+
+	var r0 k8sclient.IKubernetesClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) (k8sclient.IKubernetesClient, error)); ok {
+		return rf(user, groups)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) k8sclient.IKubernetesClient); ok {
+		r0 = rf(user, groups)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(k8sclient.IKubernetesClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(user, groups)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_Impersonate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Impersonate'
+type IKubernetesClient_Impersonate_Call struct {
+	*mock.Call
+}
+
+// Impersonate is a helper method to define mock.On call
+//   - user string
+//   - groups []string
+func (_e *IKubernetesClient_Expecter) Impersonate(user interface{}, groups interface{}) *IKubernetesClient_Impersonate_Call {
+	return &IKubernetesClient_Impersonate_Call{Call: _e.mock.On("Impersonate", user, groups)}
+}
+
+func (_c *IKubernetesClient_Impersonate_Call) Run(run func(user string, groups []string)) *IKubernetesClient_Impersonate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_Impersonate_Call) Return(_a0 k8sclient.IKubernetesClient, _a1 error) *IKubernetesClient_Impersonate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_Impersonate_Call) RunAndReturn(run func(string, []string) (k8sclient.IKubernetesClient, error)) *IKubernetesClient_Impersonate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListManagedResources provides a mock function with given fields: moduleName, gvrs
+func (_m *IKubernetesClient) ListManagedResources(moduleName string, gvrs []v1alpha1.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	ret := _m.Called(moduleName, gvrs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListManagedResources")
+	}
+
+	var r0 []unstructured.Unstructured
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []v1alpha1.GroupVersionResource) ([]unstructured.Unstructured, error)); ok {
+		return rf(moduleName, gvrs)
+	}
+	if rf, ok := ret.Get(0).(func(string, []v1alpha1.GroupVersionResource) []unstructured.Unstructured); ok {
+		r0 = rf(moduleName, gvrs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]unstructured.Unstructured)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []v1alpha1.GroupVersionResource) error); ok {
+		r1 = rf(moduleName, gvrs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_ListManagedResources_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListManagedResources'
+type IKubernetesClient_ListManagedResources_Call struct {
+	*mock.Call
+}
+
+// ListManagedResources is a helper method to define mock.On call
+//   - moduleName string
+//   - gvrs []v1alpha1.GroupVersionResource
+func (_e *IKubernetesClient_Expecter) ListManagedResources(moduleName interface{}, gvrs interface{}) *IKubernetesClient_ListManagedResources_Call {
+	return &IKubernetesClient_ListManagedResources_Call{Call: _e.mock.On("ListManagedResources", moduleName, gvrs)}
+}
+
+func (_c *IKubernetesClient_ListManagedResources_Call) Run(run func(moduleName string, gvrs []v1alpha1.GroupVersionResource)) *IKubernetesClient_ListManagedResources_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]v1alpha1.GroupVersionResource))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_ListManagedResources_Call) Return(_a0 []unstructured.Unstructured, _a1 error) *IKubernetesClient_ListManagedResources_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_ListManagedResources_Call) RunAndReturn(run func(string, []v1alpha1.GroupVersionResource) ([]unstructured.Unstructured, error)) *IKubernetesClient_ListManagedResources_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListModuleRevisions provides a mock function with given fields: moduleName
+func (_m *IKubernetesClient) ListModuleRevisions(moduleName string) ([]v1alpha1.ModuleRevision, error) {
+	ret := _m.Called(moduleName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListModuleRevisions")
+	}
+
+	var r0 []v1alpha1.ModuleRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]v1alpha1.ModuleRevision, error)); ok {
+		return rf(moduleName)
+	}
+	if rf, ok := ret.Get(0).(func(string) []v1alpha1.ModuleRevision); ok {
+		r0 = rf(moduleName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1alpha1.ModuleRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(moduleName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_ListModuleRevisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListModuleRevisions'
+type IKubernetesClient_ListModuleRevisions_Call struct {
+	*mock.Call
+}
+
+// ListModuleRevisions is a helper method to define mock.On call
+//   - moduleName string
+func (_e *IKubernetesClient_Expecter) ListModuleRevisions(moduleName interface{}) *IKubernetesClient_ListModuleRevisions_Call {
+	return &IKubernetesClient_ListModuleRevisions_Call{Call: _e.mock.On("ListModuleRevisions", moduleName)}
+}
+
+func (_c *IKubernetesClient_ListModuleRevisions_Call) Run(run func(moduleName string)) *IKubernetesClient_ListModuleRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_ListModuleRevisions_Call) Return(_a0 []v1alpha1.ModuleRevision, _a1 error) *IKubernetesClient_ListModuleRevisions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_ListModuleRevisions_Call) RunAndReturn(run func(string) ([]v1alpha1.ModuleRevision, error)) *IKubernetesClient_ListModuleRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListModules provides a mock function with no fields
 func (_m *IKubernetesClient) ListModules() ([]v1alpha1.Module, error) {
 	ret := _m.Called()
@@ -2054,6 +2504,137 @@ func (_c *IKubernetesClient_RestartStatefulSet_Call) RunAndReturn(run func(strin
 	return _c
 }
 
+// SubjectAccessReview provides a mock function with given fields: user, groups, path, verb
+func (_m *IKubernetesClient) SubjectAccessReview(user string, groups []string, path string, verb string) (bool, string, error) {
+	ret := _m.Called(user, groups, path, verb)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubjectAccessReview")
+	}
+
+	var r0 bool
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, []string, string, string) (bool, string, error)); ok {
+		return rf(user, groups, path, verb)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string, string, string) bool); ok {
+		r0 = rf(user, groups, path, verb)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string, string, string) string); ok {
+		r1 = rf(user, groups, path, verb)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, []string, string, string) error); ok {
+		r2 = rf(user, groups, path, verb)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IKubernetesClient_SubjectAccessReview_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SubjectAccessReview'
+type IKubernetesClient_SubjectAccessReview_Call struct {
+	*mock.Call
+}
+
+// SubjectAccessReview is a helper method to define mock.On call
+//   - user string
+//   - groups []string
+//   - path string
+//   - verb string
+func (_e *IKubernetesClient_Expecter) SubjectAccessReview(user interface{}, groups interface{}, path interface{}, verb interface{}) *IKubernetesClient_SubjectAccessReview_Call {
+	return &IKubernetesClient_SubjectAccessReview_Call{Call: _e.mock.On("SubjectAccessReview", user, groups, path, verb)}
+}
+
+func (_c *IKubernetesClient_SubjectAccessReview_Call) Run(run func(user string, groups []string, path string, verb string)) *IKubernetesClient_SubjectAccessReview_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_SubjectAccessReview_Call) Return(_a0 bool, _a1 string, _a2 error) *IKubernetesClient_SubjectAccessReview_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IKubernetesClient_SubjectAccessReview_Call) RunAndReturn(run func(string, []string, string, string) (bool, string, error)) *IKubernetesClient_SubjectAccessReview_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TokenReview provides a mock function with given fields: token
+func (_m *IKubernetesClient) TokenReview(token string) (*authenticationv1.UserInfo, bool, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TokenReview")
+	}
+
+	var r0 *authenticationv1.UserInfo
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (*authenticationv1.UserInfo, bool, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) *authenticationv1.UserInfo); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*authenticationv1.UserInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IKubernetesClient_TokenReview_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TokenReview'
+type IKubernetesClient_TokenReview_Call struct {
+	*mock.Call
+}
+
+// TokenReview is a helper method to define mock.On call
+//   - token string
+func (_e *IKubernetesClient_Expecter) TokenReview(token interface{}) *IKubernetesClient_TokenReview_Call {
+	return &IKubernetesClient_TokenReview_Call{Call: _e.mock.On("TokenReview", token)}
+}
+
+func (_c *IKubernetesClient_TokenReview_Call) Run(run func(token string)) *IKubernetesClient_TokenReview_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_TokenReview_Call) Return(_a0 *authenticationv1.UserInfo, _a1 bool, _a2 error) *IKubernetesClient_TokenReview_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IKubernetesClient_TokenReview_Call) RunAndReturn(run func(string) (*authenticationv1.UserInfo, bool, error)) *IKubernetesClient_TokenReview_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateModule provides a mock function with given fields: module
 func (_m *IKubernetesClient) UpdateModule(module *v1alpha1.Module) error {
 	ret := _m.Called(module)
@@ -0,0 +1,377 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	json "github.com/json-iterator/go"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+)
+
+// DefaultDiskMaxSize is the default size limit of the disk cache (1GB)
+const DefaultDiskMaxSize = 1 << 30
+
+// Disk caches templates on disk so they survive controller restarts. Templates
+// are kept in memory as well, and evicted from disk in least recently used
+// order once the cache exceeds its max size.
+type Disk struct {
+	memory Templates
+	store  *diskStore
+}
+
+func NewDiskTemplatesCache(dir string, maxSize int64, ttl TTL) (*Disk, error) {
+	store, err := newDiskStore(dir, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Disk{
+		memory: NewInMemoryTemplatesCache(ttl),
+		store:  store,
+	}, nil
+}
+
+func (d *Disk) GetTemplate(repo, path, version, sourceType string) (*models.Template, bool) {
+	if template, ok := d.memory.GetTemplate(repo, path, version, sourceType); ok {
+		return template, true
+	}
+
+	key := templateKey(repo, path, version, sourceType)
+
+	data, expiresAt, ok := d.store.get(key)
+	if !ok {
+		return nil, false
+	}
+
+	var template *models.Template
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, false
+	}
+
+	d.memory.cache.SetWithTTL(key, data, int64(len(data)), remainingTTL(expiresAt))
+	d.memory.cache.Wait()
+
+	return template, true
+}
+
+func (d *Disk) SetTemplate(repo, path, version, sourceType string, template *models.Template) {
+	d.memory.SetTemplate(repo, path, version, sourceType, template)
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		return
+	}
+
+	d.store.set(templateKey(repo, path, version, sourceType), data, d.memory.ttl.For(version))
+}
+
+func (d *Disk) GetTemplateInitialValues(repo, path, version, sourceType string) (map[string]interface{}, bool) {
+	if values, ok := d.memory.GetTemplateInitialValues(repo, path, version, sourceType); ok {
+		return values, true
+	}
+
+	key := initialValuesKey(repo, path, version, sourceType)
+
+	data, expiresAt, ok := d.store.get(key)
+	if !ok {
+		return nil, false
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, false
+	}
+
+	d.memory.cache.SetWithTTL(key, values, int64(len(data)), remainingTTL(expiresAt))
+	d.memory.cache.Wait()
+
+	return values, true
+}
+
+func (d *Disk) SetTemplateInitialValues(repo, path, version, sourceType string, values map[string]interface{}) {
+	d.memory.SetTemplateInitialValues(repo, path, version, sourceType, values)
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return
+	}
+
+	d.store.set(initialValuesKey(repo, path, version, sourceType), data, d.memory.ttl.For(version))
+}
+
+func (d *Disk) Invalidate(repo, path, version, sourceType string) {
+	d.memory.Invalidate(repo, path, version, sourceType)
+
+	d.store.delete(templateKey(repo, path, version, sourceType))
+	d.store.delete(initialValuesKey(repo, path, version, sourceType))
+}
+
+func (d *Disk) ReturnCache() *ristretto.Cache {
+	return d.memory.ReturnCache()
+}
+
+func remainingTTL(expiresAt time.Time) time.Duration {
+	if expiresAt.IsZero() {
+		return 0
+	}
+
+	return time.Until(expiresAt)
+}
+
+// diskEntry is a file written for a cache key
+type diskEntry struct {
+	Key       string          `json:"key"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type indexEntry struct {
+	name string
+	size int64
+}
+
+// diskStore keeps entries in files named by the hash of their key. The LRU
+// order is kept in memory and persisted in file modification times.
+type diskStore struct {
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+func newDiskStore(dir string, maxSize int64) (*diskStore, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultDiskMaxSize
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &diskStore{
+		dir:     dir,
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load indexes the entries left on disk by a previous run
+func (s *diskStore) load() error {
+	type file struct {
+		indexEntry
+		modTime time.Time
+	}
+
+	files := make([]file, 0)
+
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if strings.HasSuffix(path, ".tmp") {
+			return os.Remove(path)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		if filepath.Ext(path) != ".json" || len(name) != sha256.Size*2 {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, file{
+			indexEntry: indexEntry{
+				name: name,
+				size: info.Size(),
+			},
+			modTime: info.ModTime(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range files {
+		s.entries[f.name] = s.lru.PushFront(&indexEntry{name: f.name, size: f.size})
+		s.size += f.size
+	}
+
+	s.evict()
+
+	return nil
+}
+
+func (s *diskStore) get(key string) ([]byte, time.Time, bool) {
+	name := entryName(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[name]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		s.remove(elem)
+		return nil, time.Time{}, false
+	}
+
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		s.remove(elem)
+		return nil, time.Time{}, false
+	}
+
+	if !entry.ExpiresAt.IsZero() && time.Now().After(entry.ExpiresAt) {
+		s.remove(elem)
+		return nil, time.Time{}, false
+	}
+
+	s.lru.MoveToFront(elem)
+	now := time.Now()
+	_ = os.Chtimes(s.path(name), now, now)
+
+	return entry.Data, entry.ExpiresAt, true
+}
+
+func (s *diskStore) set(key string, value []byte, ttl time.Duration) {
+	entry := diskEntry{
+		Key:  key,
+		Data: value,
+	}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	size := int64(len(data))
+	if size > s.maxSize {
+		return
+	}
+
+	name := entryName(key)
+	path := s.path(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return
+	}
+
+	if elem, ok := s.entries[name]; ok {
+		s.size -= elem.Value.(*indexEntry).size
+		elem.Value.(*indexEntry).size = size
+		s.lru.MoveToFront(elem)
+	} else {
+		s.entries[name] = s.lru.PushFront(&indexEntry{name: name, size: size})
+	}
+	s.size += size
+
+	s.evict()
+}
+
+func (s *diskStore) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[entryName(key)]; ok {
+		s.remove(elem)
+	}
+}
+
+// evict removes least recently used entries until the store fits its max size
+func (s *diskStore) evict() {
+	for s.size > s.maxSize {
+		elem := s.lru.Back()
+		if elem == nil {
+			return
+		}
+
+		s.remove(elem)
+	}
+}
+
+func (s *diskStore) remove(elem *list.Element) {
+	entry := elem.Value.(*indexEntry)
+
+	// the entry is dropped from the index even if the file can not be removed,
+	// so it does not count towards the max size again
+	_ = os.Remove(s.path(entry.name))
+
+	s.lru.Remove(elem)
+	delete(s.entries, entry.name)
+	s.size -= entry.size
+}
+
+func (s *diskStore) path(name string) string {
+	return filepath.Join(s.dir, name[:2], name+".json")
+}
+
+func entryName(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+)
+
+func TestCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test templates cache")
+}
+
+const commitSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+var _ = Describe("Disk templates cache", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("keeps templates across restarts", func() {
+		diskCache, err := NewDiskTemplatesCache(dir, 0, DefaultTTL())
+		Expect(err).ToNot(HaveOccurred())
+
+		diskCache.SetTemplate("https://github.com/my-org/templates", "demo", commitSHA, "git", &models.Template{
+			Name:            "demo",
+			ResolvedVersion: commitSHA,
+		})
+		diskCache.SetTemplateInitialValues("https://github.com/my-org/templates", "demo", commitSHA, "git", map[string]interface{}{
+			"replicas": 3,
+		})
+
+		restarted, err := NewDiskTemplatesCache(dir, 0, DefaultTTL())
+		Expect(err).ToNot(HaveOccurred())
+
+		template, ok := restarted.GetTemplate("https://github.com/my-org/templates", "demo", commitSHA, "git")
+		Expect(ok).To(BeTrue())
+		Expect(template.Name).To(BeEquivalentTo("demo"))
+
+		values, ok := restarted.GetTemplateInitialValues("https://github.com/my-org/templates", "demo", commitSHA, "git")
+		Expect(ok).To(BeTrue())
+		Expect(values).To(HaveKeyWithValue("replicas", BeEquivalentTo(3)))
+
+		_, ok = restarted.GetTemplate("https://github.com/my-org/templates", "other", commitSHA, "git")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("evicts least recently used templates", func() {
+		store, err := newDiskStore(dir, 300)
+		Expect(err).ToNot(HaveOccurred())
+
+		value := []byte(`"` + strings.Repeat("a", 50) + `"`)
+
+		store.set("first", value, 0)
+		store.set("second", value, 0)
+
+		_, _, ok := store.get("first")
+		Expect(ok).To(BeTrue())
+
+		store.set("third", value, 0)
+
+		_, _, ok = store.get("second")
+		Expect(ok).To(BeFalse())
+		_, _, ok = store.get("first")
+		Expect(ok).To(BeTrue())
+		_, _, ok = store.get("third")
+		Expect(ok).To(BeTrue())
+
+		Expect(store.size).To(BeNumerically("<=", 300))
+	})
+
+	It("expires templates of mutable versions", func() {
+		store, err := newDiskStore(dir, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		store.set("mutable", []byte(`{}`), time.Millisecond)
+		store.set("immutable", []byte(`{}`), 0)
+
+		time.Sleep(5 * time.Millisecond)
+
+		_, _, ok := store.get("mutable")
+		Expect(ok).To(BeFalse())
+		_, _, ok = store.get("immutable")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("is safe for concurrent use", func() {
+		diskCache, err := NewDiskTemplatesCache(dir, 4096, DefaultTTL())
+		Expect(err).ToNot(HaveOccurred())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+
+				version := []string{"1.0.0", "1.1.0", commitSHA}[i%3]
+				diskCache.SetTemplate("https://charts.my-org.com", "demo", version, "helm", &models.Template{Name: "demo"})
+				diskCache.GetTemplate("https://charts.my-org.com", "demo", version, "helm")
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(diskCache.store.size).To(BeNumerically("<=", 4096))
+	})
+})
+
+var _ = Describe("TTL", func() {
+	It("separates immutable from mutable versions", func() {
+		ttl := TTL{Immutable: 0, Mutable: time.Minute}
+
+		Expect(ttl.For(commitSHA)).To(BeEquivalentTo(0))
+		Expect(ttl.For("sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")).To(BeEquivalentTo(0))
+		Expect(ttl.For("main")).To(BeEquivalentTo(time.Minute))
+		Expect(ttl.For("1.2.3")).To(BeEquivalentTo(time.Minute))
+	})
+})
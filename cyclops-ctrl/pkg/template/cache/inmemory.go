@@ -2,7 +2,6 @@ package cache
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/dgraph-io/ristretto"
 	json "github.com/json-iterator/go"
@@ -12,9 +11,10 @@ import (
 
 type Templates struct {
 	cache *ristretto.Cache
+	ttl   TTL
 }
 
-func NewInMemoryTemplatesCache() Templates {
+func NewInMemoryTemplatesCache(ttl TTL) Templates {
 	cache, err := ristretto.NewCache(&ristretto.Config{
 		NumCounters: 1e7,     // number of keys to track frequency of (10M).
 		MaxCost:     1 << 30, // maximum cost of cache (1GB).
@@ -27,6 +27,7 @@ func NewInMemoryTemplatesCache() Templates {
 
 	return Templates{
 		cache: cache,
+		ttl:   ttl,
 	}
 }
 
@@ -55,7 +56,7 @@ func (t Templates) SetTemplate(repo, path, version, sourceType string, template
 		return
 	}
 
-	t.cache.SetWithTTL(templateKey(repo, path, version, sourceType), data, int64(len(data)), time.Minute*15)
+	t.cache.SetWithTTL(templateKey(repo, path, version, sourceType), data, int64(len(data)), t.ttl.For(version))
 	t.cache.Wait()
 }
 
@@ -79,10 +80,16 @@ func (t Templates) SetTemplateInitialValues(repo, path, version, sourceType stri
 		return
 	}
 
-	t.cache.SetWithTTL(initialValuesKey(repo, path, version, sourceType), values, int64(len(data)), time.Minute*15)
+	t.cache.SetWithTTL(initialValuesKey(repo, path, version, sourceType), values, int64(len(data)), t.ttl.For(version))
 	t.cache.Wait()
 }
 
+// Invalidate removes the template and its initial values from the cache
+func (t Templates) Invalidate(repo, path, version, sourceType string) {
+	t.cache.Del(templateKey(repo, path, version, sourceType))
+	t.cache.Del(initialValuesKey(repo, path, version, sourceType))
+}
+
 func (t Templates) ReturnCache() *ristretto.Cache {
 	return t.cache
 }
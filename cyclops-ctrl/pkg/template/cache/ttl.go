@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultMutableTTL is how long templates of mutable versions, like tags and
+// chart versions, are cached by default
+const DefaultMutableTTL = 15 * time.Minute
+
+var shaRegex = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// TTL configures how long templates are cached. A zero TTL caches templates
+// until they are evicted.
+type TTL struct {
+	// Immutable is the TTL of templates resolved to a commit SHA or a digest
+	Immutable time.Duration
+	// Mutable is the TTL of templates resolved to any other version
+	Mutable time.Duration
+}
+
+func DefaultTTL() TTL {
+	return TTL{
+		Immutable: 0,
+		Mutable:   DefaultMutableTTL,
+	}
+}
+
+func (t TTL) For(version string) time.Duration {
+	if IsImmutableVersion(version) {
+		return t.Immutable
+	}
+
+	return t.Mutable
+}
+
+// IsImmutableVersion reports whether the version is a git commit SHA or an OCI
+// digest, whose content can not change
+func IsImmutableVersion(version string) bool {
+	return strings.HasPrefix(version, "sha256:") || shaRegex.MatchString(version)
+}
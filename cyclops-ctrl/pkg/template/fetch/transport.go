@@ -0,0 +1,254 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"golang.org/x/time/rate"
+)
+
+const (
+	_maxBackoff    = 30 * time.Second
+	_maxRetryAfter = time.Minute
+)
+
+// Limits of requests to a single template host
+type Limits struct {
+	// RequestsPerSecond is the rate of the host token bucket. Zero disables
+	// rate limiting.
+	RequestsPerSecond float64
+	Burst             int
+	// MaxConcurrent is the number of requests in flight to the host. Zero
+	// disables the limit.
+	MaxConcurrent int
+	// MaxRetries of requests failed with 429 or 5xx
+	MaxRetries int
+	// Backoff before the first retry, doubled on each retry
+	Backoff time.Duration
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		RequestsPerSecond: 10,
+		Burst:             20,
+		MaxConcurrent:     5,
+		MaxRetries:        3,
+		Backoff:           500 * time.Millisecond,
+	}
+}
+
+// Client is used for all requests to template repositories
+var Client = &http.Client{
+	Transport: NewTransport(http.DefaultTransport, DefaultLimits()),
+}
+
+// Configure sets the limits of Client and makes go-git use it for http and
+// https repositories
+func Configure(limits Limits) {
+	Client.Transport = NewTransport(http.DefaultTransport, limits)
+
+	gitClient := githttp.NewClient(Client)
+	client.InstallProtocol("http", gitClient)
+	client.InstallProtocol("https", gitClient)
+}
+
+// Transport limits the rate and concurrency of requests per host and retries
+// requests rate limited or failed by the host
+type Transport struct {
+	base   http.RoundTripper
+	limits Limits
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+type host struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+func NewTransport(base http.RoundTripper, limits Limits) *Transport {
+	return &Transport{
+		base:   base,
+		limits: limits,
+		hosts:  make(map[string]*host),
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h := t.host(req.URL.Host)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.roundTrip(h, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if attempt >= t.limits.MaxRetries || !retryable(resp.StatusCode) {
+			return resp, nil
+		}
+
+		retryReq, ok := rewind(req)
+		if !ok {
+			return resp, nil
+		}
+
+		wait := retryAfter(resp)
+		if wait == 0 {
+			wait = backoff(t.limits.Backoff, attempt)
+		}
+
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		req = retryReq
+	}
+}
+
+func (t *Transport) roundTrip(h *host, req *http.Request) (*http.Response, error) {
+	if h.limiter != nil {
+		if err := h.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.slots == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		<-h.slots
+		return nil, err
+	}
+
+	// the slot is held until the response body is read, so large downloads
+	// count towards the concurrency limit
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { <-h.slots }}
+	return resp, nil
+}
+
+func (t *Transport) host(name string) *host {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if h, ok := t.hosts[name]; ok {
+		return h
+	}
+
+	h := &host{}
+	if t.limits.RequestsPerSecond > 0 {
+		burst := t.limits.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		h.limiter = rate.NewLimiter(rate.Limit(t.limits.RequestsPerSecond), burst)
+	}
+	if t.limits.MaxConcurrent > 0 {
+		h.slots = make(chan struct{}, t.limits.MaxConcurrent)
+	}
+
+	t.hosts[name] = h
+	return h
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// rewind returns a copy of the request that can be sent again
+func rewind(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Clone(req.Context()), true
+	}
+
+	if req.GetBody == nil {
+		return nil, false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
+	return retryReq, true
+}
+
+// retryAfter returns the wait set in the Retry-After header, in seconds or as
+// an HTTP date
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if len(value) == 0 {
+		return 0
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = time.Until(date)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+
+	if wait > _maxRetryAfter {
+		return _maxRetryAfter
+	}
+
+	return wait
+}
+
+func backoff(base time.Duration, attempt int) time.Duration {
+	wait := base << attempt
+	if wait <= 0 || wait > _maxBackoff {
+		wait = _maxBackoff
+	}
+
+	// jitter spreads retries of concurrent requests
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
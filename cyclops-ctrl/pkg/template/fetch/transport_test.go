@@ -0,0 +1,131 @@
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFetch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test template fetch")
+}
+
+var _ = Describe("Transport", func() {
+	It("retries rate limited requests after Retry-After", func() {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewTransport(http.DefaultTransport, Limits{MaxRetries: 3, Backoff: time.Millisecond})}
+
+		start := time.Now()
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		resp.Body.Close()
+
+		Expect(resp.StatusCode).To(BeEquivalentTo(http.StatusOK))
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+	})
+
+	It("returns the last response once retries are exhausted", func() {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewTransport(http.DefaultTransport, Limits{MaxRetries: 2, Backoff: time.Millisecond})}
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		resp.Body.Close()
+
+		Expect(resp.StatusCode).To(BeEquivalentTo(http.StatusBadGateway))
+		Expect(requests.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("does not retry client errors", func() {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewTransport(http.DefaultTransport, Limits{MaxRetries: 3, Backoff: time.Millisecond})}
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		resp.Body.Close()
+
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("limits concurrent requests per host", func() {
+		var inFlight, maxInFlight atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+
+			for {
+				max := maxInFlight.Load()
+				if current <= max || maxInFlight.CompareAndSwap(max, current) {
+					break
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewTransport(http.DefaultTransport, Limits{MaxConcurrent: 2})}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+
+				resp, err := client.Get(server.URL)
+				Expect(err).ToNot(HaveOccurred())
+				resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+
+		Expect(maxInFlight.Load()).To(BeNumerically("<=", 2))
+	})
+})
+
+var _ = Describe("retryAfter", func() {
+	It("parses seconds and HTTP dates", func() {
+		resp := &http.Response{Header: http.Header{}}
+
+		resp.Header.Set("Retry-After", "5")
+		Expect(retryAfter(resp)).To(BeEquivalentTo(5 * time.Second))
+
+		resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+		Expect(retryAfter(resp)).To(BeEquivalentTo(0))
+
+		resp.Header.Set("Retry-After", "3600")
+		Expect(retryAfter(resp)).To(BeEquivalentTo(_maxRetryAfter))
+	})
+})
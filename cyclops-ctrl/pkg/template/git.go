@@ -23,7 +23,6 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"helm.sh/helm/v3/pkg/chart"
 
@@ -49,11 +48,17 @@ func (r Repo) LoadTemplate(repoURL, path, commit, resolvedVersion string) (*mode
 		commitSHA = ref
 	}
 
-	cached, ok := r.cache.GetTemplate(repoURL, path, commitSHA, string(cyclopsv1alpha1.TemplateSourceTypeGit))
+	cacheSource := cacheSourceType(cyclopsv1alpha1.TemplateSourceTypeGit, creds)
+
+	cached, ok := r.cache.GetTemplate(repoURL, path, commitSHA, cacheSource)
 	if ok {
 		return cached, nil
 	}
 
+	if err := verifyGitTemplate(repoURL, commit, commitSHA, creds); err != nil {
+		return nil, err
+	}
+
 	if gitproviders2.IsGitHubSource(repoURL) {
 		ghTemplate, err := r.mapGitHubRepoTemplate(repoURL, path, commitSHA, creds)
 		if err != nil {
@@ -63,7 +68,7 @@ func (r Repo) LoadTemplate(repoURL, path, commit, resolvedVersion string) (*mode
 		ghTemplate.Version = commit
 		ghTemplate.ResolvedVersion = commitSHA
 
-		r.cache.SetTemplate(repoURL, path, commitSHA, string(cyclopsv1alpha1.TemplateSourceTypeGit), ghTemplate)
+		r.cache.SetTemplate(repoURL, path, commitSHA, cacheSource, ghTemplate)
 
 		return ghTemplate, nil
 	}
@@ -187,7 +192,7 @@ func (r Repo) LoadTemplate(repoURL, path, commit, resolvedVersion string) (*mode
 	}
 	// endregion
 
-	r.cache.SetTemplate(repoURL, path, commitSHA, string(cyclopsv1alpha1.TemplateSourceTypeGit), template)
+	r.cache.SetTemplate(repoURL, path, commitSHA, cacheSource, template)
 
 	return template, err
 }
@@ -282,7 +287,7 @@ func (r Repo) listRemoteRefs(repo string, creds *auth.Credentials) ([]string, er
 
 	refs, err := rem.List(&git.ListOptions{
 		PeelingOption: git.AppendPeeled,
-		Auth:          httpBasicAuthCredentials(creds),
+		Auth:          creds.GitAuth(),
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repo))
@@ -312,7 +317,7 @@ func resolveRef(repo, version string, creds *auth.Credentials) (string, error) {
 	// We can then use every Remote functions to retrieve wanted information
 	refs, err := rem.List(&git.ListOptions{
 		PeelingOption: git.AppendPeeled,
-		Auth:          httpBasicAuthCredentials(creds),
+		Auth:          creds.GitAuth(),
 	})
 	if err != nil {
 		return "", errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repo))
@@ -337,7 +342,7 @@ func resolveDefaultBranchRef(repo string, creds *auth.Credentials) (string, erro
 	// We can then use every Remote functions to retrieve wanted information
 	refs, err := rem.List(&git.ListOptions{
 		PeelingOption: git.AppendPeeled,
-		Auth:          httpBasicAuthCredentials(creds),
+		Auth:          creds.GitAuth(),
 	})
 	if err != nil {
 		return "", errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repo))
@@ -387,7 +392,7 @@ func clone(repoURL, commit string, creds *auth.Credentials) (billy.Filesystem, e
 	repo, err := git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{
 		URL:  repoURL,
 		Tags: git.AllTags,
-		Auth: httpBasicAuthCredentials(creds),
+		Auth: creds.GitAuth(),
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repoURL))
@@ -407,7 +412,7 @@ func clone(repoURL, commit string, creds *auth.Credentials) (billy.Filesystem, e
 			return nil, err
 		}
 		refList, err := remote.List(&git.ListOptions{
-			Auth: httpBasicAuthCredentials(creds),
+			Auth: creds.GitAuth(),
 		})
 		if err != nil {
 			return nil, err
@@ -583,14 +588,3 @@ func (r Repo) mapGitHubRepoInitialValues(repoURL, path, commitSHA string, creds
 
 	return initial, nil
 }
-
-func httpBasicAuthCredentials(creds *auth.Credentials) *http.BasicAuth {
-	if creds == nil {
-		return nil
-	}
-
-	return &http.BasicAuth{
-		Username: creds.Username,
-		Password: creds.Password,
-	}
-}
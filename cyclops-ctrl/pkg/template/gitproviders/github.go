@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/fetch"
 
 	"github.com/pkg/errors"
 )
@@ -19,7 +20,8 @@ func IsGitHubSource(repoURL string) bool {
 		return false
 	}
 
-	return host.Host == "github.com"
+	// repositories cloned over ssh are not fetched as tarballs
+	return host.Host == "github.com" && (host.Scheme == "https" || host.Scheme == "http")
 }
 
 func GitHubClone(repoURL, commitSHA string, creds *auth.Credentials) ([]byte, error) {
@@ -55,7 +57,7 @@ func gitHubTarball(org, repo, commitSHA string, creds *auth.Credentials) ([]byte
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
 	}
 
-	client := &http.Client{}
+	client := fetch.Client
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
@@ -21,26 +20,32 @@ import (
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/mapper"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/helm"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/fetch"
 )
 
 func (r Repo) LoadHelmChart(repo, chart, version, resolvedVersion string) (*models.Template, error) {
-	var err error
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	strictVersion := version
 	if len(resolvedVersion) > 0 {
 		strictVersion = resolvedVersion
 	} else if !isValidVersion(version) {
-		strictVersion, err = getRepoStrictVersion(repo, chart, version)
+		strictVersion, err = getRepoStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	cached, ok := r.cache.GetTemplate(repo, chart, strictVersion, string(cyclopsv1alpha1.TemplateSourceTypeHelm))
+	cached, ok := r.cache.GetTemplate(repo, chart, strictVersion, cacheSourceType(cyclopsv1alpha1.TemplateSourceTypeHelm, creds))
 	if ok {
 		return cached, nil
 	}
 
-	tgzData, err := r.loadFromHelmChartRepo(repo, chart, version)
+	tgzData, err := loadFromHelmChartRepo(repo, chart, version, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -58,16 +63,20 @@ func (r Repo) LoadHelmChart(repo, chart, version, resolvedVersion string) (*mode
 	template.Version = version
 	template.ResolvedVersion = strictVersion
 
-	r.cache.SetTemplate(repo, chart, strictVersion, string(cyclopsv1alpha1.TemplateSourceTypeHelm), template)
+	r.cache.SetTemplate(repo, chart, strictVersion, cacheSourceType(cyclopsv1alpha1.TemplateSourceTypeHelm, creds), template)
 
 	return template, nil
 }
 
 func (r Repo) LoadHelmChartInitialValues(repo, chart, version string) (map[string]interface{}, error) {
-	var err error
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	strictVersion := version
 	if !isValidVersion(version) {
-		strictVersion, err = getRepoStrictVersion(repo, chart, version)
+		strictVersion, err = getRepoStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
@@ -78,7 +87,7 @@ func (r Repo) LoadHelmChartInitialValues(repo, chart, version string) (map[strin
 		return cached, nil
 	}
 
-	tgzData, err := r.loadFromHelmChartRepo(repo, chart, version)
+	tgzData, err := loadFromHelmChartRepo(repo, chart, version, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +107,7 @@ func (r Repo) LoadHelmChartInitialValues(repo, chart, version string) (map[strin
 	return initial, nil
 }
 
-func IsHelmRepo(repo string) (bool, error) {
+func IsHelmRepo(repo string, creds *auth.Credentials) (bool, error) {
 	indexURL, err := url.JoinPath(repo, "index.yaml")
 	if err != nil {
 		return false, err
@@ -108,8 +117,9 @@ func IsHelmRepo(repo string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	creds.SetAuthorization(req)
 
-	client := &http.Client{}
+	client := fetch.Client
 	resp, err := client.Do(req)
 	if err != nil {
 		return false, err
@@ -119,13 +129,33 @@ func IsHelmRepo(repo string) (bool, error) {
 	return resp.StatusCode == http.StatusOK, nil
 }
 
-func (r Repo) loadFromHelmChartRepo(repo, chart, version string) ([]byte, error) {
-	tgzURL, err := getTarUrl(repo, chart, version)
+func loadFromHelmChartRepo(repo, chart, version string, creds *auth.Credentials) ([]byte, error) {
+	tgzURL, err := getTarUrl(repo, chart, version, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := verificationKeys(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sameHost(repo, tgzURL) {
+		creds = nil
+	}
+
+	tgzData, err := downloadFile(tgzURL, creds)
 	if err != nil {
 		return nil, err
 	}
 
-	return downloadFile(tgzURL)
+	if keys != nil {
+		if err := verifyHelmProvenance(tgzURL, tgzData, keys, creds); err != nil {
+			return nil, err
+		}
+	}
+
+	return tgzData, nil
 }
 
 func (r Repo) mapHelmChart(chartName string, files map[string][]byte) (*models.Template, error) {
@@ -329,25 +359,8 @@ func overlayValues(existing interface{}, overlay interface{}) interface{} {
 	return existingMap
 }
 
-func getTarUrl(repo, chart, version string) (string, error) {
-	indexURL, err := url.JoinPath(repo, "index.yaml")
-	if err != nil {
-		return "", err
-	}
-
-	response, err := http.Get(indexURL)
-	if err != nil {
-		return "", err
-	}
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return "", err
-	}
-
-	var data helm.Index
-	err = yaml.Unmarshal(body, &data)
+func getTarUrl(repo, chart, version string, creds *auth.Credentials) (string, error) {
+	data, err := loadIndex(repo, creds)
 	if err != nil {
 		return "", err
 	}
@@ -367,41 +380,63 @@ func getTarUrl(repo, chart, version string) (string, error) {
 				return "", errors.New(fmt.Sprintf("no URL on version %v of chart %v and repo %v", version, chart, repo))
 			}
 
-			return entry.URLs[0], nil
+			return chartURL(repo, entry.URLs[0])
 		}
 	}
 
 	return "", errors.New(fmt.Sprintf("version %v not found in chart %v and repo %v", version, chart, repo))
 }
 
-func getRepoStrictVersion(repo, chart, version string) (string, error) {
-	indexURL, err := url.JoinPath(repo, "index.yaml")
+func getRepoStrictVersion(repo, chart, version string, creds *auth.Credentials) (string, error) {
+	data, err := loadIndex(repo, creds)
 	if err != nil {
 		return "", err
 	}
 
-	response, err := http.Get(indexURL)
+	if _, ok := data.Entries[chart]; !ok {
+		return "", errors.New(fmt.Sprintf("chart %v not found in repo %v", chart, repo))
+	}
+
+	return resolveVersion(data.Entries[chart], version)
+}
+
+func loadIndex(repo string, creds *auth.Credentials) (*helm.Index, error) {
+	indexURL, err := url.JoinPath(repo, "index.yaml")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer response.Body.Close()
 
-	body, err := ioutil.ReadAll(response.Body)
+	body, err := downloadFile(indexURL, creds)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var data helm.Index
-	err = yaml.Unmarshal(body, &data)
+	if err := yaml.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// chartURL resolves chart URLs relative to the repository, as served by
+// ChartMuseum and other repositories
+func chartURL(repo, chartURL string) (string, error) {
+	parsed, err := url.Parse(chartURL)
 	if err != nil {
 		return "", err
 	}
 
-	if _, ok := data.Entries[chart]; !ok {
-		return "", errors.New(fmt.Sprintf("chart %v not found in repo %v", chart, repo))
+	if parsed.IsAbs() {
+		return chartURL, nil
 	}
 
-	return resolveVersion(data.Entries[chart], version)
+	base, err := url.Parse(strings.TrimSuffix(repo, "/") + "/")
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(parsed).String(), nil
 }
 
 func resolveVersion(indexEntries []helm.IndexEntry, version string) (string, error) {
@@ -417,15 +452,21 @@ func resolveVersion(indexEntries []helm.IndexEntry, version string) (string, err
 	return resolveSemver(version, versions)
 }
 
-func downloadFile(url string) ([]byte, error) {
-	response, err := http.Get(url)
+func downloadFile(url string, creds *auth.Credentials) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	creds.SetAuthorization(req)
+
+	response, err := fetch.Client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP request failed with status: %s", response.Status)
+	if err := registryResponseError(response, creds); err != nil {
+		return nil, err
 	}
 
 	body, err := io.ReadAll(response.Body)
@@ -1,6 +1,7 @@
 package template
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -12,28 +13,34 @@ import (
 
 	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/fetch"
 )
 
 func (r Repo) LoadOCIHelmChart(repo, chart, version, resolvedVersion string) (*models.Template, error) {
-	var err error
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	strictVersion := version
 
 	if len(resolvedVersion) > 0 {
 		strictVersion = resolvedVersion
 	} else if !isValidVersion(version) {
-		strictVersion, err = getOCIStrictVersion(repo, chart, version)
+		strictVersion, err = getOCIStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	cached, ok := r.cache.GetTemplate(repo, chart, strictVersion, string(cyclopsv1alpha1.TemplateSourceTypeOCI))
+	cached, ok := r.cache.GetTemplate(repo, chart, strictVersion, cacheSourceType(cyclopsv1alpha1.TemplateSourceTypeOCI, creds))
 	if ok {
 		return cached, nil
 	}
 
 	var tgzData []byte
-	tgzData, err = loadOCIHelmChartBytes(repo, chart, version)
+	tgzData, err = loadOCIHelmChartBytes(repo, chart, version, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -51,16 +58,20 @@ func (r Repo) LoadOCIHelmChart(repo, chart, version, resolvedVersion string) (*m
 	template.Version = version
 	template.ResolvedVersion = strictVersion
 
-	r.cache.SetTemplate(repo, chart, strictVersion, string(cyclopsv1alpha1.TemplateSourceTypeOCI), template)
+	r.cache.SetTemplate(repo, chart, strictVersion, cacheSourceType(cyclopsv1alpha1.TemplateSourceTypeOCI, creds), template)
 
 	return template, nil
 }
 
 func (r Repo) LoadOCIHelmChartInitialValues(repo, chart, version string) (map[string]interface{}, error) {
-	var err error
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	strictVersion := version
 	if !isValidVersion(version) {
-		strictVersion, err = getOCIStrictVersion(repo, chart, version)
+		strictVersion, err = getOCIStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +82,7 @@ func (r Repo) LoadOCIHelmChartInitialValues(repo, chart, version string) (map[st
 		return cached, nil
 	}
 
-	tgzData, err := loadOCIHelmChartBytes(repo, chart, version)
+	tgzData, err := loadOCIHelmChartBytes(repo, chart, version, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -91,34 +102,45 @@ func (r Repo) LoadOCIHelmChartInitialValues(repo, chart, version string) (map[st
 	return initial, nil
 }
 
-func loadOCIHelmChartBytes(repo, chart, version string) ([]byte, error) {
+func loadOCIHelmChartBytes(repo, chart, version string, creds *auth.Credentials) ([]byte, error) {
 	var err error
 	if !isValidVersion(version) {
-		version, err = getOCIStrictVersion(repo, chart, version)
+		version, err = getOCIStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	token, err := authorizeOCI(repo, chart, version)
+	authorization, err := authorizeOCI(repo, chart, version, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := fetchDigest(repo, chart, version, authorization)
 	if err != nil {
 		return nil, err
 	}
 
-	digest, err := fetchDigest(repo, chart, version, token)
+	keys, err := verificationKeys(creds)
 	if err != nil {
 		return nil, err
 	}
 
-	contentDigest, err := fetchContentDigest(repo, chart, digest, token)
+	if keys != nil {
+		if err := verifyOCISignature(repo, chart, digest, keys, authorization); err != nil {
+			return nil, err
+		}
+	}
+
+	contentDigest, err := fetchContentDigest(repo, chart, digest, authorization)
 	if err != nil {
 		return nil, err
 	}
 
-	return loadOCITar(repo, chart, contentDigest, token)
+	return loadOCITar(repo, chart, contentDigest, authorization)
 }
 
-func loadOCITar(repo, chart, digest, token string) ([]byte, error) {
+func loadOCITar(repo, chart, digest string, authorization *ociAuthorization) ([]byte, error) {
 	bURL, err := blobURL(repo, chart, digest)
 	if err != nil {
 		return nil, err
@@ -131,21 +153,23 @@ func loadOCITar(repo, chart, digest, token string) ([]byte, error) {
 
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Accept", "application/vnd.cncf.helm.config.v1+json, */*")
-	if len(token) != 0 {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
-	}
+	authorization.apply(req)
 
-	client := &http.Client{}
+	client := fetch.Client
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if err := registryResponseError(resp, authorization.creds); err != nil {
+		return nil, err
+	}
+
 	return ioutil.ReadAll(resp.Body)
 }
 
-func fetchContentDigest(repo, chart, digest, token string) (string, error) {
+func fetchContentDigest(repo, chart, digest string, authorization *ociAuthorization) (string, error) {
 	dURL, err := contentDigestURL(repo, chart, digest)
 	if err != nil {
 		return "", err
@@ -158,17 +182,19 @@ func fetchContentDigest(repo, chart, digest, token string) (string, error) {
 
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Accept", "application/vnd.oci.image.manifest.v1+json, */*")
-	if len(token) != 0 {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
-	}
+	authorization.apply(req)
 
-	client := &http.Client{}
+	client := fetch.Client
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
+	if err := registryResponseError(resp, authorization.creds); err != nil {
+		return "", err
+	}
+
 	responseBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
@@ -198,7 +224,7 @@ func fetchContentDigest(repo, chart, digest, token string) (string, error) {
 	return ct.Layers[0].Digest, nil
 }
 
-func fetchDigest(repo, chart, version, token string) (string, error) {
+func fetchDigest(repo, chart, version string, authorization *ociAuthorization) (string, error) {
 	dURL, err := digestURL(repo, chart, version)
 	if err != nil {
 		return "", err
@@ -211,22 +237,24 @@ func fetchDigest(repo, chart, version, token string) (string, error) {
 
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json, application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.manifest.v1+json, application/vnd.oci.image.index.v1+json, */*")
-	if len(token) != 0 {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
-	}
+	authorization.apply(req)
 
-	client := &http.Client{}
+	client := fetch.Client
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
+	if err := registryResponseError(resp, authorization.creds); err != nil {
+		return "", err
+	}
+
 	return resp.Header.Get("docker-content-digest"), nil
 }
 
-func getOCIStrictVersion(repo, chart, version string) (string, error) {
-	allTags, err := GetOCIChartTags(repo, chart)
+func getOCIStrictVersion(repo, chart, version string, creds *auth.Credentials) (string, error) {
+	allTags, err := GetOCIChartTags(repo, chart, creds)
 	if err != nil {
 		return "", err
 	}
@@ -234,8 +262,8 @@ func getOCIStrictVersion(repo, chart, version string) (string, error) {
 	return resolveSemver(version, allTags)
 }
 
-func GetOCIChartTags(repo, chart string) ([]string, error) {
-	token, err := authorizeOCITags(repo, chart)
+func GetOCIChartTags(repo, chart string, creds *auth.Credentials) ([]string, error) {
+	authorization, err := authorizeOCITags(repo, chart, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +273,7 @@ func GetOCIChartTags(repo, chart string) ([]string, error) {
 		return nil, err
 	}
 
-	client := &http.Client{}
+	client := fetch.Client
 	var allTags []string
 	for {
 		req, err := http.NewRequest(http.MethodGet, tURL.String(), nil)
@@ -254,9 +282,7 @@ func GetOCIChartTags(repo, chart string) ([]string, error) {
 		}
 
 		req.Header.Set("User-Agent", "Helm/3.13.3")
-		if len(token) != 0 {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
-		}
+		authorization.apply(req)
 
 		resp, err := client.Do(req)
 		if err != nil {
@@ -264,6 +290,10 @@ func GetOCIChartTags(repo, chart string) ([]string, error) {
 		}
 		defer resp.Body.Close()
 
+		if err := registryResponseError(resp, creds); err != nil {
+			return nil, err
+		}
+
 		responseBody, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			return nil, err
@@ -303,114 +333,92 @@ func GetOCIChartTags(repo, chart string) ([]string, error) {
 	return allTags, err
 }
 
-func authorizeOCI(repo, chart, version string) (string, error) {
-	// region head
+// ociAuthorization is the Authorization header of requests to an OCI registry
+type ociAuthorization struct {
+	header string
+	creds  *auth.Credentials
+}
+
+func (a *ociAuthorization) apply(req *http.Request) {
+	if len(a.header) != 0 {
+		req.Header.Set("Authorization", a.header)
+	}
+}
+
+func authorizeOCI(repo, chart, version string, creds *auth.Credentials) (*ociAuthorization, error) {
 	dURL, err := digestURL(repo, chart, version)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	client := &http.Client{}
-
 	req, err := http.NewRequest(http.MethodHead, dURL.String(), nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json, application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.manifest.v1+json, application/vnd.oci.image.index.v1+json, */*")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var authUrlRealm, service, scope string
-	if resp.StatusCode == http.StatusOK {
-		return "", nil
-	} else if resp.StatusCode == http.StatusUnauthorized {
-		authUrlRealm, service, scope = parseAuthenticateHeader(resp.Header.Get("WWW-Authenticate"))
-	}
-
-	if resp.StatusCode != http.StatusUnauthorized {
-		return "", errors.New(fmt.Sprintf("unexpected status code: %v", resp.StatusCode))
-	}
-
-	// endregion
-
-	// region get token
-
-	params := url.Values{}
-	params.Add("service", service)
-	params.Add("scope", scope)
-
-	authUrl := fmt.Sprintf("%v?%v", authUrlRealm, params.Encode())
-
-	req, err = http.NewRequest(http.MethodGet, authUrl, nil)
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return "", err
-	}
-
-	req.Header.Set("User-Agent", "Helm/3.13.3")
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	return authorizeOCIRequest(req, creds)
+}
 
-	resp, err = client.Do(req)
+func authorizeOCITags(repo, chart string, creds *auth.Credentials) (*ociAuthorization, error) {
+	tURL, err := tagsURL(repo, chart)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	responseBody, err := ioutil.ReadAll(resp.Body)
+	req, err := http.NewRequest(http.MethodGet, tURL.String(), nil)
 	if err != nil {
-		return "", err
-	}
-
-	var ar struct {
-		Token string `json:"token"`
-	}
-
-	if err := json.Unmarshal(responseBody, &ar); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return ar.Token, nil
-
-	// endregion
+	return authorizeOCIRequest(req, creds)
 }
 
-func authorizeOCITags(repo, chart string) (string, error) {
-	// region head
-	tURL, err := tagsURL(repo, chart)
-	if err != nil {
-		return "", err
-	}
+// authorizeOCIRequest sends the probe request to the registry and returns the
+// authorization for the following requests. Tokens from credentials are used
+// as they are; registries asking for a bearer token get one from their token
+// service, authenticated with basic auth credentials if set.
+func authorizeOCIRequest(probe *http.Request, creds *auth.Credentials) (*ociAuthorization, error) {
+	authorization := &ociAuthorization{creds: creds}
 
-	client := &http.Client{}
-
-	req, err := http.NewRequest(http.MethodGet, tURL.String(), nil)
-	if err != nil {
-		return "", err
+	if creds != nil && len(creds.Token) != 0 {
+		authorization.header = fmt.Sprintf("Bearer %v", creds.Token)
+		return authorization, nil
 	}
 
-	resp, err := client.Do(req)
+	// region probe
+	client := fetch.Client
+
+	resp, err := client.Do(probe)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var authUrlRealm, service, scope string
 	if resp.StatusCode == http.StatusOK {
-		return "", nil
-	} else if resp.StatusCode == http.StatusUnauthorized {
-		authUrlRealm, service, scope = parseAuthenticateHeader(resp.Header.Get("WWW-Authenticate"))
+		return authorization, nil
 	}
 
 	if resp.StatusCode != http.StatusUnauthorized {
-		return "", errors.New(fmt.Sprintf("unexpected status code: %v", resp.StatusCode))
+		return nil, errors.New(fmt.Sprintf("unexpected status code: %v", resp.StatusCode))
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+		if !creds.Basic() {
+			return nil, registryResponseError(resp, nil)
+		}
+
+		authorization.header = fmt.Sprintf(
+			"Basic %v",
+			base64.StdEncoding.EncodeToString([]byte(creds.Username+":"+creds.Password)),
+		)
+		return authorization, nil
 	}
 
+	authUrlRealm, service, scope := parseAuthenticateHeader(challenge)
 	// endregion
 
 	// region get token
@@ -420,36 +428,54 @@ func authorizeOCITags(repo, chart string) (string, error) {
 
 	authUrl := fmt.Sprintf("%v?%v", authUrlRealm, params.Encode())
 
-	req, err = http.NewRequest(http.MethodGet, authUrl, nil)
+	req, err := http.NewRequest(http.MethodGet, authUrl, nil)
 	if err != nil {
 		fmt.Println("Error creating request:", err)
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if creds.Basic() {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
 
 	resp, err = client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if err := registryResponseError(resp, creds); err != nil {
+		return nil, err
+	}
+
 	responseBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var ar struct {
-		Token string `json:"token"`
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
 	}
 
 	if err := json.Unmarshal(responseBody, &ar); err != nil {
-		return "", err
+		return nil, err
+	}
+
+	token := ar.Token
+	if len(token) == 0 {
+		token = ar.AccessToken
+	}
+
+	if len(token) == 0 {
+		return nil, errors.New(fmt.Sprintf("no token in response of %v", authUrlRealm))
 	}
 
-	return ar.Token, nil
+	authorization.header = fmt.Sprintf("Bearer %v", token)
 
+	return authorization, nil
 	// endregion
 }
 
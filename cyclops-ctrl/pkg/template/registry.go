@@ -0,0 +1,45 @@
+package template
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+)
+
+// registryResponseError returns an error for unsuccessful responses of Helm and
+// OCI registries, pointing to TemplateAuthRules if the request was not authorized
+func registryResponseError(resp *http.Response, creds *auth.Credentials) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	requestURL := resp.Request.URL.Redacted()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		if creds == nil {
+			return fmt.Errorf("%v requires authentication (%v); create a TemplateAuthRule for the repository", requestURL, resp.Status)
+		}
+
+		return fmt.Errorf("authentication to %v failed (%v); check the credentials of the TemplateAuthRule for the repository", requestURL, resp.Status)
+	}
+
+	return fmt.Errorf("request to %v failed with status: %v", requestURL, resp.Status)
+}
+
+// sameHost returns true if both URLs point to the same host. Credentials are
+// only sent to the repository host, like Helm does without --pass-credentials.
+func sameHost(repo, target string) bool {
+	repoURL, err := url.Parse(repo)
+	if err != nil {
+		return false
+	}
+
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+
+	return repoURL.Host == targetURL.Host
+}
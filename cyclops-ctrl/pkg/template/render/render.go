@@ -45,6 +45,10 @@ func (r *Renderer) HelmTemplate(module cyclopsv1alpha1.Module, moduleTemplate *m
 		return "", err
 	}
 
+	if err := r.resolveValuesFrom(module, values); err != nil {
+		return "", err
+	}
+
 	for _, dependency := range moduleTemplate.Dependencies {
 		if !evaluateDependencyCondition(dependency.Condition, values) {
 			continue
@@ -85,12 +89,9 @@ func (r *Renderer) HelmTemplate(module cyclopsv1alpha1.Module, moduleTemplate *m
 		HelmVersion: mapCapabilitiesHelmVersion(defaultCapabilites),
 	}
 
-	// TODO fix dependency validation
-	//if len(chart.Schema) != 0 {
-	//	if err := chartutil.ValidateAgainstSchema(chart, values); err != nil {
-	//		return "", err
-	//	}
-	//}
+	if err := ValidateValues(moduleTemplate, values); err != nil {
+		return "", err
+	}
 
 	out, err := engine.Render(chart, top)
 	if err != nil {
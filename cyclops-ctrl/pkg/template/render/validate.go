@@ -0,0 +1,144 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"github.com/xeipuuv/gojsonschema"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+)
+
+// FieldError is a value that does not match the template schema. Field is the
+// dot separated path of the value; values of dependencies are prefixed with the
+// dependency name.
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+// ValidationError is returned for values that do not match the schema of the
+// template or its dependencies
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		fields = append(fields, fmt.Sprintf("%v: %v", field.Field, field.Description))
+	}
+
+	return fmt.Sprintf("values do not match the template schema: %v", strings.Join(fields, "; "))
+}
+
+// ValidateValues validates the Module values, merged with its values sources,
+// against the template schema
+func (r *Renderer) ValidateValues(module cyclopsv1alpha1.Module, moduleTemplate *models.Template) error {
+	if moduleTemplate == nil {
+		return nil
+	}
+
+	values := make(map[string]interface{})
+	if err := json.Unmarshal(module.Spec.Values.Raw, &values); err != nil {
+		return err
+	}
+
+	if err := r.resolveValuesFrom(module, values); err != nil {
+		return err
+	}
+
+	return ValidateValues(moduleTemplate, values)
+}
+
+// ValidateValues validates values against the template schema, and values of
+// each enabled dependency against the dependency schema
+func ValidateValues(moduleTemplate *models.Template, values map[string]interface{}) error {
+	fieldErrors, err := validateSchema(moduleTemplate.RawSchema, values, "")
+	if err != nil {
+		return err
+	}
+
+	for _, dependency := range moduleTemplate.Dependencies {
+		if len(dependency.RawSchema) == 0 || !evaluateDependencyCondition(dependency.Condition, values) {
+			continue
+		}
+
+		// values of aliased dependencies are set under the alias, like in helm
+		name := dependency.Name
+		if len(dependency.Alias) != 0 {
+			name = dependency.Alias
+		} else if dependency.HelmChartMetadata != nil {
+			name = dependency.HelmChartMetadata.Name
+		}
+
+		dependencyValues := make(map[string]interface{})
+		if raw, ok := values[name]; ok && raw != nil {
+			dependencyValues, ok = raw.(map[string]interface{})
+			if !ok {
+				fieldErrors = append(fieldErrors, FieldError{Field: name, Description: "Invalid type. Expected: object"})
+				continue
+			}
+		}
+
+		dependencyErrors, err := validateSchema(dependency.RawSchema, dependencyValues, name)
+		if err != nil {
+			return fmt.Errorf("invalid schema of dependency %v: %w", name, err)
+		}
+
+		fieldErrors = append(fieldErrors, dependencyErrors...)
+	}
+
+	if len(fieldErrors) != 0 {
+		return &ValidationError{Fields: fieldErrors}
+	}
+
+	return nil
+}
+
+func validateSchema(schema []byte, values map[string]interface{}, prefix string) ([]FieldError, error) {
+	if len(schema) == 0 {
+		return nil, nil
+	}
+
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(valuesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	fieldErrors := make([]FieldError, 0, len(result.Errors()))
+	for _, resultErr := range result.Errors() {
+		path := make([]string, 0, 3)
+		if len(prefix) != 0 {
+			path = append(path, prefix)
+		}
+
+		if field := resultErr.Field(); field != gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			path = append(path, field)
+		}
+
+		// required errors are reported on the object missing the property
+		if property, ok := resultErr.Details()["property"].(string); ok && resultErr.Type() == "required" {
+			path = append(path, property)
+		}
+
+		field := strings.Join(path, ".")
+		if len(field) == 0 {
+			field = gojsonschema.STRING_ROOT_SCHEMA_PROPERTY
+		}
+
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:       field,
+			Description: resultErr.Description(),
+		})
+	}
+
+	return fieldErrors, nil
+}
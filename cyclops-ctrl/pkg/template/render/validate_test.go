@@ -0,0 +1,125 @@
+package render
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/internal/models/helm"
+)
+
+func TestRender(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test render")
+}
+
+const schema = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "pattern": "^[a-z]+$"},
+		"replicas": {"type": "integer", "minimum": 1},
+		"image": {
+			"type": "object",
+			"required": ["repository"],
+			"properties": {"repository": {"type": "string"}}
+		}
+	}
+}`
+
+const dependencySchema = `{
+	"type": "object",
+	"required": ["auth"],
+	"properties": {
+		"port": {"type": "integer", "maximum": 65535}
+	}
+}`
+
+func moduleTemplate() *models.Template {
+	return &models.Template{
+		RawSchema: []byte(schema),
+		Dependencies: []*models.Template{
+			{
+				Name:              "redis",
+				HelmChartMetadata: &helm.Metadata{Name: "redis"},
+				RawSchema:         []byte(dependencySchema),
+				Condition:         "redis.enabled",
+			},
+		},
+	}
+}
+
+var _ = Describe("ValidateValues", func() {
+	It("accepts valid values", func() {
+		err := ValidateValues(moduleTemplate(), map[string]interface{}{
+			"name":     "demo",
+			"replicas": 3,
+			"redis":    map[string]interface{}{"enabled": true, "auth": true, "port": 6379},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns the paths of invalid values", func() {
+		err := ValidateValues(moduleTemplate(), map[string]interface{}{
+			"name":     "Demo",
+			"replicas": 0,
+			"image":    map[string]interface{}{},
+			"redis":    map[string]interface{}{"enabled": true, "port": 70000},
+		})
+
+		var validationErr *ValidationError
+		Expect(err).To(BeAssignableToTypeOf(validationErr))
+
+		validationErr = err.(*ValidationError)
+		fields := make([]string, 0, len(validationErr.Fields))
+		for _, field := range validationErr.Fields {
+			fields = append(fields, field.Field)
+		}
+
+		Expect(fields).To(ConsistOf("name", "replicas", "image.repository", "redis.auth", "redis.port"))
+	})
+
+	It("skips disabled dependencies", func() {
+		err := ValidateValues(moduleTemplate(), map[string]interface{}{
+			"name":  "demo",
+			"redis": map[string]interface{}{"enabled": false},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("validates missing dependency values as empty", func() {
+		template := moduleTemplate()
+		template.Dependencies[0].Condition = ""
+
+		err := ValidateValues(template, map[string]interface{}{"name": "demo"})
+		Expect(err).To(MatchError(ContainSubstring("redis.auth")))
+	})
+	It("validates values of aliased dependencies under the alias", func() {
+		template := moduleTemplate()
+		template.Dependencies[0].Alias = "cache"
+		template.Dependencies[0].Condition = "cache.enabled"
+
+		err := ValidateValues(template, map[string]interface{}{
+			"name":  "demo",
+			"cache": map[string]interface{}{"enabled": true, "port": 70000},
+		})
+
+		var validationErr *ValidationError
+		Expect(err).To(BeAssignableToTypeOf(validationErr))
+
+		validationErr = err.(*ValidationError)
+		fields := make([]string, 0, len(validationErr.Fields))
+		for _, field := range validationErr.Fields {
+			fields = append(fields, field.Field)
+		}
+
+		Expect(fields).To(ConsistOf("cache.auth", "cache.port"))
+
+		Expect(ValidateValues(template, map[string]interface{}{
+			"name":  "demo",
+			"cache": map[string]interface{}{"enabled": true, "auth": true},
+		})).To(Succeed())
+	})
+})
@@ -0,0 +1,151 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/yaml"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// resolveValuesFrom merges Module values sources into values in the order they
+// are listed in the Module spec
+func (r *Renderer) resolveValuesFrom(module cyclopsv1alpha1.Module, values map[string]interface{}) error {
+	for _, ref := range module.Spec.ValuesFrom {
+		data, err := r.k8sClient.GetValuesSource(ref.Kind, ref.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) && ref.Optional {
+				continue
+			}
+
+			return fmt.Errorf("failed to get values from %v %v: %w", ref.Kind, ref.Name, err)
+		}
+
+		if err := mergeValuesSource(values, ref, data); err != nil {
+			return fmt.Errorf("failed to merge values from %v %v: %w", ref.Kind, ref.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func mergeValuesSource(values map[string]interface{}, ref cyclopsv1alpha1.ValuesReference, data map[string]string) error {
+	path := splitValuesPath(ref.TargetPath)
+
+	if len(ref.Key) == 0 {
+		source := make(map[string]interface{}, len(data))
+		for key, value := range data {
+			source[key] = value
+		}
+
+		return setValue(values, path, source)
+	}
+
+	value, ok := data[ref.Key]
+	if !ok {
+		if ref.Optional {
+			return nil
+		}
+
+		return fmt.Errorf("key %v not found", ref.Key)
+	}
+
+	if len(path) != 0 {
+		return setValue(values, path, value)
+	}
+
+	source := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(value), &source); err != nil {
+		return fmt.Errorf("key %v is not a YAML object: %w", ref.Key, err)
+	}
+
+	return setValue(values, path, source)
+}
+
+// setValue sets the value at the given path, creating missing objects on the
+// way. Objects are merged with the existing object at the path.
+func setValue(values map[string]interface{}, path []string, value interface{}) error {
+	if len(path) == 0 {
+		source, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("only objects can be merged into values root")
+		}
+
+		mergeMaps(values, source)
+		return nil
+	}
+
+	current := values
+	for i, field := range path[:len(path)-1] {
+		next, ok := current[field]
+		if !ok || next == nil {
+			nextMap := make(map[string]interface{})
+			current[field] = nextMap
+			current = nextMap
+			continue
+		}
+
+		nextMap, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("value at %v is not an object", strings.Join(path[:i+1], "."))
+		}
+		current = nextMap
+	}
+
+	last := path[len(path)-1]
+
+	existing, existingIsMap := current[last].(map[string]interface{})
+	source, sourceIsMap := value.(map[string]interface{})
+	if existingIsMap && sourceIsMap {
+		mergeMaps(existing, source)
+		return nil
+	}
+
+	current[last] = value
+	return nil
+}
+
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		existing, existingIsMap := dst[key].(map[string]interface{})
+		source, sourceIsMap := value.(map[string]interface{})
+
+		if existingIsMap && sourceIsMap {
+			mergeMaps(existing, source)
+			continue
+		}
+
+		dst[key] = value
+	}
+}
+
+// splitValuesPath splits a dot separated values path into keys. Like in helm
+// --set, a dot or backslash in a key is escaped with a backslash.
+func splitValuesPath(path string) []string {
+	path = strings.TrimSpace(path)
+	if len(path) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0)
+	var key strings.Builder
+	escaped := false
+	for _, c := range path {
+		switch {
+		case escaped:
+			key.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteRune(c)
+		}
+	}
+
+	return append(keys, key.String())
+}
@@ -0,0 +1,153 @@
+package render
+
+import (
+	json "github.com/json-iterator/go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	k8smocks "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+var _ = Describe("Values sources", func() {
+	DescribeTable("merges values sources at the target path",
+		func(values string, ref cyclopsv1alpha1.ValuesReference, data map[string]string, expected string) {
+			merged := make(map[string]interface{})
+			Expect(json.Unmarshal([]byte(values), &merged)).To(Succeed())
+
+			Expect(mergeValuesSource(merged, ref, data)).To(Succeed())
+			Expect(json.Marshal(merged)).To(MatchJSON(expected))
+		},
+		Entry("all keys at the target path",
+			`{"name":"web"}`,
+			cyclopsv1alpha1.ValuesReference{TargetPath: "env"},
+			map[string]string{"LOG_LEVEL": "debug", "PORT": "8080"},
+			`{"name":"web","env":{"LOG_LEVEL":"debug","PORT":"8080"}}`,
+		),
+		Entry("all keys merged with an existing object",
+			`{"env":{"LOG_LEVEL":"info","REGION":"eu"}}`,
+			cyclopsv1alpha1.ValuesReference{TargetPath: "env"},
+			map[string]string{"LOG_LEVEL": "debug"},
+			`{"env":{"LOG_LEVEL":"debug","REGION":"eu"}}`,
+		),
+		Entry("single key at a nested target path",
+			`{"database":{"host":"postgres"}}`,
+			cyclopsv1alpha1.ValuesReference{Key: "password", TargetPath: "database.auth.password"},
+			map[string]string{"password": "s3cr3t", "username": "admin"},
+			`{"database":{"host":"postgres","auth":{"password":"s3cr3t"}}}`,
+		),
+		Entry("single key at a target path with escaped dots",
+			`{"podAnnotations":{"team":"payments"}}`,
+			cyclopsv1alpha1.ValuesReference{Key: "scrape", TargetPath: `podAnnotations.prometheus\.io/scrape`},
+			map[string]string{"scrape": "true"},
+			`{"podAnnotations":{"team":"payments","prometheus.io/scrape":"true"}}`,
+		),
+		Entry("single key parsed as YAML into the values root",
+			`{"name":"web","resources":{"cpu":"100m","memory":"128Mi"}}`,
+			cyclopsv1alpha1.ValuesReference{Key: "values.yaml"},
+			map[string]string{"values.yaml": "replicas: 3\nresources:\n  memory: 256Mi\n"},
+			`{"name":"web","replicas":3,"resources":{"cpu":"100m","memory":"256Mi"}}`,
+		),
+		Entry("missing optional key",
+			`{"name":"web"}`,
+			cyclopsv1alpha1.ValuesReference{Key: "values.yaml", Optional: true},
+			map[string]string{},
+			`{"name":"web"}`,
+		),
+	)
+
+	DescribeTable("fails to merge invalid values sources",
+		func(values string, ref cyclopsv1alpha1.ValuesReference, data map[string]string, message string) {
+			merged := make(map[string]interface{})
+			Expect(json.Unmarshal([]byte(values), &merged)).To(Succeed())
+
+			Expect(mergeValuesSource(merged, ref, data)).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("missing key",
+			`{}`,
+			cyclopsv1alpha1.ValuesReference{Key: "values.yaml"},
+			map[string]string{},
+			"key values.yaml not found",
+		),
+		Entry("key that is not a YAML object",
+			`{}`,
+			cyclopsv1alpha1.ValuesReference{Key: "values.yaml"},
+			map[string]string{"values.yaml": "- replicas"},
+			"key values.yaml is not a YAML object",
+		),
+		Entry("target path through a value that is not an object",
+			`{"database":"postgres"}`,
+			cyclopsv1alpha1.ValuesReference{Key: "password", TargetPath: "database.password"},
+			map[string]string{"password": "s3cr3t"},
+			"value at database is not an object",
+		),
+	)
+
+	DescribeTable("splits values paths",
+		func(path string, expected []string) {
+			Expect(splitValuesPath(path)).To(Equal(expected))
+		},
+		Entry("empty path", " ", []string(nil)),
+		Entry("dot separated path", "database.auth.password", []string{"database", "auth", "password"}),
+		Entry("escaped dots", `ingress.annotations.kubernetes\.io/ingress\.class`, []string{"ingress", "annotations", "kubernetes.io/ingress.class"}),
+		Entry("escaped backslash", `paths.C:\\.data`, []string{"paths", `C:\`, "data"}),
+	)
+
+	Describe("resolveValuesFrom", func() {
+		var k8sClient *k8smocks.IKubernetesClient
+		var renderer *Renderer
+
+		module := func(refs ...cyclopsv1alpha1.ValuesReference) cyclopsv1alpha1.Module {
+			return cyclopsv1alpha1.Module{
+				Spec: cyclopsv1alpha1.ModuleSpec{
+					Values:     apiextensionsv1.JSON{Raw: []byte(`{}`)},
+					ValuesFrom: refs,
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			k8sClient = &k8smocks.IKubernetesClient{}
+			renderer = NewRenderer(k8sClient)
+		})
+
+		It("merges sources in the order they are listed", func() {
+			k8sClient.On("GetValuesSource", cyclopsv1alpha1.ValuesFromKindConfigMap, "defaults").
+				Return(map[string]string{"values.yaml": "replicas: 1\nimage: nginx\n"}, nil)
+			k8sClient.On("GetValuesSource", cyclopsv1alpha1.ValuesFromKindSecret, "overrides").
+				Return(map[string]string{"values.yaml": "replicas: 3\n"}, nil)
+
+			values := map[string]interface{}{}
+			Expect(renderer.resolveValuesFrom(module(
+				cyclopsv1alpha1.ValuesReference{Kind: cyclopsv1alpha1.ValuesFromKindConfigMap, Name: "defaults", Key: "values.yaml"},
+				cyclopsv1alpha1.ValuesReference{Kind: cyclopsv1alpha1.ValuesFromKindSecret, Name: "overrides", Key: "values.yaml"},
+			), values)).To(Succeed())
+
+			Expect(json.Marshal(values)).To(MatchJSON(`{"replicas":3,"image":"nginx"}`))
+		})
+
+		It("skips missing optional sources", func() {
+			k8sClient.On("GetValuesSource", cyclopsv1alpha1.ValuesFromKindSecret, "overrides").
+				Return(nil, apierrors.NewNotFound(k8sschema.GroupResource{Resource: "secrets"}, "overrides"))
+
+			values := map[string]interface{}{}
+			Expect(renderer.resolveValuesFrom(module(
+				cyclopsv1alpha1.ValuesReference{Kind: cyclopsv1alpha1.ValuesFromKindSecret, Name: "overrides", Optional: true},
+			), values)).To(Succeed())
+			Expect(values).To(BeEmpty())
+		})
+
+		It("fails on missing sources", func() {
+			k8sClient.On("GetValuesSource", cyclopsv1alpha1.ValuesFromKindSecret, "overrides").
+				Return(nil, apierrors.NewNotFound(k8sschema.GroupResource{Resource: "secrets"}, "overrides"))
+
+			err := renderer.resolveValuesFrom(module(
+				cyclopsv1alpha1.ValuesReference{Kind: cyclopsv1alpha1.ValuesFromKindSecret, Name: "overrides"},
+			), map[string]interface{}{})
+			Expect(err).To(MatchError(ContainSubstring("failed to get values from Secret overrides")))
+		})
+	})
+})
@@ -2,6 +2,11 @@ package template
 
 import (
 	"fmt"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"golang.org/x/sync/singleflight"
+
 	gitproviders2 "github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/gitproviders"
 
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
@@ -31,12 +36,22 @@ type ITemplateRepo interface {
 		source cyclopsv1alpha1.TemplateSourceType,
 	) (map[string]interface{}, error)
 	GetTemplateRevisions(repo, path string) ([]string, error)
+	GetTemplateVersions(repo, path string, source cyclopsv1alpha1.TemplateSourceType) ([]TemplateVersion, error)
+	AssumeTemplateSourceType(repo string) (cyclopsv1alpha1.TemplateSourceType, error)
+	InvalidateTemplate(repo, path, version string)
 	ReturnCache() *ristretto.Cache
 }
 
 type Repo struct {
 	credResolver auth.TemplatesResolver
 	cache        templateCache
+	fetches      *singleflight.Group
+	observer     FetchObserver
+}
+
+// FetchObserver records the duration and failures of template fetches
+type FetchObserver interface {
+	ObserveTemplateFetch(source string, duration time.Duration, err error)
 }
 
 type templateCache interface {
@@ -44,13 +59,16 @@ type templateCache interface {
 	SetTemplate(repo, path, version, sourceType string, template *models.Template)
 	GetTemplateInitialValues(repo, path, version, sourceType string) (map[string]interface{}, bool)
 	SetTemplateInitialValues(repo, path, version, sourceType string, values map[string]interface{})
+	Invalidate(repo, path, version, sourceType string)
 	ReturnCache() *ristretto.Cache
 }
 
-func NewRepo(credResolver auth.TemplatesResolver, tc templateCache) ITemplateRepo {
+func NewRepo(credResolver auth.TemplatesResolver, tc templateCache, observer FetchObserver) ITemplateRepo {
 	return &Repo{
 		credResolver: credResolver,
 		cache:        tc,
+		fetches:      &singleflight.Group{},
+		observer:     observer,
 	}
 }
 
@@ -63,13 +81,31 @@ func (r Repo) GetTemplate(
 ) (*models.Template, error) {
 	var err error
 	if len(source) == 0 {
-		source, err = r.assumeTemplateSourceType(repo)
+		source, err = r.AssumeTemplateSourceType(repo)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return r.getTemplate(repo, path, version, resolvedVersion, source)
+	// concurrent requests for the same template share a single fetch
+	key := fmt.Sprintf("template:%v:%v/%v@%v:%v", source, repo, path, version, resolvedVersion)
+	result, err, shared := r.fetches.Do(key, func() (interface{}, error) {
+		start := time.Now()
+		template, err := r.getTemplate(repo, path, version, resolvedVersion, source)
+		r.observeFetch(source, start, err)
+		return template, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	template := result.(*models.Template)
+	if !shared {
+		return template, nil
+	}
+
+	// callers modify the returned template, so each gets its own copy
+	return copyTemplate(template)
 }
 
 func (r Repo) getTemplate(
@@ -99,13 +135,24 @@ func (r Repo) GetTemplateInitialValues(
 ) (map[string]interface{}, error) {
 	var err error
 	if len(source) == 0 {
-		source, err = r.assumeTemplateSourceType(repo)
+		source, err = r.AssumeTemplateSourceType(repo)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return r.getTemplateInitialValues(repo, path, version, source)
+	key := fmt.Sprintf("values:%v:%v/%v@%v", source, repo, path, version)
+	result, err, _ := r.fetches.Do(key, func() (interface{}, error) {
+		start := time.Now()
+		values, err := r.getTemplateInitialValues(repo, path, version, source)
+		r.observeFetch(source, start, err)
+		return values, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(map[string]interface{}), nil
 }
 
 func (r Repo) getTemplateInitialValues(
@@ -126,6 +173,28 @@ func (r Repo) getTemplateInitialValues(
 	}
 }
 
+func (r Repo) observeFetch(source cyclopsv1alpha1.TemplateSourceType, start time.Time, err error) {
+	if r.observer == nil {
+		return
+	}
+
+	r.observer.ObserveTemplateFetch(string(source), time.Since(start), err)
+}
+
+func copyTemplate(template *models.Template) (*models.Template, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied *models.Template
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+
+	return copied, nil
+}
+
 func (r Repo) loadDependencies(metadata *helm.Metadata) ([]*models.Template, error) {
 	deps := make([]*models.Template, 0)
 	for _, dependency := range metadata.Dependencies {
@@ -139,6 +208,7 @@ func (r Repo) loadDependencies(metadata *helm.Metadata) ([]*models.Template, err
 		}
 
 		dep.Condition = dependency.Condition
+		dep.Alias = dependency.Alias
 
 		deps = append(deps, dep)
 	}
@@ -164,12 +234,19 @@ func (r Repo) loadDependenciesInitialValues(metadata *helm.Metadata) (map[string
 	return initialValues, nil
 }
 
-func (r Repo) assumeTemplateSourceType(repo string) (cyclopsv1alpha1.TemplateSourceType, error) {
+// AssumeTemplateSourceType returns the source type of templates referenced
+// without one
+func (r Repo) AssumeTemplateSourceType(repo string) (cyclopsv1alpha1.TemplateSourceType, error) {
 	if registry.IsOCI(repo) {
 		return cyclopsv1alpha1.TemplateSourceTypeOCI, nil
 	}
 
-	isHelmRepo, err := IsHelmRepo(repo)
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return "", err
+	}
+
+	isHelmRepo, err := IsHelmRepo(repo, creds)
 	if err != nil {
 		return "", err
 	}
@@ -182,20 +259,40 @@ func (r Repo) assumeTemplateSourceType(repo string) (cyclopsv1alpha1.TemplateSou
 }
 
 func (r Repo) GetTemplateRevisions(repo, path string) ([]string, error) {
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	if registry.IsOCI(repo) {
-		return GetOCIChartTags(repo, path)
+		return GetOCIChartTags(repo, path, creds)
 	}
 
 	if !gitproviders2.IsGitHubSource(repo) {
 		return nil, nil
 	}
 
+	return r.listRemoteRefs(repo, creds)
+}
+
+// InvalidateTemplate removes the template version of any source type from the
+// cache, so it is fetched again
+func (r Repo) InvalidateTemplate(repo, path, version string) {
 	creds, err := r.credResolver.RepoAuthCredentials(repo)
 	if err != nil {
-		return nil, err
+		creds = nil
 	}
 
-	return r.listRemoteRefs(repo, creds)
+	for _, source := range []cyclopsv1alpha1.TemplateSourceType{
+		cyclopsv1alpha1.TemplateSourceTypeGit,
+		cyclopsv1alpha1.TemplateSourceTypeHelm,
+		cyclopsv1alpha1.TemplateSourceTypeOCI,
+	} {
+		r.cache.Invalidate(repo, path, version, string(source))
+		if creds.Verifies() {
+			r.cache.Invalidate(repo, path, version, cacheSourceType(source, creds))
+		}
+	}
 }
 
 func (r Repo) ReturnCache() *ristretto.Cache {
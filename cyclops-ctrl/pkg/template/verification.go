@@ -0,0 +1,255 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	json "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/fetch"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/gitproviders"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/template/verify"
+)
+
+// VerificationError is returned for templates that are not signed by a key
+// trusted by their TemplateAuthRule
+type VerificationError struct {
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("template verification failed: %v", e.Err)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+func verificationKeys(creds *auth.Credentials) (*verify.Keys, error) {
+	if !creds.Verifies() {
+		return nil, nil
+	}
+
+	keys, err := verify.ParseKeys(creds.VerificationKeys)
+	if err != nil {
+		return nil, &VerificationError{Err: errors.Wrap(err, "invalid verification public keys")}
+	}
+
+	return keys, nil
+}
+
+// cacheSourceType separates cached templates verified with different keys
+// from each other and from templates that were not verified
+func cacheSourceType(source cyclopsv1alpha1.TemplateSourceType, creds *auth.Credentials) string {
+	if !creds.Verifies() {
+		return string(source)
+	}
+
+	sum := sha256.Sum256(creds.VerificationKeys)
+	return fmt.Sprintf("%v+verified-%v", source, hex.EncodeToString(sum[:8]))
+}
+
+// verifyGitTemplate verifies the signature of the tag the version references,
+// or of the commit if the tag is not signed
+func verifyGitTemplate(repoURL, version, commitSHA string, creds *auth.Credentials) error {
+	keys, err := verificationKeys(creds)
+	if err != nil || keys == nil {
+		return err
+	}
+
+	repo, err := initVerificationRepository(repoURL)
+	if err != nil {
+		return err
+	}
+
+	if len(version) != 0 && version != commitSHA {
+		tagRef := plumbing.NewTagReferenceName(version)
+		err := fetchRef(repo, config.RefSpec(fmt.Sprintf("+%v:%v", tagRef, tagRef)), creds)
+		if err != nil && !errors.Is(err, git.NoMatchingRefSpecError{}) {
+			return errors.Wrapf(err, "failed to fetch tag %v from repo %v", version, repoURL)
+		}
+
+		if ref, err := repo.Tag(version); err == nil {
+			tag, err := repo.TagObject(ref.Hash())
+			if err == nil && len(tag.PGPSignature) != 0 && tag.Target.String() == commitSHA {
+				payload, err := encodeWithoutSignature(tag.EncodeWithoutSignature)
+				if err != nil {
+					return err
+				}
+
+				if err := keys.Git(tag.PGPSignature, payload); err != nil {
+					return &VerificationError{Err: errors.Wrapf(err, "tag %v", version)}
+				}
+
+				return nil
+			}
+		}
+	}
+
+	commitRef := config.RefSpec(fmt.Sprintf("+%v:refs/heads/%v", commitSHA, commitSHA))
+	if err := fetchRef(repo, commitRef, creds); err != nil {
+		return errors.Wrapf(err, "failed to fetch commit %v from repo %v", commitSHA, repoURL)
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(commitSHA))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read commit %v", commitSHA)
+	}
+
+	payload, err := encodeWithoutSignature(commit.EncodeWithoutSignature)
+	if err != nil {
+		return err
+	}
+
+	if err := keys.Git(commit.PGPSignature, payload); err != nil {
+		return &VerificationError{Err: errors.Wrapf(err, "commit %v", commitSHA)}
+	}
+
+	return nil
+}
+
+// initVerificationRepository creates an empty in-memory repository, so only
+// the objects needed to verify the signature are fetched into it
+func initVerificationRepository(repoURL string) (*git.Repository, error) {
+	if gitproviders.IsAzureRepo(repoURL) {
+		transport.UnsupportedCapabilities = []capability.Capability{
+			capability.ThinPack,
+		}
+	}
+
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repoURL},
+	}); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// fetchRef fetches a single ref without its history
+func fetchRef(repo *git.Repository, refSpec config.RefSpec, creds *auth.Credentials) error {
+	err := repo.Fetch(&git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{refSpec},
+		Depth:      1,
+		Tags:       git.NoTags,
+		Auth:       creds.GitAuth(),
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+
+	return err
+}
+
+func encodeWithoutSignature(encode func(o plumbing.EncodedObject) error) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := encode(encoded); err != nil {
+		return nil, err
+	}
+
+	reader, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// verifyHelmProvenance verifies the chart against the provenance file served
+// next to it
+func verifyHelmProvenance(tgzURL string, tgzData []byte, keys *verify.Keys, creds *auth.Credentials) error {
+	provenance, err := downloadFile(tgzURL+".prov", creds)
+	if err != nil {
+		return &VerificationError{Err: errors.Wrap(err, "failed to download chart provenance file")}
+	}
+
+	if err := keys.HelmProvenance(provenance, tgzData, path.Base(tgzURL)); err != nil {
+		return &VerificationError{Err: err}
+	}
+
+	return nil
+}
+
+// verifyOCISignature verifies the cosign signature of the chart manifest. Cosign
+// stores signatures under the sha256-<digest>.sig tag of the repository.
+func verifyOCISignature(repo, chart, digest string, keys *verify.Keys, authorization *ociAuthorization) error {
+	sURL, err := contentDigestURL(repo, chart, strings.Replace(digest, ":", "-", 1)+".sig")
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, sURL.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("User-Agent", "Helm/3.13.3")
+	req.Header.Set("Accept", "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json")
+	authorization.apply(req)
+
+	resp, err := fetch.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &VerificationError{Err: errors.Errorf("no cosign signature found for %v", digest)}
+	}
+
+	if err := registryResponseError(resp, authorization.creds); err != nil {
+		return err
+	}
+
+	var manifest struct {
+		Layers []struct {
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"layers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return err
+	}
+
+	verificationErr := errors.Errorf("no cosign signature found for %v", digest)
+	for _, layer := range manifest.Layers {
+		signature, ok := layer.Annotations[verify.CosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		payload, err := loadOCITar(repo, chart, layer.Digest, authorization)
+		if err != nil {
+			return err
+		}
+
+		verificationErr = keys.Cosign(payload, signature, digest)
+		if verificationErr == nil {
+			return nil
+		}
+	}
+
+	return &VerificationError{Err: verificationErr}
+}
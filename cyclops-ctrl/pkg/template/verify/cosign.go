@@ -0,0 +1,68 @@
+package verify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// CosignSignatureAnnotation holds the signature of cosign signature layers
+const CosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// cosignPayload is the simple signing payload cosign signs
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Cosign verifies a cosign signature of the manifest digest. The payload is the
+// signature layer and the signature is taken from its annotation.
+func (k *Keys) Cosign(payload []byte, signature, digest string) error {
+	if len(k.cosign) == 0 {
+		return errors.New("charts are signed with cosign keys, but no cosign public keys are trusted")
+	}
+
+	var p cosignPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return errors.Wrap(err, "invalid cosign payload")
+	}
+
+	if p.Critical.Image.DockerManifestDigest != digest {
+		return errors.Errorf("cosign signature is for digest %v, not %v", p.Critical.Image.DockerManifestDigest, digest)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid cosign signature")
+	}
+
+	sum := sha256.Sum256(payload)
+
+	for _, key := range k.cosign {
+		switch publicKey := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(publicKey, sum[:], sig) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, sum[:], sig) == nil {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(publicKey, payload, sig) {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("cosign signature does not match any trusted key")
+}
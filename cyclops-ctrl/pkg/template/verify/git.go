@@ -0,0 +1,127 @@
+package verify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshSignatureMagic     = "SSHSIG"
+	sshSignatureNamespace = "git"
+)
+
+// Git verifies the signature of a git commit or tag. The payload is the object
+// encoded without its signature.
+func (k *Keys) Git(signature string, payload []byte) error {
+	switch {
+	case len(signature) == 0:
+		return errors.New("object is not signed")
+	case strings.HasPrefix(signature, "-----BEGIN PGP SIGNATURE-----"):
+		if len(k.pgp) == 0 {
+			return errors.New("object is signed with a pgp key, but no pgp public keys are trusted")
+		}
+
+		if _, err := openpgp.CheckArmoredDetachedSignature(k.pgp, bytes.NewReader(payload), strings.NewReader(signature), nil); err != nil {
+			return errors.Wrap(err, "invalid pgp signature")
+		}
+
+		return nil
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		if len(k.ssh) == 0 {
+			return errors.New("object is signed with an ssh key, but no ssh public keys are trusted")
+		}
+
+		return k.verifySSHSignature(signature, payload)
+	}
+
+	return errors.New("unsupported signature format")
+}
+
+// verifySSHSignature verifies signatures in the format of ssh-keygen -Y sign
+func (k *Keys) verifySSHSignature(signature string, payload []byte) error {
+	block, _ := pem.Decode([]byte(signature))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return errors.New("failed to decode ssh signature")
+	}
+
+	if !bytes.HasPrefix(block.Bytes, []byte(sshSignatureMagic)) {
+		return errors.New("invalid ssh signature")
+	}
+
+	var sig struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(block.Bytes[len(sshSignatureMagic):], &sig); err != nil {
+		return errors.Wrap(err, "invalid ssh signature")
+	}
+
+	if sig.Namespace != sshSignatureNamespace {
+		return errors.Errorf("ssh signature namespace %v is not %v", sig.Namespace, sshSignatureNamespace)
+	}
+
+	publicKey, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "invalid ssh signature public key")
+	}
+
+	if !k.trustsSSHKey(publicKey) {
+		return errors.Errorf("object is signed with untrusted ssh key %v", ssh.FingerprintSHA256(publicKey))
+	}
+
+	var hash []byte
+	switch sig.HashAlgorithm {
+	case "sha256":
+		sum := sha256.Sum256(payload)
+		hash = sum[:]
+	case "sha512":
+		sum := sha512.Sum512(payload)
+		hash = sum[:]
+	default:
+		return errors.Errorf("unsupported ssh signature hash algorithm %v", sig.HashAlgorithm)
+	}
+
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{
+		Namespace:     sig.Namespace,
+		Reserved:      sig.Reserved,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          hash,
+	})...)
+
+	var sshSig ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &sshSig); err != nil {
+		return errors.Wrap(err, "invalid ssh signature")
+	}
+
+	if err := publicKey.Verify(signed, &sshSig); err != nil {
+		return errors.Wrap(err, "invalid ssh signature")
+	}
+
+	return nil
+}
+
+func (k *Keys) trustsSSHKey(key ssh.PublicKey) bool {
+	for _, trusted := range k.ssh {
+		if bytes.Equal(trusted.Marshal(), key.Marshal()) {
+			return true
+		}
+	}
+
+	return false
+}
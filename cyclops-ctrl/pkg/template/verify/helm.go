@@ -0,0 +1,53 @@
+package verify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck // helm provenance verifies with x/crypto keyrings
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+// HelmProvenance verifies the provenance file of a packaged Helm chart, as
+// created by helm package --sign, the same way helm verify does
+func (k *Keys) HelmProvenance(provenanceFile, chart []byte, chartName string) error {
+	if len(k.pgpArmored) == 0 {
+		return errors.New("provenance files are signed with pgp keys, but no pgp public keys are trusted")
+	}
+
+	var keyring openpgp.EntityList
+	for _, armored := range k.pgpArmored {
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armored))
+		if err != nil {
+			return errors.Wrap(err, "failed to parse pgp public key")
+		}
+
+		keyring = append(keyring, entities...)
+	}
+
+	// the provenance file lists digests by chart file name, and the signatory
+	// reads both files from disk
+	dir, err := os.MkdirTemp("", "cyclops-provenance-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	chartPath := filepath.Join(dir, filepath.Base(chartName))
+	if err := os.WriteFile(chartPath, chart, 0600); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(chartPath+".prov", provenanceFile, 0600); err != nil {
+		return err
+	}
+
+	signatory := &provenance.Signatory{KeyRing: keyring}
+	if _, err := signatory.Verify(chartPath, chartPath+".prov"); err != nil {
+		return errors.Wrap(err, "invalid provenance file")
+	}
+
+	return nil
+}
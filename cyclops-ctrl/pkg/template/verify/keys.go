@@ -0,0 +1,111 @@
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// Keys trusted to sign templates
+type Keys struct {
+	pgp        openpgp.EntityList
+	pgpArmored [][]byte
+	ssh        []ssh.PublicKey
+	cosign     []crypto.PublicKey
+}
+
+// ParseKeys reads armored PGP public keys, PEM encoded public keys used by
+// cosign and SSH public keys in the authorized_keys format
+func ParseKeys(data []byte) (*Keys, error) {
+	keys := &Keys{}
+
+	var block strings.Builder
+	blockType := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if len(blockType) == 0 {
+			if strings.HasPrefix(line, "-----BEGIN ") {
+				blockType = strings.TrimSuffix(strings.TrimPrefix(line, "-----BEGIN "), "-----")
+				block.Reset()
+				block.WriteString(line + "\n")
+				continue
+			}
+
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse ssh public key")
+			}
+
+			keys.ssh = append(keys.ssh, key)
+			continue
+		}
+
+		block.WriteString(line + "\n")
+		if line != fmt.Sprintf("-----END %v-----", blockType) {
+			continue
+		}
+
+		if err := keys.addBlock(blockType, block.String()); err != nil {
+			return nil, err
+		}
+		blockType = ""
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(blockType) != 0 {
+		return nil, fmt.Errorf("unterminated %v block", blockType)
+	}
+
+	if len(keys.pgp) == 0 && len(keys.ssh) == 0 && len(keys.cosign) == 0 {
+		return nil, errors.New("no public keys found")
+	}
+
+	return keys, nil
+}
+
+func (k *Keys) addBlock(blockType, block string) error {
+	switch blockType {
+	case "PGP PUBLIC KEY BLOCK":
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(block))
+		if err != nil {
+			return errors.Wrap(err, "failed to parse pgp public key")
+		}
+
+		k.pgp = append(k.pgp, entities...)
+		k.pgpArmored = append(k.pgpArmored, []byte(block))
+	case "PUBLIC KEY":
+		decoded, _ := pem.Decode([]byte(block))
+		if decoded == nil {
+			return errors.New("failed to decode public key")
+		}
+
+		key, err := x509.ParsePKIXPublicKey(decoded.Bytes)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse public key")
+		}
+
+		k.cosign = append(k.cosign, key)
+	default:
+		return fmt.Errorf("unsupported key block %v", blockType)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package verify
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"golang.org/x/crypto/ssh"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestVerify(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test template verification")
+}
+
+const manifestDigest = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var payload = []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor cyclops <cyclops@cyclops-ui.com> 1700000000 +0000\n\ntemplates\n")
+
+func pgpEntity() *openpgp.Entity {
+	entity, err := openpgp.NewEntity("cyclops", "", "cyclops@cyclops-ui.com", nil)
+	Expect(err).ToNot(HaveOccurred())
+	return entity
+}
+
+func armoredPublicKey(entity *openpgp.Entity) string {
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(entity.Serialize(w)).To(Succeed())
+	Expect(w.Close()).To(Succeed())
+	return buf.String()
+}
+
+func pgpSignature(entity *openpgp.Entity, data []byte) string {
+	var buf bytes.Buffer
+	Expect(openpgp.ArmoredDetachSign(&buf, entity, bytes.NewReader(data), nil)).To(Succeed())
+	return buf.String()
+}
+
+// sshSignature signs the data like ssh-keygen -Y sign -n git
+func sshSignature(signer ssh.Signer, data []byte) string {
+	hash := sha512.Sum512(data)
+
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSignatureNamespace, "", "sha512", hash[:]})...)
+
+	sig, err := signer.Sign(rand.Reader, signed)
+	Expect(err).ToNot(HaveOccurred())
+
+	blob := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, signer.PublicKey().Marshal(), sshSignatureNamespace, "", "sha512", ssh.Marshal(sig)})...)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob}))
+}
+
+func sshSigner() ssh.Signer {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	Expect(err).ToNot(HaveOccurred())
+	return signer
+}
+
+var _ = Describe("ParseKeys", func() {
+	It("parses pgp, ssh and cosign keys", func() {
+		ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		der, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		data := armoredPublicKey(pgpEntity()) + "\n" +
+			"# ci signing key\n" +
+			string(ssh.MarshalAuthorizedKey(sshSigner().PublicKey())) +
+			string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+		keys, err := ParseKeys([]byte(data))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys.pgp).To(HaveLen(1))
+		Expect(keys.ssh).To(HaveLen(1))
+		Expect(keys.cosign).To(HaveLen(1))
+	})
+
+	It("fails without keys", func() {
+		_, err := ParseKeys([]byte("# no keys\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Git", func() {
+	It("verifies pgp signatures", func() {
+		entity := pgpEntity()
+		keys, err := ParseKeys([]byte(armoredPublicKey(entity)))
+		Expect(err).ToNot(HaveOccurred())
+
+		signature := pgpSignature(entity, payload)
+
+		Expect(keys.Git(signature, payload)).To(Succeed())
+		Expect(keys.Git(signature, append(payload, []byte("tampered")...))).ToNot(Succeed())
+		Expect(keys.Git(pgpSignature(pgpEntity(), payload), payload)).ToNot(Succeed())
+	})
+
+	It("verifies ssh signatures", func() {
+		signer := sshSigner()
+		keys, err := ParseKeys(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+		Expect(err).ToNot(HaveOccurred())
+
+		signature := sshSignature(signer, payload)
+
+		Expect(keys.Git(signature, payload)).To(Succeed())
+		Expect(keys.Git(signature, append(payload, []byte("tampered")...))).ToNot(Succeed())
+		Expect(keys.Git(sshSignature(sshSigner(), payload), payload)).ToNot(Succeed())
+	})
+
+	It("rejects unsigned objects", func() {
+		keys, err := ParseKeys(ssh.MarshalAuthorizedKey(sshSigner().PublicKey()))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(keys.Git("", payload)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("HelmProvenance", func() {
+	provenance := func(entity *openpgp.Entity, chart []byte) []byte {
+		sum := sha256.Sum256(chart)
+
+		var buf bytes.Buffer
+		w, err := clearsign.Encode(&buf, entity.PrivateKey, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = fmt.Fprintf(w, "apiVersion: v2\nname: demo\nversion: 1.0.0\n\n...\nfiles:\n  demo-1.0.0.tgz: sha256:%v\n", hex.EncodeToString(sum[:]))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		return buf.Bytes()
+	}
+
+	It("verifies the chart digest and signature", func() {
+		entity := pgpEntity()
+		keys, err := ParseKeys([]byte(armoredPublicKey(entity)))
+		Expect(err).ToNot(HaveOccurred())
+
+		chart := []byte("chart archive")
+
+		Expect(keys.HelmProvenance(provenance(entity, chart), chart, "demo-1.0.0.tgz")).To(Succeed())
+		Expect(keys.HelmProvenance(provenance(entity, chart), []byte("other chart archive"), "demo-1.0.0.tgz")).ToNot(Succeed())
+		Expect(keys.HelmProvenance(provenance(pgpEntity(), chart), chart, "demo-1.0.0.tgz")).ToNot(Succeed())
+		Expect(keys.HelmProvenance(provenance(entity, chart), chart, "other-1.0.0.tgz")).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("Cosign", func() {
+	It("verifies signatures of the manifest digest", func() {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		keys, err := ParseKeys(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		Expect(err).ToNot(HaveOccurred())
+
+		sign := func(payload []byte) string {
+			sum := sha256.Sum256(payload)
+			sig, err := ecdsa.SignASN1(rand.Reader, privateKey, sum[:])
+			Expect(err).ToNot(HaveOccurred())
+			return base64.StdEncoding.EncodeToString(sig)
+		}
+
+		cosignPayload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"ghcr.io/my-org/charts/demo"},"image":{"docker-manifest-digest":"%v"},"type":"cosign container image signature"},"optional":null}`, manifestDigest))
+
+		Expect(keys.Cosign(cosignPayload, sign(cosignPayload), manifestDigest)).To(Succeed())
+		Expect(keys.Cosign(cosignPayload, sign(cosignPayload), "sha256:4b825dc642cb6eb9a060e54bf8d69288fbee4904")).ToNot(Succeed())
+		Expect(keys.Cosign(cosignPayload, sign([]byte("other payload")), manifestDigest)).ToNot(Succeed())
+	})
+})
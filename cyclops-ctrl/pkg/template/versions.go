@@ -0,0 +1,136 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/pkg/errors"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/auth"
+)
+
+// TemplateVersion is a published version of a template. Revision is the
+// version Modules are pinned to once resolved; for git templates it is the
+// commit the tag or branch points to.
+type TemplateVersion struct {
+	Name     string
+	Revision string
+
+	Branch        bool
+	DefaultBranch bool
+}
+
+// GetTemplateVersions lists chart versions of Helm and OCI templates, and tags
+// and branches of git templates
+func (r Repo) GetTemplateVersions(repo, path string, source cyclopsv1alpha1.TemplateSourceType) ([]TemplateVersion, error) {
+	var err error
+	if len(source) == 0 {
+		source, err = r.AssumeTemplateSourceType(repo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	switch source {
+	case cyclopsv1alpha1.TemplateSourceTypeGit:
+		return listGitVersions(repo, creds)
+	case cyclopsv1alpha1.TemplateSourceTypeOCI:
+		versions, err = GetOCIChartTags(repo, path, creds)
+	case cyclopsv1alpha1.TemplateSourceTypeHelm:
+		versions, err = listHelmChartVersions(repo, path, creds)
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported template source: %v", source))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	templateVersions := make([]TemplateVersion, 0, len(versions))
+	for _, version := range versions {
+		templateVersions = append(templateVersions, TemplateVersion{Name: version, Revision: version})
+	}
+
+	return templateVersions, nil
+}
+
+func listHelmChartVersions(repo, chart string, creds *auth.Credentials) ([]string, error) {
+	index, err := loadIndex(repo, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := index.Entries[chart]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("chart %v not found in repo %v", chart, repo))
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		versions = append(versions, entry.Version)
+	}
+
+	return versions, nil
+}
+
+// listGitVersions lists tags and branches with the commits they point to.
+// Annotated tags are peeled to their commit.
+func listGitVersions(repo string, creds *auth.Credentials) ([]TemplateVersion, error) {
+	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repo},
+	})
+
+	refs, err := rem.List(&git.ListOptions{
+		PeelingOption: git.AppendPeeled,
+		Auth:          creds.GitAuth(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repo))
+	}
+
+	versions := make([]TemplateVersion, 0, len(refs))
+	tags := make(map[string]int)
+	head := ""
+
+	for _, ref := range refs {
+		switch {
+		case ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference:
+			head = ref.Target().Short()
+		case ref.Name().IsBranch():
+			versions = append(versions, TemplateVersion{
+				Name:     ref.Name().Short(),
+				Revision: ref.Hash().String(),
+				Branch:   true,
+			})
+		case ref.Name().IsTag():
+			name, peeled := strings.CutSuffix(ref.Name().Short(), "^{}")
+
+			if i, ok := tags[name]; ok {
+				if peeled {
+					versions[i].Revision = ref.Hash().String()
+				}
+				continue
+			}
+
+			tags[name] = len(versions)
+			versions = append(versions, TemplateVersion{Name: name, Revision: ref.Hash().String()})
+		}
+	}
+
+	for i := range versions {
+		versions[i].DefaultBranch = versions[i].Branch && versions[i].Name == head
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,36 @@
+package template
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	cyclopsv1alpha1 "github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// WarmUp loads the templates the Modules were last reconciled with into the
+// cache, so they are not fetched for all Modules at once after a restart
+func WarmUp(repo ITemplateRepo, modules []cyclopsv1alpha1.Module, logger logr.Logger) {
+	loaded := make(map[string]struct{})
+
+	for _, module := range modules {
+		ref := module.Spec.TemplateRef
+		resolvedVersion := module.Status.TemplateResolvedVersion
+
+		if len(resolvedVersion) == 0 {
+			continue
+		}
+
+		key := fmt.Sprintf("%v:%v/%v@%v", ref.SourceType, ref.URL, ref.Path, resolvedVersion)
+		if _, ok := loaded[key]; ok {
+			continue
+		}
+		loaded[key] = struct{}{}
+
+		if _, err := repo.GetTemplate(ref.URL, ref.Path, ref.Version, resolvedVersion, ref.SourceType); err != nil {
+			logger.Error(err, "failed to warm up template", "module", module.Name, "repo", ref.URL, "path", ref.Path, "version", resolvedVersion)
+		}
+	}
+
+	logger.Info("warmed up template cache", "templates", len(loaded))
+}
@@ -0,0 +1,125 @@
+package values
+
+import (
+	"strconv"
+)
+
+// Field is the type of a template field values are converted to. It decodes
+// from the root field of templates returned by the API.
+type Field struct {
+	Name                 string  `json:"name"`
+	Type                 string  `json:"type"`
+	Properties           []Field `json:"properties"`
+	Items                *Field  `json:"items"`
+	AdditionalProperties *Field  `json:"additionalProperties,omitempty"`
+}
+
+// Coerce converts values to the types of template fields, e.g. replicas=3 set
+// as a string to a number, or a version set as a number to a string. Values
+// that can't be converted are left as they are and fail schema validation.
+func Coerce(root Field, values map[string]interface{}) map[string]interface{} {
+	coerced, _ := coerce(root, values).(map[string]interface{})
+	if coerced == nil {
+		return values
+	}
+
+	return coerced
+}
+
+func coerce(field Field, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch field.Type {
+	case "string":
+		return toString(value)
+	case "number":
+		return toNumber(value)
+	case "boolean":
+		return toBool(value)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok || field.Items == nil {
+			return value
+		}
+
+		coerced := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			coerced = append(coerced, coerce(*field.Items, item))
+		}
+		return coerced
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	properties := make(map[string]Field, len(field.Properties))
+	for _, property := range field.Properties {
+		properties[property.Name] = property
+	}
+
+	coerced := make(map[string]interface{}, len(object))
+	for key, v := range object {
+		if property, ok := properties[key]; ok {
+			coerced[key] = coerce(property, v)
+			continue
+		}
+
+		if field.AdditionalProperties != nil {
+			coerced[key] = coerce(*field.AdditionalProperties, v)
+			continue
+		}
+
+		coerced[key] = v
+	}
+
+	return coerced
+}
+
+func toString(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return value
+}
+
+func toNumber(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+
+	return value
+}
+
+func toBool(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+
+	return value
+}
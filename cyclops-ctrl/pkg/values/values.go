@@ -0,0 +1,150 @@
+package values
+
+import (
+	"fmt"
+
+	"helm.sh/helm/v3/pkg/strvals"
+	"sigs.k8s.io/yaml"
+)
+
+// Options are Module values set the way Helm sets chart values. Values files
+// are merged in order, then values set with --set-json, --set, --set-string and
+// --set-file are applied in the same order Helm applies them.
+type Options struct {
+	// ValuesFiles are contents of values.yaml files, like -f
+	ValuesFiles [][]byte
+	// Values are key=value pairs with typed values, like --set
+	Values []string
+	// StringValues are key=value pairs with string values, like --set-string
+	StringValues []string
+	// JSONValues are key=value pairs with JSON values, like --set-json
+	JSONValues []string
+	// FileValues are key=path pairs set to the contents of the file, like
+	// --set-file
+	FileValues []string
+}
+
+// FileReader returns the contents of a file set with FileValues
+type FileReader func(path string) ([]byte, error)
+
+// Merge applies the options on top of base values and returns the result.
+// Values set to null are removed. Base values are not modified.
+func (o Options) Merge(base map[string]interface{}, readFile FileReader) (map[string]interface{}, error) {
+	values := copyValues(base)
+
+	for i, file := range o.ValuesFiles {
+		current := make(map[string]interface{})
+		if err := yaml.Unmarshal(file, &current); err != nil {
+			return nil, fmt.Errorf("failed to parse values file %v: %w", i+1, err)
+		}
+
+		values = MergeMaps(values, current)
+	}
+
+	for _, value := range o.JSONValues {
+		if err := strvals.ParseJSON(value, values); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-json data %v: %w", value, err)
+		}
+	}
+
+	for _, value := range o.Values {
+		if err := strvals.ParseInto(value, values); err != nil {
+			return nil, fmt.Errorf("failed parsing --set data %v: %w", value, err)
+		}
+	}
+
+	for _, value := range o.StringValues {
+		if err := strvals.ParseIntoString(value, values); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-string data %v: %w", value, err)
+		}
+	}
+
+	for _, value := range o.FileValues {
+		reader := func(path []rune) (interface{}, error) {
+			if readFile == nil {
+				return nil, fmt.Errorf("reading files is not supported")
+			}
+
+			data, err := readFile(string(path))
+			if err != nil {
+				return nil, err
+			}
+
+			return string(data), nil
+		}
+
+		if err := strvals.ParseIntoFile(value, values, reader); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-file data %v: %w", value, err)
+		}
+	}
+
+	removeNulls(values)
+	return values, nil
+}
+
+// removeNulls deletes values set to null, like Helm does when it coalesces
+// values with chart defaults
+func removeNulls(values map[string]interface{}) {
+	for k, v := range values {
+		switch v := v.(type) {
+		case nil:
+			delete(values, k)
+		case map[string]interface{}:
+			removeNulls(v)
+		}
+	}
+}
+
+// MergeMaps merges b into a, like Helm merges values files. Nested maps are
+// merged and other values of b replace values of a.
+func MergeMaps(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+
+	for k, v := range b {
+		if v, ok := v.(map[string]interface{}); ok {
+			if bv, ok := out[k]; ok {
+				if bv, ok := bv.(map[string]interface{}); ok {
+					out[k] = MergeMaps(bv, v)
+					continue
+				}
+			}
+		}
+
+		out[k] = v
+	}
+
+	return out
+}
+
+// ToYAML returns the values as a values.yaml file
+func ToYAML(values map[string]interface{}) ([]byte, error) {
+	return yaml.Marshal(values)
+}
+
+// copyValues deep copies values, keeping the types of numbers
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		copied[k] = copyValue(v)
+	}
+
+	return copied
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return copyValues(v)
+	case []interface{}:
+		copied := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			copied = append(copied, copyValue(item))
+		}
+		return copied
+	}
+
+	return value
+}
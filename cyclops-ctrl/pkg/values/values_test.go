@@ -0,0 +1,173 @@
+package values
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestValues(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test values")
+}
+
+var _ = Describe("Values test", func() {
+	Describe("Merge", func() {
+		It("merges values files in order", func() {
+			values, err := Options{
+				ValuesFiles: [][]byte{
+					[]byte("image:\n  repository: nginx\n  tag: \"1.25\"\nreplicas: 1\n"),
+					[]byte("image:\n  tag: \"1.27\"\nservice:\n  enabled: true\n"),
+				},
+			}.Merge(map[string]interface{}{"name": "demo"}, nil)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values).To(Equal(map[string]interface{}{
+				"name": "demo",
+				"image": map[string]interface{}{
+					"repository": "nginx",
+					"tag":        "1.27",
+				},
+				"replicas": float64(1),
+				"service": map[string]interface{}{
+					"enabled": true,
+				},
+			}))
+		})
+
+		It("does not modify base values", func() {
+			base := map[string]interface{}{
+				"image": map[string]interface{}{"tag": "1.25"},
+			}
+
+			_, err := Options{Values: []string{"image.tag=1.27"}}.Merge(base, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(base["image"]).To(Equal(map[string]interface{}{"tag": "1.25"}))
+		})
+
+		It("sets values with helm syntax", func() {
+			values, err := Options{
+				JSONValues:   []string{`resources={"limits":{"cpu":"500m"}}`},
+				Values:       []string{"replicas=3,enabled=true", "ports[1].port=8080", `annotations.cyclops\.dev/team=core`},
+				StringValues: []string{"version=1.10"},
+				FileValues:   []string{"config=app.conf"},
+			}.Merge(nil, func(path string) ([]byte, error) {
+				return []byte(fmt.Sprintf("contents of %v", path)), nil
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values).To(Equal(map[string]interface{}{
+				"resources": map[string]interface{}{
+					"limits": map[string]interface{}{"cpu": "500m"},
+				},
+				"replicas": int64(3),
+				"enabled":  true,
+				"ports": []interface{}{
+					nil,
+					map[string]interface{}{"port": int64(8080)},
+				},
+				"annotations": map[string]interface{}{"cyclops.dev/team": "core"},
+				"version":     "1.10",
+				"config":      "contents of app.conf",
+			}))
+		})
+
+		It("removes values set to null", func() {
+			values, err := Options{
+				Values: []string{"image=null"},
+			}.Merge(map[string]interface{}{"image": map[string]interface{}{"tag": "1.25"}, "replicas": 1}, nil)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values).To(Equal(map[string]interface{}{"replicas": 1}))
+		})
+
+		It("keeps the types of base values", func() {
+			base := map[string]interface{}{
+				"replicas":  int64(3),
+				"ports":     []interface{}{80, map[string]interface{}{"port": int64(443)}},
+				"resources": map[string]interface{}{"cpu": 0.5},
+			}
+
+			values, err := Options{StringValues: []string{"version=1.27"}}.Merge(base, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values).To(Equal(map[string]interface{}{
+				"replicas":  int64(3),
+				"ports":     []interface{}{80, map[string]interface{}{"port": int64(443)}},
+				"resources": map[string]interface{}{"cpu": 0.5},
+				"version":   "1.27",
+			}))
+		})
+
+		It("returns an error for invalid values", func() {
+			_, err := Options{Values: []string{"replicas"}}.Merge(nil, nil)
+			Expect(err).To(HaveOccurred())
+
+			_, err = Options{ValuesFiles: [][]byte{[]byte("- not\n- an object\n")}}.Merge(nil, nil)
+			Expect(err).To(HaveOccurred())
+
+			_, err = Options{FileValues: []string{"config=app.conf"}}.Merge(nil, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ToYAML", func() {
+		It("returns a values file", func() {
+			data, err := ToYAML(map[string]interface{}{
+				"image":    map[string]interface{}{"tag": "1.27"},
+				"replicas": 3,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("image:\n  tag: \"1.27\"\nreplicas: 3\n"))
+		})
+	})
+
+	Describe("Coerce", func() {
+		root := Field{
+			Type: "object",
+			Properties: []Field{
+				{Name: "version", Type: "string"},
+				{Name: "replicas", Type: "number"},
+				{Name: "enabled", Type: "boolean"},
+				{
+					Name:  "ports",
+					Type:  "array",
+					Items: &Field{Type: "number"},
+				},
+				{
+					Name:                 "labels",
+					Type:                 "map",
+					AdditionalProperties: &Field{Type: "string"},
+				},
+			},
+		}
+
+		It("converts values to the types of template fields", func() {
+			values, err := Options{
+				Values:       []string{"version=1", "enabled=false", "ports={80,443}", "labels.tier=2", "other=3"},
+				StringValues: []string{"replicas=3"},
+			}.Merge(nil, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(Coerce(root, values)).To(Equal(map[string]interface{}{
+				"version":  "1",
+				"replicas": int64(3),
+				"enabled":  false,
+				"ports":    []interface{}{int64(80), int64(443)},
+				"labels":   map[string]interface{}{"tier": "2"},
+				"other":    int64(3),
+			}))
+		})
+
+		It("keeps values that can not be converted", func() {
+			values := map[string]interface{}{
+				"replicas": "three",
+				"enabled":  "maybe",
+				"version":  map[string]interface{}{"major": 1},
+			}
+
+			Expect(Coerce(root, values)).To(Equal(values))
+		})
+	})
+})
@@ -335,7 +335,22 @@ export const EditModuleComponent = ({
         };
       }
       updateModule(moduleName, templateRef, values, gitOpsWrite)
-        .then(() => {
+        .then((res) => {
+          const pullRequestURL = res?.data?.pullRequestURL;
+          if (pullRequestURL) {
+            notificationApi.success({
+              message: "Pull request opened",
+              description: (
+                <a href={pullRequestURL} target="_blank" rel="noreferrer">
+                  {pullRequestURL}
+                </a>
+              ),
+              placement: "topRight",
+              duration: 0,
+            });
+            return;
+          }
+
           onUpdateModuleSuccess(moduleName);
         })
         .catch((error) => {
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/cyclops-ui/cycops-cyctl/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffExample = `# shows the resources that would be added, changed or removed by updating a module
+cyctl diff module <module-name> --value="<key>=<value>" --version="<template-version>"
+
+# to see changes for a module named test with 3 replicas
+cyctl diff module test --value="scaling.replicas=3"`
+)
+
+var (
+	diffCMD = &cobra.Command{
+		Use:     "diff",
+		Short:   "shows changes to cluster resources before they are applied (currently supports only Modules)",
+		Long:    "shows changes to cluster resources before they are applied (currently supports only Modules)",
+		Example: diffExample,
+		Args:    cobra.NoArgs,
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(diffCMD)
+	diffCMD.AddCommand(diff.DiffModuleCMD)
+}
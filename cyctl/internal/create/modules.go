@@ -4,14 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1/client"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/values"
 	"github.com/cyclops-ui/cycops-cyctl/internal/kubeconfig"
+	"github.com/cyclops-ui/cycops-cyctl/internal/modulevalues"
 	"github.com/spf13/cobra"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 )
 
@@ -22,24 +24,18 @@ var (
 	--repo 'github.com/github/demo' \
 	--path '/path/to/charts' \
 	--version 'main'
+
+	# Create module with values from multiple files, merged in order, and values set on the command line
+	cyctl create module NAME -f values.yaml -f values-prod.yaml \
+	--set scaling.replicas=3 \
+	--template 'demo'
 	`
-	valuesFile   string
 	templateName string
 	outputFormat string
 )
 
 // createModule allows you to create module Custom Resource.
-func createModule(clientset *client.CyclopsV1Alpha1Client, moduleName, repo, path, version, namespace, valuesFile, templateName, outputFormat string) {
-
-	values, err := os.ReadFile(valuesFile)
-	if err != nil {
-		log.Fatalf("Error reading values file: %v", err)
-	}
-	jsonValues, err := yaml.YAMLToJSON(values)
-	if err != nil {
-		log.Fatalf("Error converting values file to JSON: %v", err)
-	}
-
+func createModule(clientset *client.CyclopsV1Alpha1Client, kubernetesClientset *kubernetes.Clientset, moduleName, repo, path, version, namespace string, options values.Options, templateName, outputFormat string) {
 	if templateName != "" && (repo == "" && path == "" && version == "") {
 		temp, err := clientset.TemplateStore("cyclops").Get(templateName)
 		if err != nil {
@@ -51,6 +47,27 @@ func createModule(clientset *client.CyclopsV1Alpha1Client, moduleName, repo, pat
 		version = temp.Spec.Version
 	}
 
+	moduleValues, err := options.Merge(nil, modulevalues.ReadFile)
+	if err != nil {
+		log.Fatalf("Error setting module values: %v", err)
+	}
+
+	templateRef := v1alpha1.TemplateRef{
+		URL:     repo,
+		Path:    path,
+		Version: version,
+	}
+
+	moduleValues, err = modulevalues.Coerce(kubernetesClientset, templateRef, moduleValues)
+	if err != nil {
+		log.Fatalf("Error converting values to template types: %v", err)
+	}
+
+	jsonValues, err := json.Marshal(moduleValues)
+	if err != nil {
+		log.Fatalf("Error converting values to JSON: %v", err)
+	}
+
 	// Define a new Module object
 	newModule := v1alpha1.Module{
 		TypeMeta: v1.TypeMeta{
@@ -62,12 +79,8 @@ func createModule(clientset *client.CyclopsV1Alpha1Client, moduleName, repo, pat
 			Namespace: namespace,
 		},
 		Spec: v1alpha1.ModuleSpec{
-			TemplateRef: v1alpha1.TemplateRef{
-				URL:     repo,
-				Path:    path,
-				Version: version,
-			},
-			Values: apiextensionsv1.JSON{Raw: jsonValues},
+			TemplateRef: templateRef,
+			Values:      apiextensionsv1.JSON{Raw: jsonValues},
 		},
 	}
 
@@ -116,7 +129,12 @@ var (
 			if (templateName != "" && (repo != "" || path != "" || version != "")) || (templateName == "" && (repo == "" || path == "" || version == "")) {
 				log.Fatalf("Error: Either template or (repo, path and version) must be provided.")
 			}
-			createModule(kubeconfig.Moduleset, args[0], repo, path, version, namespace, valuesFile, templateName, outputFormat)
+			options, err := modulevalues.Options(cmd)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+
+			createModule(kubeconfig.Moduleset, kubeconfig.Clientset, args[0], repo, path, version, namespace, options, templateName, outputFormat)
 		},
 	}
 )
@@ -126,8 +144,7 @@ func init() {
 	CreateModule.Flags().StringVarP(&repo, "repo", "r", "", "Repository URL for the module")
 	CreateModule.Flags().StringVarP(&path, "path", "p", "", "Path to the module charts")
 	CreateModule.Flags().StringVarP(&version, "version", "v", "", "Version of the module")
-	CreateModule.Flags().StringVarP(&valuesFile, "file", "f", "", "Path to the values.yaml file")
 	CreateModule.Flags().StringVarP(&templateName, "template", "t", "", "Name of the template to use for the module creation")
 	CreateModule.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml or json)")
-	CreateModule.MarkFlagRequired("file")
+	modulevalues.AddFlags(CreateModule)
 }
@@ -0,0 +1,205 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1/client"
+	"github.com/cyclops-ui/cycops-cyctl/internal/kubeconfig"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	diffModuleExample = `# shows how the resources of a module would change if it were updated; takes module name
+# as an argument with optional flags --value and --version
+cyctl diff module test --value="scaling.replicas=3" --value="general.version=1.27.1"
+
+# to see the impact of moving a module to a different template version
+cyctl diff module test --version=v0.2.0
+	`
+)
+
+type diffTemplate struct {
+	URL     string `json:"repo"`
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+type diffRequest struct {
+	Name     string                 `json:"name"`
+	Template diffTemplate           `json:"template"`
+	Values   map[string]interface{} `json:"values"`
+}
+
+type jsonPatch struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type resourceDiff struct {
+	Group     string      `json:"group"`
+	Version   string      `json:"version"`
+	Kind      string      `json:"kind"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Action    string      `json:"action"`
+	Patch     []jsonPatch `json:"patch"`
+	Error     string      `json:"error"`
+}
+
+type moduleDiff struct {
+	Resources []resourceDiff `json:"resources"`
+}
+
+// diffModule sends the proposed module values to the cyclops controller through the
+// Kubernetes API service proxy and prints the returned per resource diff
+func diffModule(
+	clientset *client.CyclopsV1Alpha1Client,
+	kubernetesClientset *kubernetes.Clientset,
+	moduleName string,
+	values []string,
+	version string,
+) {
+	module, err := clientset.Modules("cyclops").Get(moduleName)
+	if err != nil {
+		fmt.Println("Failed to fetch module ", err)
+		return
+	}
+
+	specValuesMap := make(map[string]interface{})
+	err = json.Unmarshal(module.Spec.Values.Raw, &specValuesMap)
+	if err != nil {
+		fmt.Println("failed to decode json data:", err)
+		return
+	}
+
+	for _, v := range values {
+		keyValue := strings.Split(v, "=")
+		if len(keyValue) != 2 {
+			fmt.Println("invalid key value pair: ", v)
+			return
+		}
+		key := keyValue[0]
+		value := keyValue[1]
+
+		err = unstructured.SetNestedField(specValuesMap, value, strings.Split(key, ".")...)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	templateVersion := module.Spec.TemplateRef.Version
+	if len(version) != 0 {
+		templateVersion = version
+	}
+
+	body, err := json.Marshal(diffRequest{
+		Name: moduleName,
+		Template: diffTemplate{
+			URL:     module.Spec.TemplateRef.URL,
+			Path:    module.Spec.TemplateRef.Path,
+			Version: templateVersion,
+		},
+		Values: specValuesMap,
+	})
+	if err != nil {
+		fmt.Println("failed to encode to json: ", err)
+		return
+	}
+
+	data, err := kubernetesClientset.CoreV1().RESTClient().Post().
+		Namespace("cyclops").
+		Resource("services").
+		Name("cyclops-ctrl:8080").
+		SubResource("proxy").
+		Suffix("modules", moduleName, "diff").
+		Body(body).
+		DoRaw(context.TODO())
+	if err != nil {
+		fmt.Println("failed to diff module: ", err)
+		return
+	}
+
+	var diff moduleDiff
+	if err := json.Unmarshal(data, &diff); err != nil {
+		fmt.Println("failed to decode diff: ", err)
+		return
+	}
+
+	if len(diff.Resources) == 0 {
+		fmt.Printf("no changes for module %v\n", moduleName)
+		return
+	}
+
+	for _, r := range diff.Resources {
+		printResourceDiff(r)
+	}
+}
+
+func printResourceDiff(r resourceDiff) {
+	apiVersion := r.Version
+	if len(r.Group) != 0 {
+		apiVersion = fmt.Sprintf("%v/%v", r.Group, r.Version)
+	}
+
+	name := r.Name
+	if len(r.Namespace) != 0 {
+		name = fmt.Sprintf("%v/%v", r.Namespace, r.Name)
+	}
+
+	switch {
+	case len(r.Error) != 0:
+		fmt.Printf("! %v %v %v: %v\n", apiVersion, r.Kind, name, r.Error)
+	case r.Action == "added":
+		fmt.Printf("+ %v %v %v\n", apiVersion, r.Kind, name)
+	case r.Action == "removed":
+		fmt.Printf("- %v %v %v\n", apiVersion, r.Kind, name)
+	default:
+		fmt.Printf("~ %v %v %v\n", apiVersion, r.Kind, name)
+		for _, p := range r.Patch {
+			if p.Op == "remove" {
+				fmt.Printf("    %v %v\n", p.Op, p.Path)
+				continue
+			}
+
+			value, _ := json.Marshal(p.Value)
+			fmt.Printf("    %v %v: %s\n", p.Op, p.Path, value)
+		}
+	}
+}
+
+var (
+	DiffModuleCMD = &cobra.Command{
+		Use:     "module",
+		Short:   "shows changes to module resources before applying new values; takes module name as an argument",
+		Long:    "shows changes to module resources before applying new values; takes module name as an argument",
+		Example: diffModuleExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			values, err := cmd.Flags().GetStringArray("value")
+			if err != nil {
+				fmt.Println("failed to get value of flag --value: ", err)
+				return
+			}
+
+			version, err := cmd.Flags().GetString("version")
+			if err != nil {
+				fmt.Println("failed to get value of flag --version: ", err)
+				return
+			}
+
+			diffModule(kubeconfig.Moduleset, kubeconfig.Clientset, args[0], values, version)
+		},
+	}
+)
+
+func init() {
+	DiffModuleCMD.Flags().StringArrayP("value", "v", []string{}, "key value pair to change in module values")
+	DiffModuleCMD.Flags().String("version", "", "template version to diff against")
+}
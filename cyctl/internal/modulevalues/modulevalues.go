@@ -0,0 +1,99 @@
+package modulevalues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/values"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AddFlags adds the Helm style values flags to the command
+func AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP("file", "f", []string{}, "Path to a values.yaml file; can be set multiple times and files are merged in order")
+	cmd.Flags().StringArray("set", []string{}, "Set values on the command line (e.g. --set scaling.replicas=3,general.version=1.27.1)")
+	cmd.Flags().StringArray("set-string", []string{}, "Set STRING values on the command line")
+	cmd.Flags().StringArray("set-json", []string{}, "Set JSON values on the command line (e.g. --set-json 'resources={\"limits\":{\"cpu\":\"500m\"}}')")
+	cmd.Flags().StringArray("set-file", []string{}, "Set values from the contents of files (e.g. --set-file config=app.conf)")
+}
+
+// Options reads the values flags of the command. Values files are read from
+// disk, while files set with --set-file are read when values are merged.
+func Options(cmd *cobra.Command, setFlags ...string) (values.Options, error) {
+	files, err := cmd.Flags().GetStringArray("file")
+	if err != nil {
+		return values.Options{}, err
+	}
+
+	valuesFiles := make([][]byte, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return values.Options{}, fmt.Errorf("error reading values file: %w", err)
+		}
+
+		valuesFiles = append(valuesFiles, data)
+	}
+
+	options := values.Options{ValuesFiles: valuesFiles}
+
+	for _, flag := range append([]string{"set"}, setFlags...) {
+		set, err := cmd.Flags().GetStringArray(flag)
+		if err != nil {
+			return values.Options{}, err
+		}
+
+		options.Values = append(options.Values, set...)
+	}
+
+	if options.StringValues, err = cmd.Flags().GetStringArray("set-string"); err != nil {
+		return values.Options{}, err
+	}
+
+	if options.JSONValues, err = cmd.Flags().GetStringArray("set-json"); err != nil {
+		return values.Options{}, err
+	}
+
+	if options.FileValues, err = cmd.Flags().GetStringArray("set-file"); err != nil {
+		return values.Options{}, err
+	}
+
+	return options, nil
+}
+
+// ReadFile reads files set with --set-file
+func ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Coerce converts values to the types of the template fields. The template is
+// fetched from the Cyclops API, the same way the UI fetches it.
+func Coerce(clientset *kubernetes.Clientset, templateRef v1alpha1.TemplateRef, moduleValues map[string]interface{}) (map[string]interface{}, error) {
+	data, err := clientset.CoreV1().RESTClient().Get().
+		Namespace("cyclops").
+		Resource("services").
+		Name("cyclops-ctrl:8080").
+		SubResource("proxy").
+		Suffix("templates").
+		Param("repo", templateRef.URL).
+		Param("path", templateRef.Path).
+		Param("commit", templateRef.Version).
+		Param("sourceType", string(templateRef.SourceType)).
+		DoRaw(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch module template: %w", err)
+	}
+
+	var template struct {
+		Root values.Field `json:"root"`
+	}
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to decode module template: %w", err)
+	}
+
+	return values.Coerce(template.Root, moduleValues), nil
+}
@@ -3,25 +3,32 @@ package update
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/cyclops-ui/cyclops/cyclops-ctrl/api/v1alpha1/client"
+	"github.com/cyclops-ui/cyclops/cyclops-ctrl/pkg/values"
 	"github.com/cyclops-ui/cycops-cyctl/internal/kubeconfig"
+	"github.com/cyclops-ui/cycops-cyctl/internal/modulevalues"
 	"github.com/spf13/cobra"
 	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
 )
 
 var (
-	updateModuleExample = `# updates module values; takes module name as an argument with flag --value
+	updateModuleExample = `# updates module values; takes module name as an argument with Helm style values flags
 # to update replicas and version for a module named test
-cyctl update module test --value="scaling.replicas=3" --value="general.version=1.27.1"
+cyctl update module test --set scaling.replicas=3 --set general.version=1.27.1
+
+# to merge values files in order on top of the current values
+cyctl update module test -f values.yaml -f values-prod.yaml
+
+# to replace the module values with values files and --set values
+cyctl update module test -f values.yaml --set-string general.version=1.27 --reset-values
 	`
 )
 
 // updates the given module from cyclops API
-func updateModule(clientset *client.CyclopsV1Alpha1Client, moduleName string, values []string) {
+func updateModule(clientset *client.CyclopsV1Alpha1Client, kubernetesClientset *kubernetes.Clientset, moduleName string, options values.Options, resetValues bool) {
 	module, err := clientset.Modules("cyclops").Get(moduleName)
 	if err != nil {
 		fmt.Println("Failed to fetch module ", err)
@@ -29,26 +36,24 @@ func updateModule(clientset *client.CyclopsV1Alpha1Client, moduleName string, va
 	}
 
 	specValuesMap := make(map[string]interface{})
-	err = json.Unmarshal(module.Spec.Values.Raw, &specValuesMap)
+	if !resetValues && len(module.Spec.Values.Raw) != 0 {
+		err = json.Unmarshal(module.Spec.Values.Raw, &specValuesMap)
+		if err != nil {
+			fmt.Println("failed to decode json data:", err)
+			return
+		}
+	}
+
+	specValuesMap, err = options.Merge(specValuesMap, modulevalues.ReadFile)
 	if err != nil {
-		fmt.Println("failed to decode json data:", err)
+		fmt.Println(err)
 		return
 	}
 
-	for _, v := range values {
-		keyValue := strings.Split(v, "=")
-		if len(keyValue) != 2 {
-			fmt.Println("invalid key value pair: ", v)
-			return
-		}
-		key := keyValue[0]
-		value := keyValue[1]
-
-		err = unstructured.SetNestedField(specValuesMap, value, strings.Split(key, ".")...)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
+	specValuesMap, err = modulevalues.Coerce(kubernetesClientset, module.Spec.TemplateRef, specValuesMap)
+	if err != nil {
+		fmt.Println("failed to convert values to template types: ", err)
+		return
 	}
 
 	updatedSpecValues, err := json.Marshal(specValuesMap)
@@ -75,23 +80,31 @@ func updateModule(clientset *client.CyclopsV1Alpha1Client, moduleName string, va
 var (
 	UpdateModuleCMD = &cobra.Command{
 		Use:     "module",
-		Short:   "updates module values; takes module name as an argument with flag --value",
-		Long:    "updates module values; takes module name as an argument with flag --value",
+		Short:   "updates module values; takes module name as an argument with Helm style values flags",
+		Long:    "updates module values; takes module name as an argument with Helm style values flags. Values files set with -f are merged in order on top of the current module values, then --set-json, --set, --set-string and --set-file values are applied like Helm applies them. Values are converted to the types of the template fields before the module is updated.",
 		Example: updateModuleExample,
 		Args:    cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			values, err := cmd.Flags().GetStringArray("value")
+			options, err := modulevalues.Options(cmd, "value")
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			resetValues, err := cmd.Flags().GetBool("reset-values")
 			if err != nil {
-				fmt.Println("failed to get value of flag --value: ", err)
+				fmt.Println("failed to get value of flag --reset-values: ", err)
 				return
 			}
 
-			updateModule(kubeconfig.Moduleset, args[0], values)
+			updateModule(kubeconfig.Moduleset, kubeconfig.Clientset, args[0], options, resetValues)
 		},
 	}
 )
 
 func init() {
-	UpdateModuleCMD.Flags().StringArrayP("value", "v", []string{}, "key value pair to update module")
-	UpdateModuleCMD.MarkFlagRequired("value")
+	modulevalues.AddFlags(UpdateModuleCMD)
+	UpdateModuleCMD.Flags().StringArrayP("value", "v", []string{}, "key value pair to update module; same as --set")
+	UpdateModuleCMD.Flags().Bool("reset-values", false, "replace the module values instead of updating the current values")
+	UpdateModuleCMD.MarkFlagsOneRequired("value", "set", "set-string", "set-json", "set-file", "file")
 }
@@ -69,6 +69,7 @@ const sidebars = {
         },
         "installation/git-write",
         "installation/namespace-scope",
+        "installation/admission-webhooks",
       ],
     },
     {
@@ -80,6 +81,8 @@ const sidebars = {
         "templates/validations",
         "templates/dependencies",
         "templates/private_templates",
+        "templates/webhooks",
+        "templates/updates",
       ],
     },
     {